@@ -1,41 +1,212 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"text/template"
+	"time"
 
+	"github.com/palthisailohith/k8s-danger-scan/pkg/annotate"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/baseline"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/cluster"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/config"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/exceptions"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/fix"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/gitutil"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/logger"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/operator"
 	"github.com/palthisailohith/k8s-danger-scan/pkg/output"
 	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
 	"github.com/palthisailohith/k8s-danger-scan/pkg/scanner"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/selftest"
 	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/watch"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/webhook"
 )
 
 const version = "1.0.0"
 
+// configFetchTimeout bounds how long --config-url may take to download
+const configFetchTimeout = 10 * time.Second
+
+// watchInterval is how often watch mode polls for manifest changes by
+// default
+const watchInterval = 500 * time.Millisecond
+
+// serveReadTimeout bounds how long a webhook client may take to send
+// request headers
+const serveReadTimeout = 10 * time.Second
+
+// serveShutdownTimeout is how long in-flight admission reviews get to
+// finish once serve is asked to stop
+const serveShutdownTimeout = 5 * time.Second
+
+// operatorInterval is how often operator mode rescans the cluster by default
+const operatorInterval = 30 * time.Minute
+
+// progressInterval throttles redraws of the parsing progress line
+const progressInterval = 100 * time.Millisecond
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, `k8s-danger-scan - Detect catastrophic Kubernetes misconfigurations
 
 Usage:
   k8s-danger-scan scan <path> [flags]        Scan manifest files or directory
   k8s-danger-scan diff <old> <new> [flags]   Compare manifests and show new risks only
+  k8s-danger-scan cluster [flags]            Scan live resources via kubectl and the
+                                             current kubeconfig context
+  k8s-danger-scan diff --since <ref> [flags] [path...]
+                                             Show new risks in files changed since a git ref,
+                                             optionally only beneath the given paths
+  k8s-danger-scan git-diff <ref1> <ref2> [flags] [path...]
+                                             Show new risks between two git revisions,
+                                             without checking either out
+  k8s-danger-scan explain <rule-id>          Show detailed remediation for a rule
+  k8s-danger-scan rules list [--json]        List every built-in rule with its severity,
+                                             category and whether it runs by default
+  k8s-danger-scan selftest                   Check every rule against built-in fixtures
+  k8s-danger-scan annotate --finding <key> <path>
+                                             Add danger-scan/ignore annotations for
+                                             accepted findings (--all for every one)
+  k8s-danger-scan fix [--dry-run] <path>     Apply safe remediations to manifests
+                                             (--dry-run prints a unified diff)
+  k8s-danger-scan serve [flags]              Run a validating admission webhook
+  k8s-danger-scan operator [flags]           Rescan the cluster periodically and write a
+                                             DangerScanReport per namespace
+  k8s-danger-scan operator crd               Print the DangerScanReport CRD
+  k8s-danger-scan baseline create <path> [flags]
+                                             Record current findings in baseline.json
   k8s-danger-scan --version                  Show version
 
 Flags:
+  --format <f>[=file] Output format: human, table, json, csv, sarif, markdown,
+                      html or template. Repeat or comma-separate to write several
+                      at once, e.g. --format human --format json=results.json
+  --no-color          Don't color human and table output (default: color when
+                      stdout is a terminal and NO_COLOR is unset)
   --json              Output in JSON format
-  --include-medium    Include MEDIUM severity findings (default: HIGH only)
+  --csv               Output one CSV row per finding, with a header row
+  --template <file>   Render output with a Go text/template
+  --min-severity <s>  Lowest severity to report: low, medium, high, critical
+                      (default: high)
+  --include-medium    Deprecated alias for --min-severity medium
+  --fail-on <s>       Lowest severity that fails the run, reported or not: low,
+                      medium, high, critical (default: reported findings from
+                      medium up)
+  --categories <list> Rule categories to run: security, reliability, hardening,
+                      governance, secrets, observability, supply-chain
+                      (default: all but observability and supply-chain)
+  --disable-rule <id> Skip a rule for this run (repeatable; see 'rules list')
+  --only-rule <id>    Run only the given rule (repeatable); other rules report
+                      nothing
+  --show-snippet      Show the offending YAML (container, volume, RBAC rule...)
+                      under each finding
+  --show-suppressed   Report findings suppressed by danger-scan/ignore annotations,
+                      marked as suppressed (they never affect the exit code)
+  --strict            Flag containers with no securityContext or that leave
+                      allowPrivilegeEscalation unset (MEDIUM)
+  --pss-level <level> Report failed Pod Security Standards controls (HIGH),
+                      and which profile each workload violates:
+                      baseline or restricted (--pss for short)
+  --compliance cis    Group human, table and markdown output by CIS Kubernetes
+                      Benchmark control instead of by resource
+  --verbose, -v       Print informational messages and a scan statistics footer
+  -vv                 Also print debug messages, such as why a file was skipped
+  --quiet             Suppress warnings on stderr
+  --config-url <url>  Fetch a centrally managed rules.yaml (cached locally)
+  --allow-config-fetch-failure
+                      Fall back to defaults if --config-url cannot be loaded
+  --strict-parse      Fail if any file cannot be parsed (default: warn and continue)
+  --max-file-size <n> Skip manifest files larger than n MiB (default: 32)
+  --max-depth <n>     Skip YAML documents nested deeper than n levels (default: 100)
+  --exclude <glob>    Skip paths matching a glob; ** matches any directories (repeatable)
+  --concurrency <n>   Parse and scan n files/resources at once (default: one per CPU)
+  --raw               Don't render kustomization directories with kustomize build
+  --helm              Render Helm chart directories with helm template
+  --values <file>     Values file for helm template (repeatable; implies --helm)
+  --pre-commit        Skip file arguments that aren't .yaml/.yml/.json and exit 0
+                      when no manifests remain (for git hooks)
+  --exit-zero         Exit 0 regardless of findings or parse failures (report mode)
+  --print-exit-code   Print the exit code and what it means as the last line
+                      on stderr, e.g. "Exit code: 2 (at least one high or
+                      critical finding)"
+  --config <file>     Load a .danger-scan.yaml (default: the one in the scan
+                      root, if any) to disable rules, override severities
+                      and set default flags
+  --rules-file <file> Override rule severity and Reason/Impact/Fix text
+  --rules-dir <dir>   Run custom rules defined in the YAML files in a directory
+  --baseline <file>   Only report findings not recorded in a baseline file
+  --show-resolved     With diff and git-diff, also report findings the new side
+                      fixes, marked as resolved (they never affect the exit code)
+  --exceptions <file> Accept findings listed in an exceptions.yaml until each
+                      entry expires; applied ones are listed in JSON output
+  --watch             Rescan on manifest changes until interrupted (scan only)
+  --watch-interval <duration>
+                      How often --watch polls for changes (default: 500ms)
+
+Cluster Flags:
+  --context <name>    kubeconfig context to use (default: current context)
+  -n, --namespace <ns>
+                      Namespace to scan (default: the context's namespace)
+  -A, --all-namespaces
+                      Scan every namespace
+  -l, --selector <s>  Only scan resources matching a label selector
+
+Baseline Flags:
+  --output <file>     Where baseline create writes the baseline (default:
+                      baseline.json)
+
+Serve Flags:
+  --addr <addr>       Address to listen on (default: :8443)
+  --tls-cert-file <f> PEM certificate for HTTPS (with --tls-key-file)
+  --tls-key-file <f>  PEM private key for --tls-cert-file
+  --mode <m>          deny: reject objects with HIGH or CRITICAL findings;
+                      warn: admit them with warnings; audit: admit and only
+                      log findings (default: deny)
+
+Environment:
+  Every flag can also be set with a DANGER_SCAN_* variable named after it,
+  e.g. DANGER_SCAN_MIN_SEVERITY=medium or DANGER_SCAN_CONFIG=ci.yaml.
+  Flags given on the command line take precedence, then the environment,
+  then options in .danger-scan.yaml.
 
 Exit Codes:
-  0  No findings
+  0  No findings, or low risk only
   1  Medium risk only
-  2  At least one high risk
+  2  At least one high or critical risk
   3  Error occurred
 
 Examples:
   k8s-danger-scan scan ./manifests
-  k8s-danger-scan scan deployment.yaml --include-medium
+  k8s-danger-scan scan --min-severity medium deployment.yaml
   k8s-danger-scan diff old.yaml new.yaml
-  k8s-danger-scan scan . --json --include-medium
+  k8s-danger-scan cluster --all-namespaces --min-severity medium
+  k8s-danger-scan serve --tls-cert-file tls.crt --tls-key-file tls.key --mode warn
+  k8s-danger-scan explain privileged-container
+  k8s-danger-scan annotate --finding host-network:Deployment/kube-system/agent ./manifests
+  k8s-danger-scan fix --dry-run ./manifests
+  k8s-danger-scan scan --json --min-severity medium .
+  k8s-danger-scan scan --template report.tmpl ./manifests
+  k8s-danger-scan baseline create --min-severity low ./manifests
+  k8s-danger-scan scan --baseline baseline.json ./manifests
+  k8s-danger-scan scan --exceptions exceptions.yaml ./manifests
+  k8s-danger-scan scan --helm --values values-prod.yaml ./mychart
+  k8s-danger-scan scan --format human,json=results.json,csv=results.csv .
+  k8s-danger-scan scan --format human --format sarif=results.sarif ./manifests
 `)
 }
 
@@ -53,40 +224,144 @@ func main() {
 
 	command := os.Args[1]
 
+	if command == "explain" {
+		os.Exit(int(runExplain(os.Args[2:])))
+	}
+
+	if command == "rules" {
+		os.Exit(int(runRules(os.Args[2:])))
+	}
+
+	if command == "selftest" {
+		os.Exit(int(runSelftest()))
+	}
+
+	if command == "annotate" {
+		os.Exit(int(runAnnotate(os.Args[2:])))
+	}
+
+	if command == "fix" {
+		os.Exit(int(runFix(os.Args[2:])))
+	}
+
+	if command == "serve" {
+		os.Exit(int(runServe(os.Args[2:])))
+	}
+
+	if command == "operator" {
+		os.Exit(int(runOperator(os.Args[2:])))
+	}
+
 	// Parse command-specific flags
-	var jsonOutput, includeMedium bool
+	var opts cliOptions
 	var paths []string
+	var fs *flag.FlagSet
 
 	switch command {
 	case "scan":
 		scanFlags := flag.NewFlagSet("scan", flag.ExitOnError)
-		jsonPtr := scanFlags.Bool("json", false, "Output in JSON format")
-		mediumPtr := scanFlags.Bool("include-medium", false, "Include medium severity findings")
+		opts.registerFlags(scanFlags)
+		scanFlags.BoolVar(&opts.watch, "watch", false, "Rescan whenever a manifest changes (never exits)")
+		scanFlags.DurationVar(&opts.watchInterval, "watch-interval", watchInterval, "How often --watch polls for changes")
 		scanFlags.Parse(os.Args[2:])
-
-		jsonOutput = *jsonPtr
-		includeMedium = *mediumPtr
 		paths = scanFlags.Args()
+		fs = scanFlags
+		if err := applyEnv(scanFlags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
 
 		if len(paths) < 1 {
 			fmt.Fprintln(os.Stderr, "Error: scan requires a path argument")
-			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan scan <path> [--json] [--include-medium]")
+			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan scan [flags] <path>")
+			os.Exit(int(types.ExitError))
+		}
+		if opts.watchInterval <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: --watch-interval must be positive")
 			os.Exit(int(types.ExitError))
 		}
 
 	case "diff":
 		diffFlags := flag.NewFlagSet("diff", flag.ExitOnError)
-		jsonPtr := diffFlags.Bool("json", false, "Output in JSON format")
-		mediumPtr := diffFlags.Bool("include-medium", false, "Include medium severity findings")
+		opts.registerFlags(diffFlags)
+		diffFlags.StringVar(&opts.since, "since", "", "Diff changed manifests in the working tree against a git ref")
+		diffFlags.BoolVar(&opts.showResolved, "show-resolved", false, "Also report findings the new manifests fix, marked as resolved")
 		diffFlags.Parse(os.Args[2:])
-
-		jsonOutput = *jsonPtr
-		includeMedium = *mediumPtr
 		paths = diffFlags.Args()
+		fs = diffFlags
+		if err := applyEnv(diffFlags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
 
-		if len(paths) < 2 {
+		if len(paths) != 2 && opts.since == "" {
 			fmt.Fprintln(os.Stderr, "Error: diff requires two path arguments")
-			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan diff <old> <new> [--json] [--include-medium]")
+			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan diff [flags] <old> <new>")
+			fmt.Fprintln(os.Stderr, "       k8s-danger-scan diff --since <ref> [flags] [path...]")
+			os.Exit(int(types.ExitError))
+		}
+
+	case "git-diff":
+		gitFlags := flag.NewFlagSet("git-diff", flag.ExitOnError)
+		opts.registerFlags(gitFlags)
+		gitFlags.BoolVar(&opts.showResolved, "show-resolved", false, "Also report findings the new revision fixes, marked as resolved")
+		gitFlags.Parse(os.Args[2:])
+		paths = gitFlags.Args()
+		fs = gitFlags
+		if err := applyEnv(gitFlags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+
+		if len(paths) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: git-diff requires two git revisions")
+			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan git-diff [flags] <old-ref> <new-ref> [path...]")
+			os.Exit(int(types.ExitError))
+		}
+
+	case "cluster":
+		clusterFlags := flag.NewFlagSet("cluster", flag.ExitOnError)
+		opts.registerFlags(clusterFlags)
+		clusterFlags.StringVar(&opts.cluster.Context, "context", "", "kubeconfig context to use (default: current context)")
+		clusterFlags.StringVar(&opts.cluster.Namespace, "namespace", "", "Namespace to scan (default: the context's namespace)")
+		clusterFlags.StringVar(&opts.cluster.Namespace, "n", "", "Shorthand for --namespace")
+		clusterFlags.BoolVar(&opts.cluster.AllNamespaces, "all-namespaces", false, "Scan every namespace")
+		clusterFlags.BoolVar(&opts.cluster.AllNamespaces, "A", false, "Shorthand for --all-namespaces")
+		clusterFlags.StringVar(&opts.cluster.Selector, "selector", "", "Only scan resources matching a label selector")
+		clusterFlags.StringVar(&opts.cluster.Selector, "l", "", "Shorthand for --selector")
+		clusterFlags.Parse(os.Args[2:])
+		fs = clusterFlags
+		if err := applyEnv(clusterFlags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+
+		if clusterFlags.NArg() > 0 {
+			fmt.Fprintln(os.Stderr, "Error: cluster takes no path arguments")
+			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan cluster [--namespace <ns> | --all-namespaces] [--selector <labels>] [flags]")
+			os.Exit(int(types.ExitError))
+		}
+
+	case "baseline":
+		if len(os.Args) < 3 || os.Args[2] != "create" {
+			fmt.Fprintln(os.Stderr, "Error: unknown baseline subcommand")
+			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan baseline create [--output <file>] [flags] <path>")
+			os.Exit(int(types.ExitError))
+		}
+		baselineFlags := flag.NewFlagSet("baseline create", flag.ExitOnError)
+		opts.registerFlags(baselineFlags)
+		baselineFlags.StringVar(&opts.baselineOutput, "output", "baseline.json", "File to write the baseline to")
+		baselineFlags.Parse(os.Args[3:])
+		paths = baselineFlags.Args()
+		fs = baselineFlags
+		if err := applyEnv(baselineFlags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+
+		if len(paths) < 1 || opts.baseline != "" {
+			fmt.Fprintln(os.Stderr, "Error: baseline create requires a path argument and takes no --baseline")
+			fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan baseline create [--output <file>] [flags] <path>")
 			os.Exit(int(types.ExitError))
 		}
 
@@ -96,79 +371,1432 @@ func main() {
 		os.Exit(int(types.ExitError))
 	}
 
+	// .danger-scan.yaml sits in the scanned tree; for diff, the new one
+	configRoot := paths
+	switch {
+	case command == "git-diff":
+		// The arguments are revisions; read the config from the working tree
+		configRoot = nil
+	case command == "diff" && opts.since == "":
+		configRoot = paths[1:]
+	}
+	project, projectWarnings, err := loadProject(fs, opts.configFile, configRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(int(types.ExitError))
+	}
+
 	// Create scanner with options
 	scanOptions := types.ScanOptions{
-		IncludeMedium: includeMedium,
-		OutputFormat:  types.FormatHuman,
+		OutputFormat:   types.FormatHuman,
+		Strict:         opts.strict,
+		ShowSnippet:    opts.showSnippet,
+		DisabledRules:  append(append([]string(nil), project.Disabled...), opts.disableRules...),
+		OnlyRules:      opts.onlyRules,
+		ShowSuppressed: opts.showSuppressed,
+		Concurrency:    opts.concurrency,
+		ShowResolved:   opts.showResolved,
 	}
 
-	if jsonOutput {
-		scanOptions.OutputFormat = types.FormatJSON
+	targets, err := resolveTargets(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(int(types.ExitError))
 	}
+	scanOptions.OutputFormat = stdoutFormat(targets)
 
-	s := scanner.NewScanner(scanOptions)
+	// Parse the template up front so mistakes surface before scanning
+	var tmpl *template.Template
+	if opts.templateFile != "" {
+		tmpl, err = output.ParseTemplate(opts.templateFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+	}
+
+	logLevel := logger.LevelNormal
+	if opts.quiet {
+		logLevel = logger.LevelQuiet
+	} else if opts.debug {
+		logLevel = logger.LevelDebug
+	} else if opts.verbose {
+		logLevel = logger.LevelVerbose
+	}
+	log := logger.New(os.Stderr, logLevel)
+
+	if opts.includeMedium {
+		log.Warnf("--include-medium is deprecated, use --min-severity medium")
+		if opts.minSeverity == "" {
+			scanOptions.MinSeverity = types.Medium
+		}
+	}
+
+	if opts.minSeverity != "" {
+		severity, err := config.ParseSeverity(opts.minSeverity)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --min-severity: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+		scanOptions.MinSeverity = severity
+	}
+
+	// --fail-on can reach below the reported severities, so scan down to
+	// the lower of the two and trim the report afterwards
+	failOn := types.Medium
+	var reportMin types.Severity
+	if opts.failOn != "" {
+		severity, err := config.ParseSeverity(opts.failOn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --fail-on: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+		failOn = severity
+
+		reportMin = scanOptions.MinSeverity
+		if reportMin == "" {
+			reportMin = types.High
+		}
+		if failOn.Rank() < reportMin.Rank() {
+			scanOptions.MinSeverity = failOn
+		} else {
+			reportMin = ""
+		}
+	}
+
+	if opts.categories != "" {
+		categories, err := config.ParseCategories(opts.categories)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+		scanOptions.Categories = categories
+	}
+
+	if opts.pssLevel != "" {
+		level, err := config.ParsePSSLevel(opts.pssLevel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --pss-level: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+		scanOptions.PSSLevel = level
+	}
+
+	var compliance types.ComplianceFramework
+	if opts.compliance != "" {
+		compliance, err = config.ParseCompliance(opts.compliance)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --compliance: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+	}
+
+	overrides, configWarnings, err := loadOverrides(opts, project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(int(types.ExitError))
+	}
+	scanOptions.Overrides = overrides
+
+	// Load the baseline up front so a bad file fails before scanning
+	var base *baseline.File
+	if opts.baseline != "" {
+		loaded, err := baseline.Load(opts.baseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+		base = &loaded
+	}
+	var accepted *exceptions.File
+	if opts.exceptions != "" {
+		loaded, err := exceptions.Load(opts.exceptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(int(types.ExitError))
+		}
+		accepted = &loaded
+	}
+	configWarnings = append(projectWarnings, configWarnings...)
+
+	s := scanner.NewScanner(scanOptions, scanner.WithLogger(log.Slog()))
+	customIDs, err := addCustomRules(s, opts.rulesDir, scanOptions.Categories)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(int(types.ExitError))
+	}
+	if err := checkRuleFlags(opts, customIDs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(int(types.ExitError))
+	}
+	parseOptions := parser.ParseOptions{
+		Raw:              opts.raw,
+		Helm:             opts.helm || len(opts.helmValues) > 0,
+		HelmValues:       opts.helmValues,
+		SkipNonManifests: opts.preCommit,
+		Limits: parser.Limits{
+			MaxFileBytes: int64(opts.maxFileSize) << 20,
+			MaxDepth:     opts.maxDepth,
+		},
+		Exclude:     opts.exclude,
+		Concurrency: opts.concurrency,
+		Logger:      log.Slog(),
+	}
+	out := outputConfig{
+		targets:    targets,
+		template:   tmpl,
+		showStats:  opts.verbose || opts.debug,
+		color:      !opts.noColor && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb",
+		compliance: compliance,
+	}
+
+	// Progress lines are only drawn for interactive human-readable runs
+	if (scanOptions.OutputFormat == types.FormatHuman || scanOptions.OutputFormat == types.FormatTable) && !opts.watch && logger.IsTerminal(os.Stdout) && logger.IsTerminal(os.Stderr) {
+		log.WithProgress(true)
+		parseOptions.Progress = parseProgress(log)
+	}
+
+	if opts.watch {
+		runWatch(s, log, parseOptions, out, configWarnings, paths, opts.watchInterval)
+	}
 
 	var result types.ScanResult
-	var err error
+	start := time.Now()
 
+	// Interrupting a run stops any kustomize or helm it started
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	switch command {
-	case "scan":
-		result, err = runScan(s, paths)
+	case "scan", "baseline":
+		result, err = runScan(ctx, s, log, parseOptions, paths)
+
+	case "cluster":
+		result, err = runCluster(ctx, s, log, opts.cluster)
 
 	case "diff":
-		result, err = runDiff(s, paths[0], paths[1])
+		if opts.since != "" {
+			result, err = runGitDiff(ctx, s, log, parseOptions, opts.since, "", paths)
+		} else {
+			result, err = runDiff(ctx, s, log, parseOptions, paths[0], paths[1])
+		}
+
+	case "git-diff":
+		result, err = runGitDiff(ctx, s, log, parseOptions, paths[0], paths[1], paths[2:])
 
 	default:
 		fmt.Fprintf(os.Stderr, "Error: unknown command '%s'\n", command)
 		printUsage()
 		os.Exit(int(types.ExitError))
 	}
+	stop()
+	log.ClearProgress()
+
+	if err == nil && opts.strictParse {
+		if parseErr := parseFailures(result.Warnings); parseErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", parseErr)
+			if !opts.exitZero {
+				os.Exit(int(types.ExitError))
+			}
+		}
+	}
 
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(int(types.ExitError))
 	}
 
-	// Calculate summary
-	summary := scanner.GetSummary(result.Findings)
+	result.Warnings = append(configWarnings, result.Warnings...)
+	result.Stats.ElapsedMillis = time.Since(start).Milliseconds()
+
+	// Excepted findings are left out of a new baseline, so they come back
+	// once the exception expires
+	if accepted != nil {
+		var warnings []types.Warning
+		result.Findings, result.ExceptionsApplied, warnings = accepted.Apply(result.Findings, time.Now())
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+	if command == "baseline" {
+		os.Exit(int(writeBaseline(result, opts.baselineOutput, log)))
+	}
+	if base != nil {
+		result.Findings, result.Baselined = base.Filter(result.Findings)
+	}
 
-	// Output results
-	formatter := output.NewFormatter(os.Stdout, scanOptions.OutputFormat)
-	if err := formatter.Output(result.Findings, summary); err != nil {
+	exitCode := scanner.ExitCodeFor(result.Findings, failOn)
+	if reportMin != "" {
+		result.Findings = scanner.FilterMinSeverity(result.Findings, reportMin)
+		if scanner.ExitCodeFor(result.Findings, failOn) != exitCode {
+			log.Warnf("findings below --min-severity %s fail the run under --fail-on %s", strings.ToLower(string(reportMin)), strings.ToLower(string(failOn)))
+		}
+	}
+
+	if err := writeResult(result, out, log); err != nil {
 		fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
 		os.Exit(int(types.ExitError))
 	}
 
-	// Exit with appropriate code
-	exitCode := scanner.GetExitCode(result.Findings)
+	// Exit with appropriate code; report mode never fails the pipeline
+	if opts.printExitCode {
+		printExitCode(exitCode, opts.exitZero)
+	}
+	if opts.exitZero {
+		exitCode = types.ExitOK
+	}
 	os.Exit(int(exitCode))
 }
 
+// cliOptions holds the flags shared by the scan, diff and cluster commands
+type cliOptions struct {
+	jsonOutput     bool
+	csvOutput      bool
+	formats        listFlags
+	noColor        bool
+	helm           bool
+	helmValues     listFlags
+	includeMedium  bool
+	minSeverity    string
+	failOn         string
+	verbose        bool
+	debug          bool
+	quiet          bool
+	rulesFile      string
+	rulesDir       string
+	baseline       string
+	exceptions     string
+	baselineOutput string
+	configURL      string
+	allowFetchErr  bool
+	categories     string
+	templateFile   string
+	watch          bool
+	watchInterval  time.Duration
+	since          string
+	showResolved   bool
+	strict         bool
+	strictParse    bool
+	raw            bool
+	preCommit      bool
+	showSnippet    bool
+	showSuppressed bool
+	maxFileSize    int
+	maxDepth       int
+	concurrency    int
+	exclude        listFlags
+	disableRules   listFlags
+	onlyRules      listFlags
+	exitZero       bool
+	printExitCode  bool
+	configFile     string
+	pssLevel       string
+	compliance     string
+	cluster        cluster.Options
+}
+
+// registerFlags binds the shared flags to a command's flag set
+func (o *cliOptions) registerFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.jsonOutput, "json", false, "Output in JSON format")
+	fs.BoolVar(&o.csvOutput, "csv", false, "Output one CSV row per finding")
+	fs.Var(&o.formats, "format", "Output format, optionally written to a file: human, table, json, csv, sarif, markdown, html or template[=file] (repeatable)")
+	fs.BoolVar(&o.noColor, "no-color", false, "Disable colors in human and table output")
+	fs.StringVar(&o.templateFile, "template", "", "Render output with a Go text/template file")
+	fs.BoolVar(&o.includeMedium, "include-medium", false, "Deprecated: use --min-severity medium")
+	fs.StringVar(&o.minSeverity, "min-severity", "", "Lowest severity to report: low, medium, high, critical (default: high)")
+	fs.StringVar(&o.failOn, "fail-on", "", "Lowest severity that gives a non-zero exit code, reported or not: low, medium, high, critical (default: reported findings from medium up)")
+	fs.BoolVar(&o.verbose, "verbose", false, "Print informational messages and scan statistics")
+	fs.BoolVar(&o.verbose, "v", false, "Shorthand for --verbose")
+	fs.BoolVar(&o.debug, "vv", false, "Like --verbose, and also print debug messages such as why files were skipped")
+	fs.BoolVar(&o.quiet, "quiet", false, "Suppress warnings on stderr")
+	fs.StringVar(&o.categories, "categories", "", "Comma-separated rule categories to run (default: all but observability and supply-chain)")
+	fs.Var(&o.disableRules, "disable-rule", "Rule ID to skip for this run (repeatable)")
+	fs.Var(&o.onlyRules, "only-rule", "Rule ID to run, skipping all others (repeatable)")
+	fs.BoolVar(&o.showSnippet, "show-snippet", false, "Show the YAML of the element that triggered each finding")
+	fs.BoolVar(&o.showSuppressed, "show-suppressed", false, "Report findings suppressed by danger-scan/ignore annotations, marked as suppressed")
+	fs.BoolVar(&o.strict, "strict", false, "Flag containers with no securityContext at all")
+	fs.StringVar(&o.pssLevel, "pss-level", "", "Evaluate pods against a Pod Security Standards level: baseline or restricted")
+	fs.StringVar(&o.pssLevel, "pss", "", "Shorthand for --pss-level")
+	fs.StringVar(&o.compliance, "compliance", "", "Group human, table and markdown output by the controls of a benchmark: cis")
+	fs.BoolVar(&o.strictParse, "strict-parse", false, "Treat any file that fails to parse as a fatal error")
+	fs.BoolVar(&o.exitZero, "exit-zero", false, "Always exit 0 once results are reported, regardless of findings or parse failures")
+	fs.BoolVar(&o.printExitCode, "print-exit-code", false, "Print the exit code and its meaning to stderr as the last line")
+	fs.IntVar(&o.maxFileSize, "max-file-size", 0, "Largest manifest file parsed, in MiB; -1 disables the limit (default: 32)")
+	fs.IntVar(&o.maxDepth, "max-depth", 0, "Deepest YAML nesting parsed; -1 disables the limit (default: 100)")
+	fs.Var(&o.exclude, "exclude", "Glob pattern for paths to skip, e.g. '**/test/**' (repeatable)")
+	fs.IntVar(&o.concurrency, "concurrency", 0, "Number of files parsed and resources scanned at once (default: one per CPU)")
+	fs.BoolVar(&o.helm, "helm", false, "Render directories containing a Chart.yaml with helm template before scanning")
+	fs.Var(&o.helmValues, "values", "Values file passed to helm template (repeatable; implies --helm)")
+	fs.BoolVar(&o.raw, "raw", false, "Scan kustomization directories file by file instead of running kustomize build")
+	fs.BoolVar(&o.preCommit, "pre-commit", false, "Skip file arguments that are not .yaml, .yml or .json and succeed when none are left")
+	fs.StringVar(&o.configFile, "config", "", "Path to a .danger-scan.yaml (default: the one in the scan root, if any)")
+	fs.StringVar(&o.rulesFile, "rules-file", "", "Path to a rules.yaml with per-rule severity and message overrides")
+	fs.StringVar(&o.baseline, "baseline", "", "Only report findings not recorded in this baseline file")
+	fs.StringVar(&o.exceptions, "exceptions", "", "Path to an exceptions.yaml of approved, expiring exceptions")
+	fs.StringVar(&o.rulesDir, "rules-dir", "", "Directory of YAML files defining custom rules to run alongside the built-in ones")
+	fs.StringVar(&o.configURL, "config-url", "", "URL of a centrally managed rules.yaml")
+	fs.BoolVar(&o.allowFetchErr, "allow-config-fetch-failure", false, "Continue with built-in defaults if --config-url cannot be loaded")
+}
+
+// printExitCode reports the exit code computed from the findings on stderr,
+// so it never mixes with JSON or CSV on stdout. With --exit-zero it shows
+// the code the findings would otherwise have produced.
+func printExitCode(code types.ExitCode, exitZero bool) {
+	if exitZero && code != types.ExitOK {
+		fmt.Fprintf(os.Stderr, "Exit code: 0 (--exit-zero; findings would give %d: %s)\n", code, code.Meaning())
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Exit code: %d (%s)\n", code, code.Meaning())
+}
+
+// loadProject reads the .danger-scan.yaml named by --config, or found in the
+// scan root, and applies its options to flags not already set on the command
+// line or in the environment
+func loadProject(fs *flag.FlagSet, path string, paths []string) (config.Project, []types.Warning, error) {
+	if path == "" {
+		path = config.FindProjectFile(paths)
+	}
+	if path == "" {
+		return config.Project{}, nil, nil
+	}
+
+	project, warnings, err := config.LoadProject(path)
+	if err != nil {
+		return config.Project{}, nil, err
+	}
+
+	set := setFlags(fs)
+	names := make([]string, 0, len(project.Options))
+	for name := range project.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if fs.Lookup(name) == nil || name == "config" {
+			return config.Project{}, nil, fmt.Errorf("%s: unknown option %q for %s", path, name, fs.Name())
+		}
+		if set[name] {
+			continue
+		}
+		if err := fs.Set(name, project.Options[name]); err != nil {
+			return config.Project{}, nil, fmt.Errorf("%s: invalid option %s: %w", path, name, err)
+		}
+	}
+	return project, warnings, nil
+}
+
+// envPrefix starts the environment variable that stands in for each flag
+const envPrefix = "DANGER_SCAN_"
+
+// envName returns the environment variable for a flag, e.g.
+// DANGER_SCAN_MIN_SEVERITY for --min-severity
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// flagAliases maps shorthand flags to the flag they stand in for. Only the
+// full flag is read from the environment.
+var flagAliases = map[string]string{
+	"v":   "verbose",
+	"pss": "pss-level",
+}
+
+// setFlags returns the names of the flags given on the command line. A
+// shorthand counts as its full flag being given too.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+		if name, ok := flagAliases[f.Name]; ok {
+			set[name] = true
+		}
+	})
+	return set
+}
+
+// applyEnv fills flags that were not given on the command line from their
+// DANGER_SCAN_* environment variables, so the precedence is flags, then
+// environment, then built-in defaults
+func applyEnv(fs *flag.FlagSet) error {
+	set := setFlags(fs)
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, alias := flagAliases[f.Name]; alias || err != nil || set[f.Name] {
+			return
+		}
+		env := envName(f.Name)
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid %s=%q: %w", env, value, setErr)
+		}
+	})
+	return err
+}
+
+// parseProgress returns a parser progress callback that redraws the
+// progress line at most every progressInterval
+func parseProgress(log *logger.Logger) func(done, total int) {
+	var last time.Time
+	return func(done, total int) {
+		if done < total && time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+		log.Progressf("Parsing %d/%d files…", done, total)
+	}
+}
+
+// listFlags collects the values of a repeatable, comma-separated flag
+type listFlags []string
+
+func (f *listFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlags) Set(value string) error {
+	*f = append(*f, strings.Split(value, ",")...)
+	return nil
+}
+
+// resolveTargets combines --format values with the --json, --csv and
+// --template shorthands. At most one output may go to stdout; with none
+// requested, human-readable output is written there.
+func resolveTargets(opts cliOptions) ([]output.Target, error) {
+	var targets []output.Target
+	for _, spec := range opts.formats {
+		target, err := output.ParseTarget(spec)
+		if err != nil {
+			return nil, fmt.Errorf("--format: %w", err)
+		}
+		targets = append(targets, target)
+	}
+
+	if opts.jsonOutput {
+		targets = append(targets, output.Target{Format: types.FormatJSON})
+	}
+	if opts.csvOutput {
+		targets = append(targets, output.Target{Format: types.FormatCSV})
+	}
+
+	hasTemplate := false
+	for _, target := range targets {
+		if target.Format == types.FormatTemplate {
+			hasTemplate = true
+		}
+	}
+	if opts.templateFile != "" && !hasTemplate {
+		targets = append(targets, output.Target{Format: types.FormatTemplate})
+	}
+	if hasTemplate && opts.templateFile == "" {
+		return nil, fmt.Errorf("--format template requires --template <file>")
+	}
+
+	toStdout := 0
+	for _, target := range targets {
+		if target.Path == "" {
+			toStdout++
+		}
+	}
+	if toStdout > 1 {
+		return nil, fmt.Errorf("only one output can be written to stdout; use --format <format>=<file> for the others")
+	}
+
+	if len(targets) == 0 {
+		targets = append(targets, output.Target{Format: types.FormatHuman})
+	}
+	return targets, nil
+}
+
+// stdoutFormat returns the format written to stdout, or "" if every output goes to a file
+func stdoutFormat(targets []output.Target) types.OutputFormat {
+	for _, target := range targets {
+		if target.Path == "" {
+			return target.Format
+		}
+	}
+	return ""
+}
+
+// outputConfig describes how results are rendered
+type outputConfig struct {
+	targets    []output.Target
+	template   *template.Template
+	showStats  bool
+	color      bool // Color stdout when it is a terminal
+	compliance types.ComplianceFramework
+}
+
+// writeResult logs warnings and writes the result and its summary to each
+// requested output
+func writeResult(result types.ScanResult, out outputConfig, log *logger.Logger) error {
+	// JSON output on stdout carries warnings in the document itself
+	if stdoutFormat(out.targets) != types.FormatJSON {
+		for _, w := range result.Warnings {
+			log.Warnf("%s: %s", w.Path, w.Message)
+		}
+	}
+
+	summary := scanner.GetSummary(result.Findings)
+	summary.Warnings = len(result.Warnings)
+	summary.Baselined = result.Baselined
+	summary.Excepted = len(result.ExceptionsApplied)
+	summary.Unchanged = result.Unchanged
+
+	for _, target := range out.targets {
+		if err := writeTarget(target, out, result, summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTarget renders the result in one format to stdout or a file
+func writeTarget(target output.Target, out outputConfig, result types.ScanResult, summary types.Summary) error {
+	render := func(w io.Writer) error {
+		color := out.color && target.Path == "" && logger.IsTerminal(os.Stdout)
+		formatter := output.NewFormatter(w, target.Format).WithStats(out.showStats).WithVersion(version).WithColor(color).WithCompliance(out.compliance)
+		if target.Format == types.FormatTemplate {
+			formatter = formatter.WithTemplate(out.template)
+		}
+		return formatter.Output(result, summary)
+	}
+
+	if target.Path == "" {
+		return render(os.Stdout)
+	}
+
+	f, err := os.Create(target.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target.Path, err)
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeBaseline saves the findings of a scan as a baseline file and reports
+// how many were recorded
+func writeBaseline(result types.ScanResult, path string, log *logger.Logger) types.ExitCode {
+	for _, w := range result.Warnings {
+		log.Warnf("%s: %s", w.Path, w.Message)
+	}
+
+	file := baseline.New(result.Findings)
+	if err := file.Write(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d finding(s) to %s\n", len(file.Findings), path)
+	return types.ExitOK
+}
+
+// runWatch rescans paths each time a manifest changes, polling every
+// interval. Exit codes do not apply in watch mode; it runs until interrupted.
+func runWatch(s *scanner.Scanner, log *logger.Logger, parseOptions parser.ParseOptions, out outputConfig, configWarnings []types.Warning, paths []string, interval time.Duration) {
+	watch.Run(paths, interval, func() {
+		start := time.Now()
+		if format := stdoutFormat(out.targets); format == types.FormatHuman || format == types.FormatTable {
+			// Clear the screen so each run starts fresh
+			fmt.Print("\033[H\033[2J")
+		}
+
+		result, err := runScan(context.Background(), s, log, parseOptions, paths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		result.Warnings = append(configWarnings, result.Warnings...)
+		result.Stats.ElapsedMillis = time.Since(start).Milliseconds()
+
+		if err := writeResult(result, out, log); err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+		}
+	})
+}
+
+// runExplain prints the extended documentation for a single rule
+func runExplain(args []string) types.ExitCode {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: explain requires a rule ID")
+		fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan explain <rule-id>")
+		return types.ExitError
+	}
+
+	e, ok := rules.Explain(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown rule ID '%s'\n", args[0])
+		fmt.Fprintf(os.Stderr, "Known rules: %s\n", strings.Join(rules.ExplainedRuleIDs(), ", "))
+		return types.ExitError
+	}
+
+	fmt.Printf("%s (%s)\n", e.RuleID, e.Severity)
+	fmt.Printf("%s\n\n", e.Title)
+	fmt.Printf("WHAT IT DETECTS\n%s\n\n", e.Description)
+	fmt.Printf("WHY IT MATTERS\n%s\n\n", e.Why)
+	fmt.Printf("BEFORE\n%s\n\n", indent(e.Before))
+	fmt.Printf("AFTER\n%s\n", indent(e.After))
+	if len(e.References) > 0 {
+		fmt.Printf("\nREFERENCES\n")
+		for _, ref := range e.References {
+			fmt.Printf("  - %s\n", ref)
+		}
+	}
+	return types.ExitOK
+}
+
+// runRules handles the rules subcommands; list prints the built-in rules as a
+// table or, with --json, as an array of rule metadata
+func runRules(args []string) types.ExitCode {
+	if len(args) < 1 || args[0] != "list" {
+		fmt.Fprintln(os.Stderr, "Error: rules requires a subcommand")
+		fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan rules list [--json]")
+		return types.ExitError
+	}
+
+	fs := flag.NewFlagSet("rules list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the rules as JSON")
+	fs.Parse(args[1:])
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument '%s'\n", fs.Arg(0))
+		return types.ExitError
+	}
+
+	infos := rules.Rules()
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(infos); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return types.ExitError
+		}
+		return types.ExitOK
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSEVERITY\tCATEGORY\tDEFAULT\tTITLE")
+	for _, info := range infos {
+		enabled := "yes"
+		if !info.Default {
+			enabled = "opt-in"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.ID, info.Severity, info.Category, enabled, info.Title)
+	}
+	w.Flush()
+	fmt.Printf("\n%d rules. Run 'k8s-danger-scan explain <rule-id>' for details.\n", len(infos))
+	return types.ExitOK
+}
+
+// runSelftest checks every built-in rule against its embedded fixtures and
+// prints one line per rule
+func runSelftest() types.ExitCode {
+	results := selftest.Run()
+	failed := 0
+	for _, r := range results {
+		if r.Passed {
+			fmt.Printf("PASS  %s\n", r.RuleID)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s: %s\n", r.RuleID, r.Message)
+	}
+
+	fmt.Printf("\n%d rules: %d passed, %d failed\n", len(results), len(results)-failed, failed)
+	if failed > 0 {
+		return types.ExitError
+	}
+	return types.ExitOK
+}
+
+// runAnnotate scans the given paths and writes danger-scan/ignore
+// annotations for the selected findings back into their source files
+func runAnnotate(args []string) types.ExitCode {
+	var keys listFlags
+	var all, dryRun, strict bool
+	var minSeverity, categories, pssLevel string
+
+	fs := flag.NewFlagSet("annotate", flag.ExitOnError)
+	fs.Var(&keys, "finding", "Finding to accept: a fingerprint or rule-id:Kind/[namespace/]name (repeatable)")
+	fs.BoolVar(&all, "all", false, "Accept every reported finding")
+	fs.BoolVar(&dryRun, "dry-run", false, "Report what would be annotated without writing files")
+	fs.StringVar(&minSeverity, "min-severity", "", "Lowest severity to consider: low, medium, high, critical (default: high)")
+	fs.StringVar(&categories, "categories", "", "Comma-separated rule categories to run")
+	fs.BoolVar(&strict, "strict", false, "Run the strict rules as well")
+	fs.StringVar(&pssLevel, "pss-level", "", "Evaluate pods against a Pod Security Standards level: baseline or restricted")
+	fs.StringVar(&pssLevel, "pss", "", "Shorthand for --pss-level")
+	fs.Parse(args)
+	paths := fs.Args()
+
+	if len(paths) < 1 || (len(keys) == 0 && !all) {
+		fmt.Fprintln(os.Stderr, "Error: annotate requires a path and --finding or --all")
+		fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan annotate [--finding <key>]... [--all] [--dry-run] <path>")
+		return types.ExitError
+	}
+
+	scanOptions, err := ruleOptions(minSeverity, categories, pssLevel, strict)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+
+	log := logger.New(os.Stderr, logger.LevelNormal)
+	result, err := runScan(context.Background(), scanner.NewScanner(scanOptions), log, parser.ParseOptions{Raw: true}, paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+
+	wanted := make(map[string]bool)
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	// Group the selected findings by file, then by resource. A rule-id key
+	// selects the rule's findings on every container of the resource.
+	targets := make(map[string][]annotate.Target)
+	matched := make(map[string]bool)
+	var files []string
+	for _, f := range result.Findings {
+		key := findingKey(f)
+		if !all && !wanted[f.Fingerprint] && !wanted[key] {
+			continue
+		}
+		matched[f.Fingerprint] = true
+		matched[key] = true
+
+		if !annotatable(f.File) {
+			log.Warnf("%s: cannot annotate %s %s (not a YAML file on disk)", f.RuleID, f.Kind, f.Name)
+			continue
+		}
+		if _, ok := targets[f.File]; !ok {
+			files = append(files, f.File)
+		}
+		targets[f.File] = addTarget(targets[f.File], f)
+	}
+	for _, key := range keys {
+		if !matched[key] {
+			log.Warnf("no finding matches %s", key)
+		}
+	}
+
+	total, changedFiles := 0, 0
+	for _, file := range files {
+		changed, err := annotate.File(file, targets[file], dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return types.ExitError
+		}
+		if changed > 0 {
+			total += changed
+			changedFiles++
+			fmt.Printf("%s: %d resource(s)\n", file, changed)
+		}
+	}
+
+	verb := "Annotated"
+	if dryRun {
+		verb = "Would annotate"
+	}
+	fmt.Printf("%s %d resource(s) in %d file(s)\n", verb, total, changedFiles)
+	return types.ExitOK
+}
+
+// runFix applies safe remediations to the manifests under the given paths,
+// or prints them as a unified diff with --dry-run
+func runFix(args []string) types.ExitCode {
+	var dryRun bool
+
+	fs := flag.NewFlagSet("fix", flag.ExitOnError)
+	fs.BoolVar(&dryRun, "dry-run", false, "Print a unified diff instead of writing files")
+	fs.Parse(args)
+	paths := fs.Args()
+
+	if len(paths) < 1 {
+		fmt.Fprintln(os.Stderr, "Error: fix requires a path")
+		fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan fix [--dry-run] <path>")
+		return types.ExitError
+	}
+
+	files, err := manifestFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+
+	total, changedFiles := 0, 0
+	for _, file := range files {
+		result, err := fix.File(file, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return types.ExitError
+		}
+		if len(result.Changes) == 0 {
+			continue
+		}
+		total += len(result.Changes)
+		changedFiles++
+
+		if dryRun {
+			fmt.Print(result.Diff)
+			continue
+		}
+		fmt.Printf("%s:\n", file)
+		for _, change := range result.Changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+
+	// Keep stdout a clean patch on a dry run
+	summary := os.Stdout
+	verb := "Applied"
+	if dryRun {
+		summary = os.Stderr
+		verb = "Would apply"
+	}
+	fmt.Fprintf(summary, "%s %d fix(es) in %d file(s)\n", verb, total, changedFiles)
+	return types.ExitOK
+}
+
+// manifestFiles expands paths into the YAML files under them, in walk order
+func manifestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if annotatable(p) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", path, err)
+		}
+	}
+	return files, nil
+}
+
+// runOperator scans the cluster every --interval until interrupted, writing
+// the findings for each namespace to its DangerScanReport
+func runOperator(args []string) types.ExitCode {
+	if len(args) > 0 && args[0] == "crd" {
+		fmt.Print(operator.CRD)
+		return types.ExitOK
+	}
+
+	var opts cliOptions
+	var options operator.Options
+	var interval time.Duration
+	var once bool
+
+	fs := flag.NewFlagSet("operator", flag.ExitOnError)
+	fs.DurationVar(&interval, "interval", operatorInterval, "Time between scans")
+	fs.BoolVar(&once, "once", false, "Scan and write the reports once, then exit")
+	fs.StringVar(&options.Cluster.Context, "context", "", "kubeconfig context to use (default: in-cluster config or the current context)")
+	fs.StringVar(&options.Cluster.Namespace, "namespace", "", "Only scan this namespace (default: every namespace)")
+	fs.StringVar(&options.Cluster.Namespace, "n", "", "Shorthand for --namespace")
+	fs.StringVar(&options.Cluster.Selector, "selector", "", "Only scan resources matching a label selector")
+	fs.StringVar(&options.Cluster.Selector, "l", "", "Shorthand for --selector")
+	fs.StringVar(&options.ClusterNamespace, "cluster-namespace", "", "Namespace whose report holds findings on cluster-scoped objects (default: the operator's namespace)")
+	fs.StringVar(&opts.minSeverity, "min-severity", "", "Lowest severity to report: low, medium, high, critical (default: high)")
+	fs.StringVar(&opts.categories, "categories", "", "Comma-separated rule categories to run")
+	fs.BoolVar(&opts.strict, "strict", false, "Run the strict rules as well")
+	fs.StringVar(&opts.pssLevel, "pss-level", "", "Evaluate pods against a Pod Security Standards level: baseline or restricted")
+	fs.StringVar(&opts.pssLevel, "pss", "", "Shorthand for --pss-level")
+	fs.StringVar(&opts.configFile, "config", "", "Path to a .danger-scan.yaml to disable rules and override severities")
+	fs.StringVar(&opts.rulesFile, "rules-file", "", "Path to a rules.yaml with per-rule severity and message overrides")
+	fs.StringVar(&opts.rulesDir, "rules-dir", "", "Directory of YAML files defining custom rules")
+	fs.BoolVar(&opts.verbose, "verbose", false, "Log each completed scan")
+	fs.BoolVar(&opts.verbose, "v", false, "Shorthand for --verbose")
+	fs.BoolVar(&opts.quiet, "quiet", false, "Suppress warnings on stderr")
+	fs.Parse(args)
+	if err := applyEnv(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+
+	if fs.NArg() > 0 || interval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: operator takes no path arguments, and --interval must be positive")
+		fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan operator [--interval <duration>] [--once] [--namespace <ns>] [flags]")
+		return types.ExitError
+	}
+	options.Cluster.AllNamespaces = options.Cluster.Namespace == ""
+
+	// Like serve, there is no scan root to find a .danger-scan.yaml in
+	var project config.Project
+	var configWarnings []types.Warning
+	var err error
+	if opts.configFile != "" {
+		project, configWarnings, err = loadProject(fs, opts.configFile, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return types.ExitError
+		}
+	}
+
+	scanOptions, err := ruleOptions(opts.minSeverity, opts.categories, opts.pssLevel, opts.strict)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+	overrides, overrideWarnings, err := loadOverrides(opts, project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+	scanOptions.Overrides = overrides
+	scanOptions.DisabledRules = project.Disabled
+
+	logLevel := logger.LevelNormal
+	if opts.quiet {
+		logLevel = logger.LevelQuiet
+	} else if opts.verbose {
+		logLevel = logger.LevelVerbose
+	}
+	log := logger.New(os.Stderr, logLevel)
+	for _, w := range append(configWarnings, overrideWarnings...) {
+		log.Warnf("%s: %s", w.Path, w.Message)
+	}
+
+	s := scanner.NewScanner(scanOptions)
+	if _, err := addCustomRules(s, opts.rulesDir, scanOptions.Categories); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	controller := operator.NewController(s, options, log)
+	if once {
+		if err := controller.Reconcile(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return types.ExitError
+		}
+		return types.ExitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "Writing DangerScanReports every %s\n", interval)
+	controller.Run(ctx, interval)
+	return types.ExitOK
+}
+
+// runServe runs the validating admission webhook until interrupted
+func runServe(args []string) types.ExitCode {
+	var opts cliOptions
+	var addr, certFile, keyFile, modeName string
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVar(&addr, "addr", ":8443", "Address to listen on")
+	fs.StringVar(&certFile, "tls-cert-file", "", "PEM certificate served to the API server")
+	fs.StringVar(&keyFile, "tls-key-file", "", "PEM private key for --tls-cert-file")
+	fs.StringVar(&modeName, "mode", string(webhook.ModeDeny), "What to do with HIGH and CRITICAL findings: deny, warn or audit")
+	fs.StringVar(&opts.minSeverity, "min-severity", "", "Lowest severity to report: low, medium, high, critical (default: high)")
+	fs.StringVar(&opts.categories, "categories", "", "Comma-separated rule categories to run")
+	fs.BoolVar(&opts.strict, "strict", false, "Run the strict rules as well")
+	fs.StringVar(&opts.pssLevel, "pss-level", "", "Evaluate pods against a Pod Security Standards level: baseline or restricted")
+	fs.StringVar(&opts.pssLevel, "pss", "", "Shorthand for --pss-level")
+	fs.StringVar(&opts.configFile, "config", "", "Path to a .danger-scan.yaml to disable rules and override severities")
+	fs.StringVar(&opts.rulesFile, "rules-file", "", "Path to a rules.yaml with per-rule severity and message overrides")
+	fs.StringVar(&opts.rulesDir, "rules-dir", "", "Directory of YAML files defining custom rules")
+	fs.BoolVar(&opts.quiet, "quiet", false, "Suppress warnings on stderr")
+	fs.Parse(args)
+	if err := applyEnv(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+
+	if fs.NArg() > 0 || (certFile == "") != (keyFile == "") {
+		fmt.Fprintln(os.Stderr, "Error: serve takes no path arguments, and --tls-cert-file and --tls-key-file go together")
+		fmt.Fprintln(os.Stderr, "Usage: k8s-danger-scan serve --tls-cert-file <cert> --tls-key-file <key> [--mode deny|warn|audit] [flags]")
+		return types.ExitError
+	}
+
+	mode, err := webhook.ParseMode(modeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --mode: %v\n", err)
+		return types.ExitError
+	}
+
+	// Unlike scan, there is no scan root to find a .danger-scan.yaml in
+	var project config.Project
+	var configWarnings []types.Warning
+	if opts.configFile != "" {
+		project, configWarnings, err = loadProject(fs, opts.configFile, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return types.ExitError
+		}
+	}
+
+	scanOptions, err := ruleOptions(opts.minSeverity, opts.categories, opts.pssLevel, opts.strict)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+	overrides, overrideWarnings, err := loadOverrides(opts, project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+	scanOptions.Overrides = overrides
+	scanOptions.DisabledRules = project.Disabled
+
+	logLevel := logger.LevelNormal
+	if opts.quiet {
+		logLevel = logger.LevelQuiet
+	}
+	log := logger.New(os.Stderr, logLevel)
+	for _, w := range append(configWarnings, overrideWarnings...) {
+		log.Warnf("%s: %s", w.Path, w.Message)
+	}
+
+	s := scanner.NewScanner(scanOptions)
+	if _, err := addCustomRules(s, opts.rulesDir, scanOptions.Categories); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           webhook.NewServer(s, mode, log, os.Stdout).Handler(),
+		ReadHeaderTimeout: serveReadTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		if certFile == "" {
+			log.Warnf("serving plain HTTP; the API server only calls webhooks over HTTPS, so terminate TLS in front of %s", addr)
+			errs <- server.ListenAndServe()
+			return
+		}
+		errs <- server.ListenAndServeTLS(certFile, keyFile)
+	}()
+	fmt.Fprintf(os.Stderr, "Serving admission reviews on %s/validate (mode: %s)\n", addr, mode)
+
+	select {
+	case err := <-errs:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	case <-ctx.Done():
+	}
+
+	// Let in-flight reviews finish so the API server doesn't see resets
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return types.ExitError
+	}
+	return types.ExitOK
+}
+
+// addCustomRules loads the custom rules in dir and adds those in the
+// selected categories, or the default ones, to the scanner. It returns the
+// IDs of every custom rule loaded, selected or not.
+func addCustomRules(s *scanner.Scanner, dir string, categories []types.Category) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	custom, err := config.LoadCustomRules(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) == 0 {
+		categories = rules.DefaultCategories()
+	}
+	compiled, err := rules.CustomRulesForCategories(custom, categories...)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range compiled {
+		s.AddRule(rule)
+	}
+
+	ids := make([]string, len(custom))
+	for i, c := range custom {
+		ids[i] = c.ID
+	}
+	return ids, nil
+}
+
+// checkRuleFlags rejects unknown rule IDs given to --disable-rule and
+// --only-rule. A typo in --only-rule would otherwise silence every rule.
+func checkRuleFlags(opts cliOptions, customIDs []string) error {
+	known := make(map[string]bool)
+	for _, id := range append(rules.RuleIDs(), customIDs...) {
+		known[id] = true
+	}
+	for _, selection := range []struct {
+		flag string
+		ids  []string
+	}{
+		{"--disable-rule", opts.disableRules},
+		{"--only-rule", opts.onlyRules},
+	} {
+		for _, id := range selection.ids {
+			if !known[id] {
+				return fmt.Errorf("unknown rule ID %q for %s (run 'k8s-danger-scan rules list' to see them)", id, selection.flag)
+			}
+		}
+	}
+	return nil
+}
+
+// ruleOptions builds the scan options that select rules and severities
+// for the commands that don't share the scan flags
+func ruleOptions(minSeverity, categories, pssLevel string, strict bool) (types.ScanOptions, error) {
+	options := types.ScanOptions{Strict: strict}
+	if minSeverity != "" {
+		severity, err := config.ParseSeverity(minSeverity)
+		if err != nil {
+			return types.ScanOptions{}, fmt.Errorf("--min-severity: %w", err)
+		}
+		options.MinSeverity = severity
+	}
+	if categories != "" {
+		parsed, err := config.ParseCategories(categories)
+		if err != nil {
+			return types.ScanOptions{}, err
+		}
+		options.Categories = parsed
+	}
+	if pssLevel != "" {
+		level, err := config.ParsePSSLevel(pssLevel)
+		if err != nil {
+			return types.ScanOptions{}, fmt.Errorf("--pss-level: %w", err)
+		}
+		options.PSSLevel = level
+	}
+	return options, nil
+}
+
+// findingKey returns the human-readable key accepted by annotate --finding.
+// The namespace is left out for resources that don't set one.
+func findingKey(f types.Finding) string {
+	if f.Namespace == "" {
+		return fmt.Sprintf("%s:%s/%s", f.RuleID, f.Kind, f.Name)
+	}
+	return fmt.Sprintf("%s:%s/%s/%s", f.RuleID, f.Kind, f.Namespace, f.Name)
+}
+
+// annotatable reports whether a finding's file can be edited in place.
+// Findings from archives, kustomize output or git refs have no such file.
+func annotatable(file string) bool {
+	info, err := os.Stat(file)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// addTarget records a finding's rule ID against its resource
+func addTarget(targets []annotate.Target, f types.Finding) []annotate.Target {
+	for i := range targets {
+		t := &targets[i]
+		if t.Kind == f.Kind && t.Name == f.Name && t.Namespace == f.Namespace {
+			if !slices.Contains(t.RuleIDs, f.RuleID) {
+				t.RuleIDs = append(t.RuleIDs, f.RuleID)
+			}
+			return targets
+		}
+	}
+	return append(targets, annotate.Target{
+		Kind:      f.Kind,
+		Name:      f.Name,
+		Namespace: f.Namespace,
+		RuleIDs:   []string{f.RuleID},
+	})
+}
+
+// indent prefixes every line of a YAML snippet so it stands out from prose
+func indent(snippet string) string {
+	return "    " + strings.ReplaceAll(snippet, "\n", "\n    ")
+}
+
+// loadOverrides resolves rule overrides from --config-url, .danger-scan.yaml
+// and --rules-file, each taking precedence over the one before for the same
+// rule.
+func loadOverrides(opts cliOptions, project config.Project) (map[string]types.RuleOverride, []types.Warning, error) {
+	overrides := make(map[string]types.RuleOverride)
+	var warnings []types.Warning
+
+	if opts.configURL != "" {
+		remote, remoteWarnings, err := config.FetchRuleOverrides(opts.configURL, config.DefaultCacheDir(), configFetchTimeout)
+		if err != nil {
+			if !opts.allowFetchErr {
+				return nil, nil, err
+			}
+			remoteWarnings = []types.Warning{{
+				Path:    opts.configURL,
+				Message: fmt.Sprintf("ignoring remote config: %v", err),
+			}}
+		}
+		for id, override := range remote {
+			overrides[id] = override
+		}
+		warnings = append(warnings, remoteWarnings...)
+	}
+
+	for id, override := range project.Overrides {
+		overrides[id] = override
+	}
+
+	if opts.rulesFile != "" {
+		local, localWarnings, err := config.LoadRuleOverrides(opts.rulesFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		for id, override := range local {
+			overrides[id] = override
+		}
+		warnings = append(warnings, localWarnings...)
+	}
+
+	return overrides, warnings, nil
+}
+
+// parseFailures turns parse warnings into a single error for --strict-parse
+func parseFailures(warnings []types.Warning) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(warnings))
+	for i, w := range warnings {
+		errs[i] = fmt.Errorf("%s: %s", w.Path, w.Message)
+	}
+	return errors.Join(errs...)
+}
+
 // runScan performs a scan on the given paths
-func runScan(s *scanner.Scanner, paths []string) (types.ScanResult, error) {
-	resources, err := parser.ParseFiles(paths...)
+func runScan(ctx context.Context, s *scanner.Scanner, log *logger.Logger, parseOptions parser.ParseOptions, paths []string) (types.ScanResult, error) {
+	parsed, err := parser.ParseFilesContext(ctx, parseOptions, paths...)
 	if err != nil {
 		return types.ScanResult{}, fmt.Errorf("failed to parse files: %w", err)
 	}
 
-	if len(resources) == 0 {
+	// A commit that touches no manifests is not an error for a git hook
+	if len(parsed.Resources) == 0 && !parseOptions.SkipNonManifests {
 		return types.ScanResult{}, fmt.Errorf("no Kubernetes resources found in specified paths")
 	}
 
-	return s.Scan(resources), nil
+	log.Infof("Parsed %d resources from %d path(s)", len(parsed.Resources), len(paths))
+	log.Progressf("Scanning %d resources…", len(parsed.Resources))
+
+	result, err := s.ScanContext(ctx, parsed.Resources)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	result.Warnings = parsed.Warnings
+	result.Stats.FilesParsed = parsed.FilesParsed
+	return result, nil
 }
 
 // runDiff performs a diff between old and new manifests
-func runDiff(s *scanner.Scanner, oldPath, newPath string) (types.ScanResult, error) {
-	oldResources, err := parser.ParseFiles(oldPath)
+func runDiff(ctx context.Context, s *scanner.Scanner, log *logger.Logger, parseOptions parser.ParseOptions, oldPath, newPath string) (types.ScanResult, error) {
+	oldParsed, err := parser.ParseFilesContext(ctx, parseOptions, oldPath)
 	if err != nil {
 		return types.ScanResult{}, fmt.Errorf("failed to parse old manifest: %w", err)
 	}
 
-	newResources, err := parser.ParseFiles(newPath)
+	newParsed, err := parser.ParseFilesContext(ctx, parseOptions, newPath)
 	if err != nil {
 		return types.ScanResult{}, fmt.Errorf("failed to parse new manifest: %w", err)
 	}
 
-	return s.Diff(oldResources, newResources), nil
+	log.Infof("Parsed %d old and %d new resources", len(oldParsed.Resources), len(newParsed.Resources))
+	log.Progressf("Scanning %d resources…", len(oldParsed.Resources)+len(newParsed.Resources))
+
+	result, err := s.DiffContext(ctx, oldParsed.Resources, newParsed.Resources)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	result.Warnings = append(oldParsed.Warnings, newParsed.Warnings...)
+	result.Stats.FilesParsed = oldParsed.FilesParsed + newParsed.FilesParsed
+	return result, nil
+}
+
+// runCluster scans resources read from the cluster API server
+func runCluster(ctx context.Context, s *scanner.Scanner, log *logger.Logger, opts cluster.Options) (types.ScanResult, error) {
+	log.Progressf("Reading resources from the cluster…")
+	resources, warnings, err := cluster.Fetch(ctx, opts)
+	if err != nil {
+		return types.ScanResult{}, fmt.Errorf("failed to read cluster resources: %w", err)
+	}
+
+	log.Infof("Read %d resources from the cluster", len(resources))
+	log.Progressf("Scanning %d resources…", len(resources))
+
+	result, err := s.ScanContext(ctx, resources)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	result.Warnings = warnings
+	return result, nil
+}
+
+// runGitDiff diffs the manifests changed between two git revisions, or
+// between ref and the working tree when to is empty. Non-empty paths limit
+// the diff to changes beneath them.
+func runGitDiff(ctx context.Context, s *scanner.Scanner, log *logger.Logger, parseOptions parser.ParseOptions, ref, to string, paths []string) (types.ScanResult, error) {
+	var oldFiles, newFiles []gitutil.File
+	var err error
+	if to == "" {
+		oldFiles, newFiles, err = gitutil.ChangedManifests(ctx, ref, paths...)
+	} else {
+		oldFiles, newFiles, err = gitutil.ChangedManifestsBetween(ctx, ref, to, paths...)
+	}
+	if err != nil && to != "" {
+		return types.ScanResult{}, fmt.Errorf("failed to list changes between %s and %s: %w", ref, to, err)
+	}
+	if err != nil {
+		return types.ScanResult{}, fmt.Errorf("failed to list changes since %s: %w", ref, err)
+	}
+
+	oldParsed := parseGitFiles(ref, oldFiles, parseOptions.Limits)
+	newParsed := parseGitFiles(to, newFiles, parseOptions.Limits)
+
+	log.Infof("%d manifest(s) changed since %s", len(newFiles), ref)
+
+	result, err := s.DiffContext(ctx, oldParsed.Resources, newParsed.Resources)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	result.Warnings = append(oldParsed.Warnings, newParsed.Warnings...)
+	result.Stats.FilesParsed = oldParsed.FilesParsed + newParsed.FilesParsed
+	return result, nil
+}
+
+// parseGitFiles parses file contents obtained from git. Old revisions are
+// labelled "<ref>:<path>" so they are distinguishable from working tree files.
+func parseGitFiles(ref string, files []gitutil.File, limits parser.Limits) parser.ParseResult {
+	var result parser.ParseResult
+
+	for _, file := range files {
+		source := file.Path
+		if ref != "" {
+			source = ref + ":" + file.Path
+		}
+
+		res, err := parser.ParseSourceWithLimits(source, file.Data, limits)
+		if err != nil {
+			result.Warnings = append(result.Warnings, types.Warning{
+				Path:    source,
+				Message: fmt.Sprintf("failed to parse: %v", err),
+			})
+			continue
+		}
+		result.Resources = append(result.Resources, res...)
+		result.FilesParsed++
+	}
+
+	return result
 }
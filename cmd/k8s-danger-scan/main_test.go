@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+)
+
+// writeFile creates a file under dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStrictParse(t *testing.T) {
+	dir := t.TempDir()
+	good := writeFile(t, dir, "good.yaml", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: good\n")
+	bad := writeFile(t, dir, "bad.yaml", "kind: Pod\nmetadata: [unterminated\n")
+
+	result, err := parser.ParseFiles(good)
+	if err != nil {
+		t.Fatalf("ParseFiles failed: %v", err)
+	}
+	if err := parseFailures(result.Warnings); err != nil {
+		t.Errorf("clean parse failed in strict mode: %v", err)
+	}
+
+	result, err = parser.ParseFiles(good, bad)
+	if err != nil {
+		t.Fatalf("ParseFiles failed: %v", err)
+	}
+	if len(result.Resources) != 1 {
+		t.Errorf("got %d resources, want the good file's one", len(result.Resources))
+	}
+	err = parseFailures(result.Warnings)
+	if err == nil {
+		t.Fatal("strict mode accepted a file that failed to parse")
+	}
+	if !strings.Contains(err.Error(), bad) {
+		t.Errorf("error %q doesn't name %s", err, bad)
+	}
+}
+
+// parseScanFlags registers the scan flags on a fresh flag set and parses args
+func parseScanFlags(t *testing.T, args ...string) (*flag.FlagSet, *cliOptions) {
+	t.Helper()
+	var opts cliOptions
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	opts.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("failed to parse %v: %v", args, err)
+	}
+	return fs, &opts
+}
+
+func TestFlagBeatsEnv(t *testing.T) {
+	t.Setenv("DANGER_SCAN_MIN_SEVERITY", "low")
+	t.Setenv("DANGER_SCAN_PSS_LEVEL", "baseline")
+
+	fs, opts := parseScanFlags(t, "--min-severity", "critical", "--pss", "restricted")
+	if err := applyEnv(fs); err != nil {
+		t.Fatalf("applyEnv failed: %v", err)
+	}
+	if opts.minSeverity != "critical" {
+		t.Errorf("min-severity is %q, want the flag's critical", opts.minSeverity)
+	}
+	if opts.pssLevel != "restricted" {
+		t.Errorf("pss-level is %q, want restricted from its --pss shorthand", opts.pssLevel)
+	}
+}
+
+func TestEnvBeatsConfigAndDefaults(t *testing.T) {
+	t.Setenv("DANGER_SCAN_MIN_SEVERITY", "low")
+	config := writeFile(t, t.TempDir(), ".danger-scan.yaml", `options:
+  min-severity: medium
+  categories: [security]
+`)
+
+	fs, opts := parseScanFlags(t)
+	if err := applyEnv(fs); err != nil {
+		t.Fatalf("applyEnv failed: %v", err)
+	}
+	if _, _, err := loadProject(fs, config, nil); err != nil {
+		t.Fatalf("loadProject failed: %v", err)
+	}
+
+	if opts.minSeverity != "low" {
+		t.Errorf("min-severity is %q, want the environment's low", opts.minSeverity)
+	}
+	if opts.categories != "security" {
+		t.Errorf("categories is %q, want the config file's security", opts.categories)
+	}
+	if opts.failOn != "" {
+		t.Errorf("fail-on is %q, want the empty default", opts.failOn)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := envName("min-severity"); got != "DANGER_SCAN_MIN_SEVERITY" {
+		t.Errorf("envName(min-severity) = %q", got)
+	}
+}
+
+func TestInvalidEnvValue(t *testing.T) {
+	t.Setenv("DANGER_SCAN_CONCURRENCY", "many")
+
+	fs, _ := parseScanFlags(t)
+	err := applyEnv(fs)
+	if err == nil {
+		t.Fatal("applyEnv accepted DANGER_SCAN_CONCURRENCY=many")
+	}
+	if !strings.Contains(err.Error(), "DANGER_SCAN_CONCURRENCY") {
+		t.Errorf("error %q doesn't name the variable", err)
+	}
+}
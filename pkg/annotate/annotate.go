@@ -0,0 +1,304 @@
+// Package annotate writes danger-scan/ignore annotations into manifest files,
+// editing only the documents it changes so the rest of each file keeps its
+// exact formatting.
+package annotate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Target identifies a resource in a file and the rules to ignore on it
+type Target struct {
+	Kind      string
+	Name      string
+	Namespace string
+	RuleIDs   []string
+}
+
+// documentSeparator matches a YAML document start marker on its own line
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*(#.*)?$`)
+
+// File adds the targets' rule IDs to the ignore annotation of the matching
+// resources in the file at path, merging with any IDs already listed. It
+// returns the number of resources changed; the file is only rewritten if
+// that is non-zero, and not at all when dryRun is set.
+func File(path string, targets []Target, dryRun bool) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	out, changed, err := Source(data, targets)
+	if err != nil {
+		return 0, fmt.Errorf("failed to annotate %s: %w", path, err)
+	}
+	if changed == 0 || dryRun {
+		return changed, nil
+	}
+
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return changed, nil
+}
+
+// Source is File for YAML data held in memory. Documents that don't match a
+// target are copied through byte for byte. In block-style metadata the
+// annotation lines are inserted or rewritten in place; anything else (flow
+// style, no metadata) falls back to re-encoding that one document.
+func Source(data []byte, targets []Target) ([]byte, int, error) {
+	var out bytes.Buffer
+	changed := 0
+
+	for _, chunk := range SplitDocuments(data) {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(chunk.Body, &doc); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+
+		out.Write(chunk.Separator)
+		body, ok, err := annotateDocument(chunk.Body, &doc, targets)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			changed++
+		}
+		out.Write(body)
+	}
+
+	return out.Bytes(), changed, nil
+}
+
+// Document is one YAML document with the separator line that preceded it
+type Document struct {
+	Separator []byte
+	Body      []byte
+}
+
+// SplitDocuments splits data at document separators, keeping every byte so
+// the documents concatenate back to the input
+func SplitDocuments(data []byte) []Document {
+	var chunks []Document
+	var separator []byte
+	rest := data
+	for {
+		loc := documentSeparator.FindIndex(rest)
+		if loc == nil {
+			return append(chunks, Document{Separator: separator, Body: rest})
+		}
+
+		end := loc[1]
+		if end < len(rest) && rest[end] == '\n' {
+			end++
+		}
+		chunks = append(chunks, Document{Separator: separator, Body: rest[:loc[0]]})
+		separator = rest[loc[0]:end]
+		rest = rest[end:]
+	}
+}
+
+// annotateDocument adds the ignore annotation for the first target matching
+// the document, returning the document text and whether it changed
+func annotateDocument(body []byte, doc *yaml.Node, targets []Target) ([]byte, bool, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return body, false, nil
+	}
+	root := doc.Content[0]
+
+	kind := scalar(root, "kind")
+	metadata := mappingValue(root, "metadata")
+	name := scalar(metadata, "name")
+	namespace := scalar(metadata, "namespace")
+
+	for _, target := range targets {
+		if target.Kind != kind || target.Name != name || target.Namespace != namespace {
+			continue
+		}
+
+		annotations := mappingValue(metadata, "annotations")
+		current := mappingValue(annotations, types.IgnoreAnnotation)
+		existing := ""
+		if current != nil {
+			existing = current.Value
+		}
+		value, added := mergeIgnore(existing, target.RuleIDs)
+		if !added {
+			return body, false, nil
+		}
+
+		if edited, ok := insertText(body, metadata, annotations, current, value); ok {
+			return edited, true, nil
+		}
+		encoded, err := reencode(doc, root, metadata, annotations, current, value)
+		return encoded, err == nil, err
+	}
+	return body, false, nil
+}
+
+// insertText edits the annotation as text, so the rest of the document keeps
+// its exact layout. It only handles block-style mappings and reports false
+// for anything else.
+func insertText(body []byte, metadata, annotations, current *yaml.Node, value string) ([]byte, bool) {
+	lines := strings.SplitAfter(string(body), "\n")
+
+	switch {
+	case current != nil:
+		// Rewrite the existing key's line, keeping its indentation
+		key := annotations.Content[indexOf(annotations, current)-1]
+		if !blockEntry(lines, key) || current.Kind != yaml.ScalarNode || current.Line != key.Line {
+			return nil, false
+		}
+		line := lines[key.Line-1]
+		ending := line[len(strings.TrimRight(line, "\r\n")):]
+		comment := ""
+		if current.LineComment != "" {
+			comment = " " + current.LineComment
+		}
+		lines[key.Line-1] = line[:key.Column-1] + types.IgnoreAnnotation + ": " + value + comment + ending
+
+	case annotations != nil:
+		// Insert the key above the first existing annotation
+		if len(annotations.Content) == 0 || !blockEntry(lines, annotations.Content[0]) {
+			return nil, false
+		}
+		first := annotations.Content[0]
+		entry := strings.Repeat(" ", first.Column-1) + types.IgnoreAnnotation + ": " + value + "\n"
+		lines = insertLine(lines, first.Line-1, entry)
+
+	case metadata != nil:
+		// Insert an annotations block above the first metadata field
+		if len(metadata.Content) == 0 || !blockEntry(lines, metadata.Content[0]) {
+			return nil, false
+		}
+		first := metadata.Content[0]
+		indent := strings.Repeat(" ", first.Column-1)
+		entry := indent + "annotations:\n" + indent + "  " + types.IgnoreAnnotation + ": " + value + "\n"
+		lines = insertLine(lines, first.Line-1, entry)
+
+	default:
+		return nil, false
+	}
+
+	return []byte(strings.Join(lines, "")), true
+}
+
+// blockEntry reports whether key starts its own line, as it does in a block
+// mapping, rather than following a flow-style brace
+func blockEntry(lines []string, key *yaml.Node) bool {
+	if key.Line < 1 || key.Line > len(lines) || key.Style&yaml.FlowStyle != 0 {
+		return false
+	}
+	prefix := lines[key.Line-1]
+	if key.Column-1 > len(prefix) {
+		return false
+	}
+	return strings.TrimLeft(prefix[:key.Column-1], " ") == ""
+}
+
+// insertLine inserts text before lines[i]
+func insertLine(lines []string, i int, text string) []string {
+	lines = append(lines, "")
+	copy(lines[i+1:], lines[i:])
+	lines[i] = text
+	return lines
+}
+
+// indexOf returns the position of node within mapping's content, or -1
+func indexOf(mapping, node *yaml.Node) int {
+	for i, n := range mapping.Content {
+		if n == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// reencode sets the annotation on the node tree and encodes the whole
+// document. Comments survive but indentation is normalized.
+func reencode(doc, root, metadata, annotations, current *yaml.Node, value string) ([]byte, error) {
+	if metadata == nil {
+		metadata = addMapping(root, "metadata")
+	}
+	if annotations == nil {
+		annotations = addMapping(metadata, "annotations")
+	}
+	if current == nil {
+		current = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+		annotations.Content = append(annotations.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: types.IgnoreAnnotation}, current)
+	}
+	current.Value = value
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	encoder.Close()
+	return buf.Bytes(), nil
+}
+
+// mergeIgnore adds rule IDs to a comma-separated ignore list, reporting
+// whether any were missing
+func mergeIgnore(existing string, ruleIDs []string) (string, bool) {
+	var ids []string
+	present := make(map[string]bool)
+	for _, id := range strings.Split(existing, ",") {
+		if id = strings.TrimSpace(id); id != "" && !present[id] {
+			ids = append(ids, id)
+			present[id] = true
+		}
+	}
+
+	added := false
+	for _, id := range ruleIDs {
+		if !present[id] {
+			ids = append(ids, id)
+			present[id] = true
+			added = true
+		}
+	}
+	return strings.Join(ids, ","), added
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalar returns the string value for key in a mapping node, or ""
+func scalar(mapping *yaml.Node, key string) string {
+	if value := mappingValue(mapping, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
+
+// addMapping appends an empty mapping under key and returns it
+func addMapping(mapping *yaml.Node, key string) *yaml.Node {
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
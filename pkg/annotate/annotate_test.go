@@ -0,0 +1,189 @@
+package annotate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		targets []Target
+		want    string
+	}{
+		{
+			name: "no annotations yet",
+			in: `# web deployment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # public
+  namespace: shop
+spec:
+  replicas: 2
+`,
+			targets: []Target{{Kind: "Deployment", Name: "web", Namespace: "shop", RuleIDs: []string{"latest-image-tag"}}},
+			want: `# web deployment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    danger-scan/ignore: latest-image-tag
+  name: web # public
+  namespace: shop
+spec:
+  replicas: 2
+`,
+		},
+		{
+			name: "other annotations",
+			in: `kind: Deployment
+metadata:
+  name: web
+  annotations:
+    # owned by the web team
+    team: web
+`,
+			targets: []Target{{Kind: "Deployment", Name: "web", RuleIDs: []string{"host-port"}}},
+			want: `kind: Deployment
+metadata:
+  name: web
+  annotations:
+    # owned by the web team
+    danger-scan/ignore: host-port
+    team: web
+`,
+		},
+		{
+			name: "merged into an existing ignore list",
+			in: `kind: Pod
+metadata:
+  name: debug
+  annotations:
+    danger-scan/ignore: host-pid-ipc # approved in SEC-12
+`,
+			targets: []Target{{Kind: "Pod", Name: "debug", RuleIDs: []string{"host-pid-ipc", "privileged-container"}}},
+			want: `kind: Pod
+metadata:
+  name: debug
+  annotations:
+    danger-scan/ignore: host-pid-ipc,privileged-container # approved in SEC-12
+`,
+		},
+		{
+			name: "flow-style metadata",
+			in: `# debug pod
+kind: Pod
+metadata: {name: debug}
+spec:
+  hostPID: true # for strace
+`,
+			targets: []Target{{Kind: "Pod", Name: "debug", RuleIDs: []string{"host-pid-ipc"}}},
+			want: `# debug pod
+kind: Pod
+metadata: {name: debug, annotations: {danger-scan/ignore: host-pid-ipc}}
+spec:
+  hostPID: true # for strace
+`,
+		},
+		{
+			name: "only the matching document",
+			in: `kind: Service
+metadata:
+  name: web
+---
+kind: Deployment
+metadata:
+  name: web
+--- # staging
+kind: Deployment
+metadata:
+  name: web
+  namespace: staging
+`,
+			targets: []Target{{Kind: "Deployment", Name: "web", RuleIDs: []string{"no-resource-limits"}}},
+			want: `kind: Service
+metadata:
+  name: web
+---
+kind: Deployment
+metadata:
+  annotations:
+    danger-scan/ignore: no-resource-limits
+  name: web
+--- # staging
+kind: Deployment
+metadata:
+  name: web
+  namespace: staging
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, changed, err := Source([]byte(tt.in), tt.targets)
+			if err != nil {
+				t.Fatalf("Source failed: %v", err)
+			}
+			if changed != 1 {
+				t.Errorf("changed %d resources, want 1", changed)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.want)
+			}
+
+			again, changed, err := Source(out, tt.targets)
+			if err != nil {
+				t.Fatalf("second Source failed: %v", err)
+			}
+			if changed != 0 || string(again) != string(out) {
+				t.Errorf("second pass changed %d resources:\n%s", changed, again)
+			}
+		})
+	}
+}
+
+func TestFileIsIdempotent(t *testing.T) {
+	manifest := `kind: Pod
+metadata:
+  name: debug # keep me
+spec:
+  hostNetwork: true
+`
+	path := filepath.Join(t.TempDir(), "pod.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	targets := []Target{{Kind: "Pod", Name: "debug", RuleIDs: []string{"host-network"}}}
+
+	if changed, err := File(path, targets, true); err != nil || changed != 1 {
+		t.Fatalf("dry run changed %d resources, err %v; want 1", changed, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != manifest {
+		t.Fatalf("dry run rewrote the file:\n%s", data)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := File(path, targets, false); err != nil {
+			t.Fatalf("File failed: %v", err)
+		}
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "danger-scan/ignore"); n != 1 {
+		t.Errorf("got %d ignore annotations, want 1:\n%s", n, data)
+	}
+	if !strings.Contains(string(data), "name: debug # keep me") {
+		t.Errorf("comment lost:\n%s", data)
+	}
+}
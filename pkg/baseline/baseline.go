@@ -0,0 +1,124 @@
+// Package baseline records the findings a repository already has, so later
+// scans report only findings that are not in the baseline.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// SchemaVersion identifies the shape of baseline files
+const SchemaVersion = "1"
+
+// File is the on-disk shape of a baseline
+type File struct {
+	SchemaVersion string  `json:"schema_version"`
+	Findings      []Entry `json:"findings"`
+}
+
+// Entry is one grandfathered finding. Only the fingerprint is used for
+// matching; the rest makes the file reviewable.
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	RuleID      string `json:"rule_id"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	Container   string `json:"container,omitempty"`
+	File        string `json:"file,omitempty"`
+}
+
+// New snapshots findings as a baseline. Suppressed findings are left out,
+// since their annotation already accepts them. Entries are sorted so
+// regenerating an unchanged baseline gives an identical file.
+func New(findings []types.Finding) File {
+	file := File{SchemaVersion: SchemaVersion, Findings: []Entry{}}
+	for _, f := range findings {
+		if f.Suppressed {
+			continue
+		}
+		file.Findings = append(file.Findings, Entry{
+			Fingerprint: f.Fingerprint,
+			RuleID:      f.RuleID,
+			Kind:        f.Kind,
+			Name:        f.Name,
+			Namespace:   f.Namespace,
+			Container:   f.Container,
+			File:        f.File,
+		})
+	}
+
+	sort.SliceStable(file.Findings, func(i, j int) bool {
+		a, b := file.Findings[i], file.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Container < b.Container
+	})
+	return file
+}
+
+// Load reads a baseline file
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return File{}, fmt.Errorf("failed to decode baseline %s: %w", path, err)
+	}
+	if file.SchemaVersion != SchemaVersion {
+		return File{}, fmt.Errorf("baseline %s has schema version %q, want %q", path, file.SchemaVersion, SchemaVersion)
+	}
+	return file, nil
+}
+
+// Write saves the baseline as indented JSON
+func (f File) Write(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Filter drops the findings recorded in the baseline and returns the rest
+// with the number dropped
+func (f File) Filter(findings []types.Finding) ([]types.Finding, int) {
+	recorded := make(map[string]bool, len(f.Findings))
+	for _, entry := range f.Findings {
+		recorded[entry.Fingerprint] = true
+	}
+
+	var kept []types.Finding
+	dropped := 0
+	for _, finding := range findings {
+		if !finding.Suppressed && finding.Status != types.StatusResolved && recorded[finding.Fingerprint] {
+			dropped++
+			continue
+		}
+		kept = append(kept, finding)
+	}
+	return kept, dropped
+}
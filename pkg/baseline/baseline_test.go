@@ -0,0 +1,59 @@
+package baseline
+
+import (
+	"testing"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/scanner"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// finding returns a fingerprinted hostpath-volume finding on one volume
+func finding(path string) types.Finding {
+	f := types.Finding{
+		RuleID:    "hostpath-volume",
+		Severity:  types.High,
+		Kind:      "Deployment",
+		Name:      "api",
+		Namespace: "shop",
+		Path:      path,
+	}
+	f.Fingerprint = scanner.Fingerprint(f)
+	return f
+}
+
+func TestFilterKeepsNewFindingsOfABaselinedRule(t *testing.T) {
+	logs := finding("spec.template.spec.volumes[0]")
+	etc := finding("spec.template.spec.volumes[1]")
+
+	kept, dropped := New([]types.Finding{logs}).Filter([]types.Finding{logs, etc})
+	if dropped != 1 {
+		t.Errorf("dropped %d findings, want 1", dropped)
+	}
+	if len(kept) != 1 || kept[0].Path != etc.Path {
+		t.Errorf("kept %+v, want the finding on %s", kept, etc.Path)
+	}
+}
+
+func TestFilterKeepsNewWeakSecretKey(t *testing.T) {
+	scan := func(manifest string) []types.Finding {
+		t.Helper()
+		resources, err := parser.ParseYAML([]byte(manifest))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return scanner.NewScanner(types.ScanOptions{MinSeverity: types.Low}).Scan(resources).Findings
+	}
+	secret := `apiVersion: v1
+kind: Secret
+metadata: {name: creds, namespace: shop}
+stringData:
+  password: changeme
+`
+
+	file := New(scan(secret))
+	kept, _ := file.Filter(scan(secret + "  token: admin\n"))
+	if len(kept) != 1 || kept[0].RuleID != "weak-secret-value" || kept[0].Path != "stringData.token" {
+		t.Errorf("kept %+v, want the weak-secret-value finding on stringData.token", kept)
+	}
+}
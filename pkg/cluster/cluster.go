@@ -0,0 +1,168 @@
+// Package cluster reads resources from a running cluster with kubectl, so
+// live workloads can be scanned without exporting YAML first.
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// ErrKubectlNotFound is returned when kubectl is not installed
+var ErrKubectlNotFound = errors.New("kubectl not found in PATH (the cluster command uses it to reach the API server)")
+
+// Options selects which resources are read from the cluster
+type Options struct {
+	Context       string // kubeconfig context; empty for the current one
+	Namespace     string // Namespace to read; empty for the context's default
+	AllNamespaces bool
+	Selector      string // Label selector, as for kubectl get -l
+}
+
+// namespacedTypes are read from the selected namespace(s). Pods, Jobs and
+// ReplicaSets owned by a controller are dropped so each workload is
+// reported once.
+var namespacedTypes = []string{
+	"pods", "deployments", "statefulsets", "daemonsets", "replicasets",
+	"replicationcontrollers", "jobs", "cronjobs",
+	"services", "roles", "rolebindings", "secrets", "configmaps",
+	"networkpolicies",
+}
+
+// clusterTypes are cluster-scoped; they are read whatever the namespace,
+// since a ClusterRoleBinding can grant rights in every namespace
+var clusterTypes = []string{"clusterroles", "clusterrolebindings"}
+
+// Fetch reads workloads, services, secrets and RBAC objects through kubectl
+// using the current kubeconfig. A resource type the caller may not list is
+// skipped with a warning rather than failing the scan. kubectl must be on
+// the PATH; once ctx is done it is killed and ctx's error is returned.
+func Fetch(ctx context.Context, opts Options) ([]parser.K8sResource, []types.Warning, error) {
+	bin, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, nil, ErrKubectlNotFound
+	}
+
+	source := "cluster"
+	if opts.Context != "" {
+		source = "cluster:" + opts.Context
+	}
+
+	var resources []parser.K8sResource
+	var warnings []types.Warning
+	fetch := func(resourceType string, namespaced bool) error {
+		items, err := get(ctx, bin, resourceType, namespaced, opts)
+		if errors.Is(err, errForbidden) {
+			warnings = append(warnings, types.Warning{
+				Path:    source,
+				Message: fmt.Sprintf("skipped %s: %v", resourceType, err),
+			})
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if controlled(item) {
+				continue
+			}
+			resource := parser.FromUnstructured(item)
+			resource.Source = source
+			resources = append(resources, resource)
+		}
+		return nil
+	}
+
+	for _, resourceType := range namespacedTypes {
+		if err := fetch(resourceType, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, resourceType := range clusterTypes {
+		if err := fetch(resourceType, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	return resources, warnings, nil
+}
+
+// errForbidden marks kubectl failures caused by missing list permission
+var errForbidden = errors.New("not permitted")
+
+// get runs kubectl get for one resource type and returns its items
+func get(ctx context.Context, bin, resourceType string, namespaced bool, opts Options) ([]map[string]interface{}, error) {
+	args := []string{"get", resourceType, "-o", "json"}
+	if opts.Context != "" {
+		args = append(args, "--context", opts.Context)
+	}
+	if namespaced {
+		if opts.AllNamespaces {
+			args = append(args, "--all-namespaces")
+		} else if opts.Namespace != "" {
+			args = append(args, "--namespace", opts.Namespace)
+		}
+	}
+	if opts.Selector != "" {
+		args = append(args, "--selector", opts.Selector)
+	}
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		if strings.Contains(msg, "Forbidden") || strings.Contains(msg, "forbidden") {
+			return nil, fmt.Errorf("%w: %s", errForbidden, msg)
+		}
+		return nil, fmt.Errorf("kubectl get %s failed: %s", resourceType, msg)
+	}
+
+	var list struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	decoder := json.NewDecoder(&stdout)
+	decoder.UseNumber()
+	if err := decoder.Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode kubectl get %s output: %w", resourceType, err)
+	}
+	return list.Items, nil
+}
+
+// controlled reports whether an object is a Pod, Job or ReplicaSet created
+// by a controller, whose template is already scanned through the owning
+// workload
+func controlled(item map[string]interface{}) bool {
+	switch kind, _ := item["kind"].(string); kind {
+	case "Pod", "Job", "ReplicaSet":
+	default:
+		return false
+	}
+	metadata, _ := item["metadata"].(map[string]interface{})
+	owners, _ := metadata["ownerReferences"].([]interface{})
+	for _, o := range owners {
+		owner, _ := o.(map[string]interface{})
+		// Static pods are mirrored with their Node as controller, and
+		// appear nowhere else
+		if kind, _ := owner["kind"].(string); kind == "Node" {
+			continue
+		}
+		if controller, _ := owner["controller"].(bool); controller {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeKubectl puts a kubectl shell script on the PATH
+func fakeKubectl(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestFetch(t *testing.T) {
+	fakeKubectl(t, `case "$2" in
+deployments) echo '{"items": [{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "api", "namespace": "shop"}}]}' ;;
+pods) echo '{"items": [{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "api-1", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "controller": true}]}}]}' ;;
+secrets) echo 'Error from server (Forbidden): secrets is forbidden' >&2; exit 1 ;;
+*) echo '{"items": []}' ;;
+esac
+`)
+
+	resources, warnings, err := Fetch(context.Background(), Options{Context: "prod"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(resources) != 1 || resources[0].Kind != "Deployment" || resources[0].Source != "cluster:prod" {
+		t.Errorf("got resources %+v, want Deployment api from cluster:prod", resources)
+	}
+	if len(warnings) != 1 || warnings[0].Path != "cluster:prod" {
+		t.Errorf("got warnings %+v, want one for the forbidden secrets", warnings)
+	}
+}
+
+func TestFetchCanceled(t *testing.T) {
+	fakeKubectl(t, "exec sleep 10\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := Fetch(ctx, Options{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Fetch took %s to stop", elapsed)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// RulesFile is the on-disk shape of a rules.yaml overrides file
+type RulesFile struct {
+	Rules map[string]types.RuleOverride `yaml:"rules"`
+}
+
+// LoadRuleOverrides reads per-rule overrides from a rules.yaml file.
+// Unknown rule IDs are reported as warnings and ignored.
+func LoadRuleOverrides(path string) (map[string]types.RuleOverride, []types.Warning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	return ParseRuleOverrides(path, data)
+}
+
+// ParseRuleOverrides parses rules.yaml content. The path is only used to
+// label warnings.
+func ParseRuleOverrides(path string, data []byte) (map[string]types.RuleOverride, []types.Warning, error) {
+	var file RulesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode rules file: %w", err)
+	}
+
+	return validateOverrides(path, file.Rules)
+}
+
+// validateOverrides normalizes override severities and drops unknown rule
+// IDs with a warning
+func validateOverrides(path string, entries map[string]types.RuleOverride) (map[string]types.RuleOverride, []types.Warning, error) {
+	known := knownRuleIDs()
+	overrides := make(map[string]types.RuleOverride)
+	var warnings []types.Warning
+
+	// Visit IDs in a stable order so warnings are deterministic
+	ids := make([]string, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		override := entries[id]
+		if !known[id] {
+			warnings = append(warnings, types.Warning{
+				Path:    path,
+				Message: fmt.Sprintf("unknown rule ID %q in rules file", id),
+			})
+			continue
+		}
+
+		if override.Severity != "" {
+			severity, err := ParseSeverity(string(override.Severity))
+			if err != nil {
+				return nil, nil, fmt.Errorf("rule %s: %w", id, err)
+			}
+			override.Severity = severity
+		}
+
+		if override.Threshold < 0 {
+			return nil, nil, fmt.Errorf("rule %s: threshold must not be negative", id)
+		}
+
+		overrides[id] = override
+	}
+
+	return overrides, warnings, nil
+}
+
+// knownRuleIDs returns the set of built-in rule IDs
+func knownRuleIDs() map[string]bool {
+	known := make(map[string]bool)
+	for _, id := range rules.RuleIDs() {
+		known[id] = true
+	}
+	return known
+}
+
+// ParseSeverity converts a case-insensitive severity name to a Severity
+func ParseSeverity(value string) (types.Severity, error) {
+	switch types.Severity(strings.ToUpper(value)) {
+	case types.Critical:
+		return types.Critical, nil
+	case types.High:
+		return types.High, nil
+	case types.Medium:
+		return types.Medium, nil
+	case types.Low:
+		return types.Low, nil
+	default:
+		return "", fmt.Errorf("invalid severity %q", value)
+	}
+}
+
+// ParsePSSLevel converts a case-insensitive Pod Security Standards level name
+func ParsePSSLevel(value string) (types.PSSLevel, error) {
+	switch level := types.PSSLevel(strings.ToLower(value)); level {
+	case types.PSSBaseline, types.PSSRestricted:
+		return level, nil
+	default:
+		return "", fmt.Errorf("unknown Pod Security Standards level %q (want baseline or restricted)", value)
+	}
+}
+
+// ParseCompliance converts a case-insensitive compliance framework name
+func ParseCompliance(value string) (types.ComplianceFramework, error) {
+	switch framework := types.ComplianceFramework(strings.ToLower(value)); framework {
+	case types.ComplianceCIS:
+		return framework, nil
+	default:
+		return "", fmt.Errorf("unknown compliance framework %q (want cis)", value)
+	}
+}
+
+// ParseCategories converts a comma-separated list of category names
+func ParseCategories(value string) ([]types.Category, error) {
+	known := make(map[types.Category]bool)
+	for _, category := range rules.Categories() {
+		known[category] = true
+	}
+
+	var categories []types.Category
+	for _, name := range strings.Split(value, ",") {
+		category := types.Category(strings.ToLower(strings.TrimSpace(name)))
+		if category == "" {
+			continue
+		}
+		if !known[category] {
+			return nil, fmt.Errorf("unknown category %q", name)
+		}
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
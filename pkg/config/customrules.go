@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// CustomRulesFile is the on-disk shape of a file in --rules-dir
+type CustomRulesFile struct {
+	Rules []rules.CustomRule `yaml:"rules"`
+}
+
+// defaultCustomCategory is the category of custom rules that don't name one
+const defaultCustomCategory = types.CategoryGovernance
+
+// LoadCustomRules reads every .yaml and .yml file in dir, in name order,
+// and returns the custom rules they define. Rules are checked up front, so
+// a bad expression or an ID that clashes with another rule fails the load.
+func LoadCustomRules(dir string) ([]rules.CustomRule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	seen := knownRuleIDs()
+	var custom []rules.CustomRule
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rules file: %w", err)
+		}
+		parsed, err := ParseCustomRules(path, data)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range parsed {
+			if seen[rule.ID] {
+				return nil, fmt.Errorf("%s: rule ID %q is already defined", path, rule.ID)
+			}
+			seen[rule.ID] = true
+		}
+		custom = append(custom, parsed...)
+	}
+	return custom, nil
+}
+
+// ParseCustomRules parses and checks the rules in one custom rules file.
+// The path is only used to label errors.
+func ParseCustomRules(path string, data []byte) ([]rules.CustomRule, error) {
+	var file CustomRulesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode rules file %s: %w", path, err)
+	}
+
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+
+		severity, err := ParseSeverity(string(rule.Severity))
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %s: %w", path, rule.ID, err)
+		}
+		rule.Severity = severity
+
+		category := strings.TrimSpace(string(rule.Category))
+		rule.Category = defaultCustomCategory
+		if category != "" {
+			categories, err := ParseCategories(category)
+			if err != nil || len(categories) != 1 {
+				return nil, fmt.Errorf("%s: rule %s: invalid category %q", path, rule.ID, category)
+			}
+			rule.Category = categories[0]
+		}
+
+		if _, err := rule.Compile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return file.Rules, nil
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the config file looked up in the scan root
+const ProjectFileName = ".danger-scan.yaml"
+
+// ProjectFile is the on-disk shape of a .danger-scan.yaml file
+type ProjectFile struct {
+	// Disable lists rule IDs whose findings are never reported
+	Disable []string `yaml:"disable"`
+	// Rules overrides rule severity, text and parameters as in rules.yaml
+	Rules map[string]types.RuleOverride `yaml:"rules"`
+	// Options sets defaults for command-line flags, keyed by flag name
+	Options map[string]interface{} `yaml:"options"`
+}
+
+// Project is a loaded .danger-scan.yaml
+type Project struct {
+	Path      string
+	Disabled  []string
+	Overrides map[string]types.RuleOverride
+	Options   map[string]string // Flag values, lists joined with commas
+}
+
+// FindProjectFile returns the .danger-scan.yaml in the scan root: the first
+// path if it is a directory, the directory holding it if it is a file, or
+// the working directory when there are no paths. It returns "" if there is
+// no config file there.
+func FindProjectFile(paths []string) string {
+	root := "."
+	if len(paths) > 0 {
+		root = paths[0]
+		if info, err := os.Stat(root); err == nil && !info.IsDir() {
+			root = filepath.Dir(root)
+		}
+	}
+
+	path := filepath.Join(root, ProjectFileName)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// LoadProject reads a .danger-scan.yaml. Unknown rule IDs are reported as
+// warnings and ignored.
+func LoadProject(path string) (Project, []types.Warning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Project{}, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return ParseProject(path, data)
+}
+
+// ParseProject parses .danger-scan.yaml content. The path is only used to
+// label warnings.
+func ParseProject(path string, data []byte) (Project, []types.Warning, error) {
+	// Reject unknown keys so a typo doesn't silently disable nothing
+	var file ProjectFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return Project{}, nil, fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+
+	overrides, warnings, err := validateOverrides(path, file.Rules)
+	if err != nil {
+		return Project{}, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	project := Project{Path: path, Overrides: overrides, Options: make(map[string]string)}
+
+	known := knownRuleIDs()
+	for _, id := range file.Disable {
+		if !known[id] {
+			warnings = append(warnings, types.Warning{
+				Path:    path,
+				Message: fmt.Sprintf("unknown rule ID %q in disable list", id),
+			})
+			continue
+		}
+		project.Disabled = append(project.Disabled, id)
+	}
+
+	names := make([]string, 0, len(file.Options))
+	for name := range file.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, err := optionValue(file.Options[name])
+		if err != nil {
+			return Project{}, nil, fmt.Errorf("%s: option %s: %w", path, name, err)
+		}
+		project.Options[name] = value
+	}
+
+	return project, warnings, nil
+}
+
+// optionValue renders a YAML option as a flag value. Lists become
+// comma-separated, the form repeatable flags accept.
+func optionValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, int, float64:
+		return fmt.Sprint(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := optionValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case nil:
+		return "", errors.New("missing value")
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
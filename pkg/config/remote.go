@@ -0,0 +1,95 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// maxRemoteConfigSize caps how much of a remote config is read
+const maxRemoteConfigSize = 1 << 20
+
+// FetchRuleOverrides downloads a rules.yaml from url and parses it with the
+// same schema as LoadRuleOverrides. A copy of each valid download is kept in
+// cacheDir; if the fetch fails and a cached copy exists it is used instead,
+// with a warning. An empty cacheDir disables caching.
+func FetchRuleOverrides(url, cacheDir string, timeout time.Duration) (map[string]types.RuleOverride, []types.Warning, error) {
+	cachePath := ""
+	if cacheDir != "" {
+		sum := sha256.Sum256([]byte(url))
+		cachePath = filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".yaml")
+	}
+
+	data, fetchErr := fetch(url, timeout)
+	if fetchErr == nil {
+		overrides, warnings, err := ParseRuleOverrides(url, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid config from %s: %w", url, err)
+		}
+
+		if cachePath != "" {
+			// A failed cache write only costs us the offline fallback
+			if err := os.MkdirAll(cacheDir, 0o755); err == nil {
+				os.WriteFile(cachePath, data, 0o644)
+			}
+		}
+		return overrides, warnings, nil
+	}
+
+	if cachePath == "" {
+		return nil, nil, fetchErr
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, nil, fetchErr
+	}
+
+	overrides, warnings, err := ParseRuleOverrides(url, data)
+	if err != nil {
+		return nil, nil, fetchErr
+	}
+
+	warnings = append([]types.Warning{{
+		Path:    url,
+		Message: fmt.Sprintf("using cached config: %v", fetchErr),
+	}}, warnings...)
+	return overrides, warnings, nil
+}
+
+// DefaultCacheDir returns the directory used to cache remote configs
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "k8s-danger-scan")
+}
+
+// fetch performs a GET request with a timeout and returns the body
+func fetch(url string, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch config from %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteConfigSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config from %s: %w", url, err)
+	}
+	return data, nil
+}
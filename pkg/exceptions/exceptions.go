@@ -0,0 +1,169 @@
+// Package exceptions applies a reviewed list of accepted findings. Unlike
+// annotations and baselines, every exception names who approved it, why,
+// and until when, and applied exceptions are reported for audit.
+package exceptions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// DateLayout is the format of exception expiry dates
+const DateLayout = "2006-01-02"
+
+// File is a loaded exceptions.yaml
+type File struct {
+	Path       string
+	Exceptions []Exception
+}
+
+// Exception accepts one rule's findings on one resource until it expires
+type Exception struct {
+	Rule          string `yaml:"rule"`
+	Kind          string `yaml:"kind"` // Empty matches any kind
+	Name          string `yaml:"name"`
+	Namespace     string `yaml:"namespace"` // Empty matches any namespace
+	Container     string `yaml:"container"` // Empty matches every container
+	Justification string `yaml:"justification"`
+	Approver      string `yaml:"approver"`
+	Expires       string `yaml:"expires"` // YYYY-MM-DD, inclusive
+
+	expires time.Time
+}
+
+// Load reads and validates an exceptions file. Every exception needs a
+// rule, a resource name, a justification, an approver and an expiry date.
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read exceptions: %w", err)
+	}
+
+	var doc struct {
+		Exceptions []Exception `yaml:"exceptions"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return File{}, fmt.Errorf("failed to decode exceptions %s: %w", path, err)
+	}
+
+	for i := range doc.Exceptions {
+		e := &doc.Exceptions[i]
+		var missing []string
+		for _, field := range []struct{ name, value string }{
+			{"rule", e.Rule}, {"name", e.Name}, {"justification", e.Justification},
+			{"approver", e.Approver}, {"expires", e.Expires},
+		} {
+			if field.value == "" {
+				missing = append(missing, field.name)
+			}
+		}
+		if len(missing) > 0 {
+			return File{}, fmt.Errorf("%s: exception %d is missing %v", path, i+1, missing)
+		}
+
+		e.expires, err = time.Parse(DateLayout, e.Expires)
+		if err != nil {
+			return File{}, fmt.Errorf("%s: exception %d: expires %q is not a YYYY-MM-DD date", path, i+1, e.Expires)
+		}
+	}
+	return File{Path: path, Exceptions: doc.Exceptions}, nil
+}
+
+// expired reports whether the exception no longer applies at now. The
+// expiry date itself is still covered.
+func (e Exception) expired(now time.Time) bool {
+	return !now.Before(e.expires.AddDate(0, 0, 1))
+}
+
+// matches reports whether the exception covers a finding
+func (e Exception) matches(f types.Finding) bool {
+	return e.Rule == f.RuleID && e.Name == f.Name &&
+		(e.Kind == "" || e.Kind == f.Kind) &&
+		(e.Namespace == "" || e.Namespace == f.Namespace) &&
+		(e.Container == "" || e.Container == f.Container)
+}
+
+// resource names the resource an exception covers, e.g. "DaemonSet/monitoring/node-exporter"
+func (e Exception) resource() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + name
+	}
+	if e.Kind != "" {
+		name = e.Kind + "/" + name
+	}
+	return name
+}
+
+// Apply drops the findings covered by an unexpired exception, returning the
+// rest along with a record of each exception applied. Findings covered only
+// by an expired exception are kept with a note on their reason, and each
+// expired exception yields a warning. Suppressed and resolved findings are
+// left alone.
+func (f File) Apply(findings []types.Finding, now time.Time) ([]types.Finding, []types.AppliedException, []types.Warning) {
+	var warnings []types.Warning
+	for _, e := range f.Exceptions {
+		if e.expired(now) {
+			warnings = append(warnings, types.Warning{
+				Path: f.Path,
+				Message: fmt.Sprintf("exception for %s on %s (approved by %s) expired %s; its findings are reported again",
+					e.Rule, e.resource(), e.Approver, e.Expires),
+			})
+		}
+	}
+
+	var kept []types.Finding
+	var applied []types.AppliedException
+	for _, finding := range findings {
+		if finding.Suppressed || finding.Status == types.StatusResolved {
+			kept = append(kept, finding)
+			continue
+		}
+
+		var active, expired *Exception
+		for i := range f.Exceptions {
+			e := &f.Exceptions[i]
+			if !e.matches(finding) {
+				continue
+			}
+			if !e.expired(now) {
+				active = e
+				break
+			}
+			if expired == nil {
+				expired = e
+			}
+		}
+
+		switch {
+		case active != nil:
+			applied = append(applied, types.AppliedException{
+				RuleID:        finding.RuleID,
+				Kind:          finding.Kind,
+				Name:          finding.Name,
+				Namespace:     finding.Namespace,
+				Container:     finding.Container,
+				File:          finding.File,
+				Fingerprint:   finding.Fingerprint,
+				Severity:      finding.Severity,
+				Justification: active.Justification,
+				Approver:      active.Approver,
+				Expires:       active.Expires,
+			})
+			continue
+		case expired != nil:
+			finding.Reason += fmt.Sprintf(" (exception expired %s)", expired.Expires)
+		}
+		kept = append(kept, finding)
+	}
+	return kept, applied, warnings
+}
@@ -0,0 +1,158 @@
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffLine is one line of an edit script: ' ' kept, '-' removed, '+' added
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns a unified diff turning a into b, with a/ and b/
+// prefixed headers for name, or "" when they are equal
+func UnifiedDiff(name string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	script := editScript(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	name = strings.TrimPrefix(filepath.ToSlash(name), "/")
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", name, name)
+
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(script); {
+		// Skip to the next change, keeping up to diffContext lines before it
+		next := start
+		for next < len(script) && script[next].op == ' ' {
+			next++
+		}
+		if next == len(script) {
+			break
+		}
+		from := next - diffContext
+		if from < start {
+			from = start
+		}
+		oldLine += from - start
+		newLine += from - start
+
+		// Extend the hunk until a run of unchanged lines is long enough to
+		// separate it from the next change
+		end := next
+		for end < len(script) {
+			if script[end].op != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(script) && script[run].op == ' ' {
+				run++
+			}
+			if run == len(script) || run-end > 2*diffContext {
+				end += min(run-end, diffContext)
+				break
+			}
+			end = run
+		}
+
+		oldCount, newCount := 0, 0
+		for _, line := range script[from:end] {
+			if line.op != '+' {
+				oldCount++
+			}
+			if line.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, line := range script[from:end] {
+			out.WriteByte(line.op)
+			out.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		oldLine += oldCount
+		newLine += newCount
+		start = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the start,count pair of a hunk header. An empty range
+// starts at the line before it, as diff(1) prints it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits data into lines, keeping each line's newline
+func splitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n') + 1
+		if end == 0 {
+			end = len(data)
+		}
+		lines = append(lines, string(data[:end]))
+		data = data[end:]
+	}
+	return lines
+}
+
+// editScript finds a shortest edit from a to b through their longest
+// common subsequence. Manifests are small enough for the quadratic table.
+func editScript(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var script []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			script = append(script, diffLine{'-', a[i]})
+			i++
+		default:
+			script = append(script, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		script = append(script, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		script = append(script, diffLine{'+', b[j]})
+	}
+	return script
+}
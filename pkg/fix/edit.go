@@ -0,0 +1,182 @@
+package fix
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// editor collects line edits to one document, positioned by the nodes it
+// was decoded into. An edit it cannot make in place, such as one inside a
+// flow mapping, sets fallback instead.
+type editor struct {
+	lines    []string
+	indent   int
+	edits    map[int]*lineEdit // Keyed by 1-based line number
+	fallback bool
+}
+
+// lineEdit is everything that happens to one line
+type lineEdit struct {
+	before  []string
+	after   []string
+	remove  bool
+	column  int // 1-based column of a replaced value, 0 for none
+	old     string
+	new     string
+	comment string
+}
+
+// newEditor starts an editor for a document body
+func newEditor(body []byte, indent int) *editor {
+	return &editor{lines: splitLines(body), indent: indent, edits: make(map[int]*lineEdit)}
+}
+
+// at returns the edit for a line, creating it as needed
+func (e *editor) at(line int) *lineEdit {
+	if e.edits[line] == nil {
+		e.edits[line] = &lineEdit{}
+	}
+	return e.edits[line]
+}
+
+// startsLine reports whether a node is the first thing on its line
+func (e *editor) startsLine(node *yaml.Node) bool {
+	if node.Line < 1 || node.Line > len(e.lines) {
+		return false
+	}
+	line := e.lines[node.Line-1]
+	return node.Column-1 <= len(line) && strings.TrimSpace(line[:node.Column-1]) == ""
+}
+
+// singleLine reports whether a value is a scalar written on its key's line
+func singleLine(key, value *yaml.Node) bool {
+	return value.Kind == yaml.ScalarNode && value.Line == key.Line &&
+		value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0
+}
+
+// block reports whether a mapping is written in block style with at least
+// one key, so new keys can go on lines of their own
+func block(mapping *yaml.Node) bool {
+	return mapping.Kind == yaml.MappingNode && mapping.Style&yaml.FlowStyle == 0 && len(mapping.Content) >= 2
+}
+
+// pair returns the key and value nodes for key in a mapping
+func pair(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// deletePair removes the line holding key and its value
+func (e *editor) deletePair(mapping *yaml.Node, key string) {
+	k, v := pair(mapping, key)
+	if k == nil || !block(mapping) || !singleLine(k, v) || !e.startsLine(k) {
+		e.fallback = true
+		return
+	}
+	e.at(k.Line).remove = true
+}
+
+// setScalar replaces the value of key, or adds the key ahead of the first
+// one in the mapping
+func (e *editor) setScalar(mapping *yaml.Node, key, value string) {
+	k, v := pair(mapping, key)
+	if k == nil {
+		first := mapping.Content
+		if !block(mapping) || !e.startsLine(first[0]) {
+			e.fallback = true
+			return
+		}
+		edit := e.at(first[0].Line)
+		edit.before = append(edit.before, strings.Repeat(" ", first[0].Column-1)+key+": "+value)
+		return
+	}
+
+	line := e.lines[v.Line-1]
+	if !singleLine(k, v) || v.Style != 0 || !strings.HasPrefix(line[v.Column-1:], v.Value) {
+		e.fallback = true
+		return
+	}
+	edit := e.at(v.Line)
+	edit.column, edit.old, edit.new = v.Column, v.Value, value
+}
+
+// addMapping adds key holding a one-line child mapping to a mapping. It
+// goes ahead of the first key, or after the first pair when that shares a
+// line with a list dash.
+func (e *editor) addMapping(mapping *yaml.Node, key, child string) {
+	if !block(mapping) {
+		e.fallback = true
+		return
+	}
+	k, v := mapping.Content[0], mapping.Content[1]
+	lines := []string{
+		strings.Repeat(" ", k.Column-1) + key + ":",
+		strings.Repeat(" ", k.Column-1+e.indent) + child,
+	}
+
+	switch {
+	case e.startsLine(k):
+		edit := e.at(k.Line)
+		edit.before = append(edit.before, lines...)
+	case singleLine(k, v):
+		edit := e.at(k.Line)
+		edit.after = append(edit.after, lines...)
+	default:
+		e.fallback = true
+	}
+}
+
+// comment appends a comment to the line of a scalar value
+func (e *editor) comment(value *yaml.Node, text string) {
+	if value.Kind != yaml.ScalarNode || value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		e.fallback = true
+		return
+	}
+	e.at(value.Line).comment = text
+}
+
+// apply returns the document body with every edit made
+func (e *editor) apply() []byte {
+	var b strings.Builder
+	emit := func(line string) {
+		b.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	for i, line := range e.lines {
+		edit := e.edits[i+1]
+		if edit == nil {
+			b.WriteString(line)
+			continue
+		}
+		for _, added := range edit.before {
+			emit(added)
+		}
+		if !edit.remove {
+			if edit.column > 0 {
+				at := edit.column - 1
+				line = line[:at] + edit.new + line[at+len(edit.old):]
+			}
+			if edit.comment != "" {
+				text := strings.TrimRight(line, " \t\r\n")
+				line = text + "  " + edit.comment + line[len(text):]
+			}
+			if len(edit.after) > 0 {
+				emit(line)
+			} else {
+				b.WriteString(line)
+			}
+		}
+		for _, added := range edit.after {
+			emit(added)
+		}
+	}
+	return []byte(b.String())
+}
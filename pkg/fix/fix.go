@@ -0,0 +1,308 @@
+// Package fix applies safe automatic remediations to manifest files. Edits
+// are made to the text in place, so everything around them keeps its
+// formatting and comments.
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/annotate"
+	"gopkg.in/yaml.v3"
+)
+
+// latestComment is the suggestion attached to images that use :latest
+const latestComment = "# danger-scan: pin to a version tag or digest instead of :latest"
+
+// Change is one remediation applied to a resource
+type Change struct {
+	Kind        string
+	Name        string
+	Container   string // Empty for pod-level changes
+	Description string
+}
+
+// String describes the change for people, e.g.
+// "Deployment/api container app: set allowPrivilegeEscalation: false"
+func (c Change) String() string {
+	target := c.Kind + "/" + c.Name
+	if c.Container != "" {
+		target += " container " + c.Container
+	}
+	return target + ": " + c.Description
+}
+
+// Result is what fixing one file did
+type Result struct {
+	Changes []Change
+	Diff    string // Unified diff of the file, empty when nothing changed
+}
+
+// File fixes the manifest at path. The file is only rewritten when something
+// changed, and never when dryRun is set; the diff is returned either way.
+func File(path string, dryRun bool) (Result, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	out, changes, err := Source(data)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to fix %s: %w", path, err)
+	}
+	if len(changes) == 0 {
+		return Result{}, nil
+	}
+
+	result := Result{Changes: changes, Diff: UnifiedDiff(path, data, out)}
+	if dryRun {
+		return result, nil
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return Result{}, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// Source is File for YAML data held in memory. It returns the fixed data
+// and the changes made.
+func Source(data []byte) ([]byte, []Change, error) {
+	var out bytes.Buffer
+	var changes []Change
+
+	for _, document := range annotate.SplitDocuments(data) {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(document.Body, &doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+
+		out.Write(document.Separator)
+		edits := newEditor(document.Body, indentOf(&doc))
+		docChanges := fixDocument(&doc, edits)
+		switch {
+		case len(docChanges) == 0:
+			out.Write(document.Body)
+		case !edits.fallback:
+			out.Write(edits.apply())
+		default:
+			// Some edit could not be made in place; re-encode the document,
+			// which keeps comments but normalizes its layout
+			var buf bytes.Buffer
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(edits.indent)
+			if err := encoder.Encode(&doc); err != nil {
+				return nil, nil, fmt.Errorf("failed to encode YAML: %w", err)
+			}
+			encoder.Close()
+			out.Write(buf.Bytes())
+		}
+		changes = append(changes, docChanges...)
+	}
+
+	return out.Bytes(), changes, nil
+}
+
+// fixDocument applies every remediation to a document's pod spec, both to
+// the node tree and as text edits
+func fixDocument(doc *yaml.Node, edits *editor) []Change {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	root := doc.Content[0]
+	kind := scalar(root, "kind")
+	name := scalar(mappingValue(root, "metadata"), "name")
+
+	podSpec := podSpecNode(root, kind)
+	if podSpec == nil {
+		return nil
+	}
+
+	var changes []Change
+	record := func(container, description string) {
+		changes = append(changes, Change{Kind: kind, Name: name, Container: container, Description: description})
+	}
+
+	var containers []*yaml.Node
+	for _, list := range []string{"initContainers", "containers"} {
+		if items := mappingValue(podSpec, list); items != nil && items.Kind == yaml.SequenceNode {
+			containers = append(containers, items.Content...)
+		}
+	}
+
+	for _, container := range containers {
+		if container.Kind != yaml.MappingNode {
+			continue
+		}
+		containerName := scalar(container, "name")
+
+		securityContext := mappingValue(container, "securityContext")
+		privileged := scalar(securityContext, "privileged") == "true"
+
+		// New keys are inserted ahead of the first existing one, so edit
+		// before privileged is removed in case it is the only key
+		if escalation := mappingValue(securityContext, "allowPrivilegeEscalation"); escalation == nil || escalation.Value != "false" {
+			if securityContext == nil {
+				edits.addMapping(container, "securityContext", "allowPrivilegeEscalation: false")
+				securityContext = addMapping(container, "securityContext")
+			} else {
+				edits.setScalar(securityContext, "allowPrivilegeEscalation", "false")
+			}
+			setBool(securityContext, "allowPrivilegeEscalation", false)
+			record(containerName, "set allowPrivilegeEscalation: false")
+		}
+
+		if privileged {
+			edits.deletePair(securityContext, "privileged")
+			removeKey(securityContext, "privileged")
+			record(containerName, "removed privileged: true")
+		}
+
+		if image := mappingValue(container, "image"); image != nil && image.LineComment == "" && usesLatest(image.Value) {
+			edits.comment(image, latestComment)
+			image.LineComment = latestComment
+			record(containerName, "added a comment suggesting an image pin")
+		}
+	}
+
+	if needsRunAsNonRoot(podSpec, containers) {
+		securityContext := mappingValue(podSpec, "securityContext")
+		if securityContext == nil {
+			edits.addMapping(podSpec, "securityContext", "runAsNonRoot: true")
+			securityContext = addMapping(podSpec, "securityContext")
+		} else {
+			edits.setScalar(securityContext, "runAsNonRoot", "true")
+		}
+		setBool(securityContext, "runAsNonRoot", true)
+		record("", "set securityContext.runAsNonRoot: true")
+	}
+
+	return changes
+}
+
+// needsRunAsNonRoot reports whether the pod should get runAsNonRoot: true.
+// It is left alone when already set, and when the pod or a container asks
+// for UID 0 or runAsNonRoot: false explicitly, since those pods would no
+// longer start.
+func needsRunAsNonRoot(podSpec *yaml.Node, containers []*yaml.Node) bool {
+	podContext := mappingValue(podSpec, "securityContext")
+	if scalar(podContext, "runAsNonRoot") == "true" {
+		return false
+	}
+	if scalar(podContext, "runAsUser") == "0" || scalar(podContext, "runAsNonRoot") == "false" {
+		return false
+	}
+	for _, container := range containers {
+		securityContext := mappingValue(container, "securityContext")
+		if scalar(securityContext, "runAsUser") == "0" || scalar(securityContext, "runAsNonRoot") == "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// usesLatest reports whether an image reference resolves to :latest, with
+// the tag written out or left off. Digest-pinned references never do.
+func usesLatest(image string) bool {
+	if image == "" || strings.Contains(image, "@") {
+		return false
+	}
+	lastSlash := strings.LastIndex(image, "/")
+	colon := strings.LastIndex(image, ":")
+	if colon <= lastSlash {
+		return true
+	}
+	return image[colon+1:] == "latest"
+}
+
+// podSpecNode finds the pod spec in a resource, as parser.PodSpecPath does
+// for decoded resources
+func podSpecNode(root *yaml.Node, kind string) *yaml.Node {
+	spec := mappingValue(root, "spec")
+	if kind == "" || spec == nil {
+		return nil
+	}
+	if kind == "Pod" {
+		return spec
+	}
+	if podSpec := mappingValue(mappingValue(spec, "template"), "spec"); podSpec != nil {
+		return podSpec
+	}
+	jobSpec := mappingValue(mappingValue(spec, "jobTemplate"), "spec")
+	return mappingValue(mappingValue(jobSpec, "template"), "spec")
+}
+
+// indentOf guesses the indentation a document uses from its first nested
+// block mapping, defaulting to two spaces
+func indentOf(doc *yaml.Node) int {
+	if len(doc.Content) == 0 {
+		return 2
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Kind == yaml.MappingNode && value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0 {
+			if indent := value.Content[0].Column - key.Column; indent > 0 {
+				return indent
+			}
+		}
+	}
+	return 2
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalar returns the string value for key in a mapping node, or ""
+func scalar(mapping *yaml.Node, key string) string {
+	if value := mappingValue(mapping, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
+
+// addMapping appends an empty mapping under key and returns it
+func addMapping(mapping *yaml.Node, key string) *yaml.Node {
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// setBool sets key to a boolean in a mapping node, adding the key if needed
+func setBool(mapping *yaml.Node, key string, value bool) {
+	if existing := mappingValue(mapping, key); existing != nil {
+		existing.Kind, existing.Tag, existing.Style = yaml.ScalarNode, "!!bool", 0
+		existing.Value = fmt.Sprint(value)
+		existing.Content = nil
+		return
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(value)})
+}
+
+// removeKey deletes key and its value from a mapping node
+func removeKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		changes []string
+	}{
+		{
+			name: "privileged container",
+			in: `# API deployment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api # owned by shop
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      containers:
+      - name: app
+        image: api:1.4
+        securityContext:
+          privileged: true
+          readOnlyRootFilesystem: true # keep
+`,
+			want: `# API deployment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api # owned by shop
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      containers:
+      - name: app
+        image: api:1.4
+        securityContext:
+          allowPrivilegeEscalation: false
+          readOnlyRootFilesystem: true # keep
+`,
+			changes: []string{
+				"Deployment/api container app: set allowPrivilegeEscalation: false",
+				"Deployment/api container app: removed privileged: true",
+			},
+		},
+		{
+			name: "allowPrivilegeEscalation set to true",
+			in: `kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+  - name: web
+    image: nginx:1.25 # pinned
+    securityContext:
+      allowPrivilegeEscalation: true
+`,
+			want: `kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+  - name: web
+    image: nginx:1.25 # pinned
+    securityContext:
+      allowPrivilegeEscalation: false
+`,
+			changes: []string{"Pod/web container web: set allowPrivilegeEscalation: false"},
+		},
+		{
+			name: "missing securityContexts and a latest image",
+			in: `kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: proxy
+        image: envoy
+`,
+			want: `kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      containers:
+      - name: proxy
+        securityContext:
+          allowPrivilegeEscalation: false
+        image: envoy  # danger-scan: pin to a version tag or digest instead of :latest
+`,
+			changes: []string{
+				"Deployment/api container proxy: set allowPrivilegeEscalation: false",
+				"Deployment/api container proxy: added a comment suggesting an image pin",
+				"Deployment/api: set securityContext.runAsNonRoot: true",
+			},
+		},
+		{
+			name: "root user left alone",
+			in: `kind: Pod
+metadata:
+  name: root
+spec:
+  securityContext:
+    runAsUser: 0
+  containers:
+  - name: app
+    image: app:1.0
+    securityContext:
+      allowPrivilegeEscalation: false
+`,
+			want: `kind: Pod
+metadata:
+  name: root
+spec:
+  securityContext:
+    runAsUser: 0
+  containers:
+  - name: app
+    image: app:1.0
+    securityContext:
+      allowPrivilegeEscalation: false
+`,
+		},
+		{
+			name: "multiple documents",
+			in: `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+  - port: 80
+---
+# worker pod
+kind: Pod
+metadata:
+  name: worker
+spec:
+    securityContext:
+        runAsNonRoot: true
+    containers:
+    -   name: worker
+        image: worker:2.0
+        securityContext:
+            privileged: true
+            allowPrivilegeEscalation: false
+---
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+			want: `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+  - port: 80
+---
+# worker pod
+kind: Pod
+metadata:
+  name: worker
+spec:
+    securityContext:
+        runAsNonRoot: true
+    containers:
+    -   name: worker
+        image: worker:2.0
+        securityContext:
+            allowPrivilegeEscalation: false
+---
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+			changes: []string{"Pod/worker container worker: removed privileged: true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, changes, err := Source([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("Source failed: %v", err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.want)
+			}
+
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if len(got) != len(tt.changes) {
+				t.Fatalf("got changes %q, want %q", got, tt.changes)
+			}
+			for i := range got {
+				if got[i] != tt.changes[i] {
+					t.Errorf("change %d is %q, want %q", i, got[i], tt.changes[i])
+				}
+			}
+
+			// Fixing is idempotent
+			again, changes, err := Source(out)
+			if err != nil {
+				t.Fatalf("second Source failed: %v", err)
+			}
+			if len(changes) != 0 || string(again) != string(out) {
+				t.Errorf("second pass changed the output: %q", changes)
+			}
+		})
+	}
+}
+
+func TestFileDryRun(t *testing.T) {
+	manifest := `kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    runAsNonRoot: true
+  containers:
+  - name: web
+    image: nginx:1.25
+    securityContext:
+      privileged: true
+      allowPrivilegeEscalation: false
+`
+	path := filepath.Join(t.TempDir(), "pod.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := File(path, true)
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+	if len(result.Changes) != 1 {
+		t.Errorf("got changes %v, want one", result.Changes)
+	}
+
+	name := strings.TrimPrefix(filepath.ToSlash(path), "/")
+	want := "--- a/" + name + "\n+++ b/" + name + `
+@@ -8,5 +8,4 @@
+   - name: web
+     image: nginx:1.25
+     securityContext:
+-      privileged: true
+       allowPrivilegeEscalation: false
+`
+	if result.Diff != want {
+		t.Errorf("got diff\n%s\nwant\n%s", result.Diff, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != manifest {
+		t.Errorf("dry run rewrote the file:\n%s", data)
+	}
+
+	if _, err := File(path, false); err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(manifest, "      privileged: true\n", "", 1); string(data) != want {
+		t.Errorf("got file\n%s\nwant\n%s", data, want)
+	}
+}
@@ -0,0 +1,202 @@
+package gitutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+)
+
+// File is the content of a manifest on one side of a change
+type File struct {
+	Path string
+	Data []byte
+}
+
+// ChangedManifests compares the working tree (staged and unstaged changes,
+// plus untracked files) against ref and returns the manifests that changed.
+// oldFiles holds their content at ref and newFiles their current content.
+// Added files appear only in newFiles, deleted files only in oldFiles, and
+// renamed files under their old path and new path respectively. When paths
+// are given, only changes beneath them are considered. git must be on the
+// PATH; it is killed if ctx is done before it exits.
+func ChangedManifests(ctx context.Context, ref string, paths ...string) (oldFiles, newFiles []File, err error) {
+	root, err := toplevel(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	specs, err := pathspecs(root, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	readNew := func(path string) ([]byte, error) {
+		data, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return data, nil
+	}
+	oldFiles, newFiles, err = changedManifests(ctx, root, readNew, []string{ref}, specs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// git diff does not report files that have never been added
+	args := []string{"-C", root, "ls-files", "--others", "--exclude-standard", "-z", "--"}
+	untracked, err := run(ctx, append(args, specs...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path == "" || !parser.IsManifestPath(path) {
+			continue
+		}
+		data, err := readNew(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		newFiles = append(newFiles, File{Path: path, Data: data})
+	}
+
+	return oldFiles, newFiles, nil
+}
+
+// ChangedManifestsBetween is ChangedManifests for two revisions of the
+// repository: newFiles holds the manifests as of to rather than the working
+// tree, so nothing needs to be checked out.
+func ChangedManifestsBetween(ctx context.Context, from, to string, paths ...string) (oldFiles, newFiles []File, err error) {
+	root, err := toplevel(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	specs, err := pathspecs(root, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	readNew := func(path string) ([]byte, error) {
+		return ReadAt(ctx, root, to, path)
+	}
+	return changedManifests(ctx, root, readNew, []string{from, to}, specs)
+}
+
+// ReadAt returns the content of path, relative to the root of the repository
+// at root, as of revision rev
+func ReadAt(ctx context.Context, root, rev, path string) ([]byte, error) {
+	data, err := run(ctx, "-C", root, "show", rev+":"+path)
+	return []byte(data), err
+}
+
+// toplevel returns the root of the repository holding the working directory
+func toplevel(ctx context.Context) (string, error) {
+	root, err := run(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(root), nil
+}
+
+// pathspecs converts paths given relative to the working directory into
+// literal git pathspecs relative to the repository root
+func pathspecs(root string, paths []string) ([]string, error) {
+	var specs []string
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		// The root git reports has symlinks resolved, so resolve the path too
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the repository at %s", path, root)
+		}
+		specs = append(specs, ":(literal)"+filepath.ToSlash(rel))
+	}
+	return specs, nil
+}
+
+// changedManifests lists the manifests git diff reports for revs, limited to
+// specs, reading their old content from the first revision and new content
+// with readNew
+func changedManifests(ctx context.Context, root string, readNew func(path string) ([]byte, error), revs, specs []string) (oldFiles, newFiles []File, err error) {
+	args := append([]string{"-C", root, "diff", "--name-status", "-M", "-z"}, revs...)
+	args = append(args, "--")
+	out, err := run(ctx, append(args, specs...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+
+		var oldPath, newPath string
+		switch status[0] {
+		case 'R', 'C':
+			if i+2 >= len(fields) {
+				return nil, nil, fmt.Errorf("unexpected git diff output")
+			}
+			oldPath, newPath = fields[i+1], fields[i+2]
+			i += 2
+		case 'A':
+			newPath = fields[i+1]
+			i++
+		case 'D':
+			oldPath = fields[i+1]
+			i++
+		default:
+			oldPath, newPath = fields[i+1], fields[i+1]
+			i++
+		}
+
+		if oldPath != "" && parser.IsManifestPath(oldPath) {
+			data, err := ReadAt(ctx, root, revs[0], oldPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			oldFiles = append(oldFiles, File{Path: oldPath, Data: data})
+		}
+
+		if newPath != "" && parser.IsManifestPath(newPath) {
+			data, err := readNew(newPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			newFiles = append(newFiles, File{Path: newPath, Data: data})
+		}
+	}
+	return oldFiles, newFiles, nil
+}
+
+// run executes git with the given arguments and returns its stdout. git is
+// killed if ctx is done first.
+func run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[len(args)-1], msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,111 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initRepo creates a repository with two commits, tagged v1 and v2, and
+// makes it the working directory. Between them api.yaml changes, old.yaml is
+// deleted, new.yaml is added and README.md, not a manifest, changes.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit := func(tag string) {
+		t.Helper()
+		git("add", "-A")
+		git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", tag)
+		git("tag", tag)
+	}
+
+	git("init", "-q")
+	write("api.yaml", "kind: Pod\nmetadata:\n  name: api\n")
+	write("old.yaml", "kind: Pod\nmetadata:\n  name: old\n")
+	write("README.md", "v1\n")
+	commit("v1")
+
+	write("api.yaml", "kind: Pod\nmetadata:\n  name: api\nspec:\n  hostPID: true\n")
+	write("new.yaml", "kind: Pod\nmetadata:\n  name: new\n")
+	write("README.md", "v2\n")
+	if err := os.Remove(filepath.Join(dir, "old.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	commit("v2")
+	return dir
+}
+
+// contents maps each file's path to its content
+func contents(files []File) map[string]string {
+	m := make(map[string]string)
+	for _, f := range files {
+		m[f.Path] = string(f.Data)
+	}
+	return m
+}
+
+func TestReadAt(t *testing.T) {
+	dir := initRepo(t)
+	ctx := context.Background()
+
+	data, err := ReadAt(ctx, dir, "v1", "api.yaml")
+	if err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	if want := "kind: Pod\nmetadata:\n  name: api\n"; string(data) != want {
+		t.Errorf("got %q at v1, want %q", data, want)
+	}
+
+	if _, err := ReadAt(ctx, dir, "v2", "old.yaml"); err == nil {
+		t.Error("read old.yaml at v2, where it was deleted")
+	}
+}
+
+func TestChangedManifestsBetween(t *testing.T) {
+	initRepo(t)
+
+	oldFiles, newFiles, err := ChangedManifestsBetween(context.Background(), "v1", "v2")
+	if err != nil {
+		t.Fatalf("ChangedManifestsBetween failed: %v", err)
+	}
+
+	old, updated := contents(oldFiles), contents(newFiles)
+	if len(old) != 2 || old["api.yaml"] != "kind: Pod\nmetadata:\n  name: api\n" || old["old.yaml"] == "" {
+		t.Errorf("got old files %q, want api.yaml and old.yaml as of v1", old)
+	}
+	if len(updated) != 2 || updated["api.yaml"] != "kind: Pod\nmetadata:\n  name: api\nspec:\n  hostPID: true\n" || updated["new.yaml"] == "" {
+		t.Errorf("got new files %q, want api.yaml and new.yaml as of v2", updated)
+	}
+}
+
+func TestChangedManifestsCanceled(t *testing.T) {
+	initRepo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := ChangedManifests(ctx, "v1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Level controls how much diagnostic output is written
+type Level int
+
+const (
+	LevelQuiet   Level = iota // Errors only
+	LevelNormal               // Warnings and errors
+	LevelVerbose              // Informational messages, warnings and errors
+	LevelDebug                // Everything, including why inputs were skipped
+)
+
+// Logger writes leveled diagnostic messages. It is safe for concurrent use.
+type Logger struct {
+	mu           sync.Mutex
+	writer       io.Writer
+	level        Level
+	progress     bool // Whether transient progress lines are enabled
+	progressLine bool // Whether a progress line is currently displayed
+}
+
+// New creates a logger that writes messages at or below the given level
+func New(writer io.Writer, level Level) *Logger {
+	return &Logger{
+		writer: writer,
+		level:  level,
+	}
+}
+
+// WithProgress toggles transient progress lines. Only enable it when the
+// writer is a terminal, since progress lines are redrawn in place.
+func (l *Logger) WithProgress(enabled bool) *Logger {
+	l.progress = enabled && l.level > LevelQuiet
+	return l
+}
+
+// IsTerminal reports whether f is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Progressf replaces the current progress line, if progress is enabled
+func (l *Logger) Progressf(format string, args ...interface{}) {
+	if !l.progress {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.writer, "\r\033[K"+format, args...)
+	l.progressLine = true
+}
+
+// ClearProgress erases the current progress line so normal output starts on
+// a clean line
+func (l *Logger) ClearProgress() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clearProgress()
+}
+
+// clearProgress is ClearProgress for callers holding the lock
+func (l *Logger) clearProgress() {
+	if !l.progressLine {
+		return
+	}
+	fmt.Fprint(l.writer, "\r\033[K")
+	l.progressLine = false
+}
+
+// Warnf logs a warning unless the logger is quiet
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelNormal, "Warning: ", format, args...)
+}
+
+// Infof logs an informational message in verbose mode only
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelVerbose, "", format, args...)
+}
+
+// Debugf logs a debug message in debug mode only
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, "Debug: ", format, args...)
+}
+
+// logf writes a message if the logger's level permits it
+func (l *Logger) logf(level Level, prefix, format string, args ...interface{}) {
+	if l.level < level {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clearProgress()
+	fmt.Fprintf(l.writer, prefix+format+"\n", args...)
+}
+
+// Slog returns a slog.Logger that writes through l, for packages such as
+// the parser that accept one. Records are filtered by l's level and written
+// as "Warning: message key=value".
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(&handler{logger: l})
+}
+
+// handler adapts a Logger to slog.Handler
+type handler struct {
+	logger *Logger
+	attrs  string // Preformatted " key=value" pairs from WithAttrs
+	group  string // Key prefix from WithGroup, ending in "."
+}
+
+// levelFor maps a slog level onto the logger level that shows it
+func levelFor(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return LevelQuiet
+	case level >= slog.LevelWarn:
+		return LevelNormal
+	case level >= slog.LevelInfo:
+		return LevelVerbose
+	default:
+		return LevelDebug
+	}
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.level >= levelFor(level)
+}
+
+func (h *handler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&b, h.group, attr)
+		return true
+	})
+
+	prefix := ""
+	switch levelFor(record.Level) {
+	case LevelQuiet:
+		prefix = "Error: "
+	case LevelNormal:
+		prefix = "Warning: "
+	case LevelDebug:
+		prefix = "Debug: "
+	}
+	h.logger.logf(levelFor(record.Level), prefix, "%s", b.String())
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, attr := range attrs {
+		writeAttr(&b, h.group, attr)
+	}
+	return &handler{logger: h.logger, attrs: b.String(), group: h.group}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{logger: h.logger, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// writeAttr appends " key=value", quoting values that contain spaces
+func writeAttr(b *strings.Builder, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, a := range attr.Value.Group() {
+			writeAttr(b, prefix, a)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", group, attr.Key, value)
+}
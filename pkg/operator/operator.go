@@ -0,0 +1,309 @@
+// Package operator runs the scanner as an in-cluster controller: it scans
+// live resources on an interval and records the findings for each namespace
+// in a DangerScanReport custom resource, so they can be read with kubectl
+// get dangerscanreports or watched by other controllers.
+package operator
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/cluster"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/logger"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/scanner"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// CRD is the CustomResourceDefinition manifest for DangerScanReport, to be
+// applied before the operator starts
+//
+//go:embed crd.yaml
+var CRD string
+
+const (
+	// APIVersion is the group and version of the DangerScanReport resource
+	APIVersion = "danger-scan.io/v1alpha1"
+
+	// ReportName names the single DangerScanReport kept in each namespace
+	ReportName = "danger-scan"
+
+	// fieldManager owns the report fields in server-side apply
+	fieldManager = "k8s-danger-scan"
+
+	// maxReportFindings bounds the findings stored in one report, keeping it
+	// well under the API server's object size limit
+	maxReportFindings = 1000
+)
+
+// serviceAccountNamespace holds the namespace of the pod the operator runs in
+const serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Options configures what the controller scans and where it reports
+type Options struct {
+	Cluster cluster.Options // Resources to read on each pass
+
+	// ClusterNamespace holds the report for findings on cluster-scoped
+	// objects such as ClusterRoles; empty means the operator's own namespace
+	ClusterNamespace string
+}
+
+// Controller periodically scans the cluster and writes a DangerScanReport
+// per namespace
+type Controller struct {
+	scanner *scanner.Scanner
+	options Options
+	log     *logger.Logger
+}
+
+// NewController creates a controller that scans with s. Failed passes are
+// reported to log.
+func NewController(s *scanner.Scanner, options Options, log *logger.Logger) *Controller {
+	if options.ClusterNamespace == "" {
+		options.ClusterNamespace = InClusterNamespace()
+	}
+	return &Controller{scanner: s, options: options, log: log}
+}
+
+// InClusterNamespace returns the namespace of the pod the process runs in,
+// or "default" outside a cluster
+func InClusterNamespace() string {
+	data, err := os.ReadFile(serviceAccountNamespace)
+	if err != nil {
+		return "default"
+	}
+	if namespace := strings.TrimSpace(string(data)); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// Run reconciles immediately and then every interval until ctx is done. A
+// failed pass is logged and retried at the next interval.
+func (c *Controller) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Reconcile(ctx); err != nil && ctx.Err() == nil {
+			c.log.Warnf("scan failed, retrying in %s: %v", interval, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile runs one pass: it reads and scans the cluster, then applies a
+// report for every namespace with scanned resources
+func (c *Controller) Reconcile(ctx context.Context) error {
+	resources, warnings, err := cluster.Fetch(ctx, c.options.Cluster)
+	if err != nil {
+		return fmt.Errorf("failed to read cluster resources: %w", err)
+	}
+	for _, w := range warnings {
+		c.log.Warnf("%s: %s", w.Path, w.Message)
+	}
+
+	result, err := c.scanner.ScanContext(ctx, resources)
+	if err != nil {
+		return err
+	}
+
+	reports := Reports(resources, result.Findings, c.options.ClusterNamespace, time.Now())
+	if err := apply(ctx, c.options.Cluster.Context, reports); err != nil {
+		return err
+	}
+	c.log.Infof("Scanned %d resources, wrote %d report(s)", result.Stats.ResourcesScanned, len(reports))
+	return nil
+}
+
+// Report is a DangerScanReport object
+type Report struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Metadata   ReportMetadata  `json:"metadata"`
+	ScanTime   string          `json:"scanTime"` // RFC 3339
+	Summary    ReportSummary   `json:"summary"`
+	Truncated  bool            `json:"truncated,omitempty"`
+	Findings   []ReportFinding `json:"findings"`
+}
+
+// ReportMetadata is the object metadata of a report
+type ReportMetadata struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// ReportSummary counts a namespace's findings. Counts include findings left
+// out of a truncated report.
+type ReportSummary struct {
+	Critical          int `json:"critical"`
+	High              int `json:"high"`
+	Medium            int `json:"medium"`
+	Low               int `json:"low"`
+	ResourcesScanned  int `json:"resourcesScanned"`
+	ResourcesAffected int `json:"resourcesAffected"`
+}
+
+// ReportFinding is a finding as stored in a report, with camelCase field
+// names as Kubernetes APIs use
+type ReportFinding struct {
+	RuleID      string         `json:"ruleID"`
+	Severity    types.Severity `json:"severity"`
+	Category    types.Category `json:"category,omitempty"`
+	Kind        string         `json:"kind"`
+	Name        string         `json:"name"`
+	Namespace   string         `json:"namespace,omitempty"`
+	Container   string         `json:"container,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+	Fix         string         `json:"fix,omitempty"`
+	CISControl  string         `json:"cisControl,omitempty"`
+	Fingerprint string         `json:"fingerprint,omitempty"`
+}
+
+// Reports builds one report per namespace that has scanned resources.
+// Findings on cluster-scoped objects go in clusterNamespace's report.
+// Suppressed findings are left out.
+func Reports(resources []parser.K8sResource, findings []types.Finding, clusterNamespace string, now time.Time) []Report {
+	scanned := make(map[string]int)
+	for _, resource := range resources {
+		if !parser.IsSupportedKind(resource.Kind) {
+			continue
+		}
+		namespace := resource.Metadata.Namespace
+		if namespace == "" {
+			namespace = clusterNamespace
+		}
+		scanned[namespace]++
+	}
+
+	byNamespace := make(map[string][]types.Finding)
+	for _, f := range findings {
+		if f.Suppressed {
+			continue
+		}
+		namespace := f.Namespace
+		if namespace == "" {
+			namespace = clusterNamespace
+		}
+		byNamespace[namespace] = append(byNamespace[namespace], f)
+		if _, ok := scanned[namespace]; !ok {
+			scanned[namespace] = 0
+		}
+	}
+
+	namespaces := make([]string, 0, len(scanned))
+	for namespace := range scanned {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	reports := make([]Report, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		nsFindings := byNamespace[namespace]
+		summary := scanner.GetSummary(nsFindings)
+		report := Report{
+			APIVersion: APIVersion,
+			Kind:       "DangerScanReport",
+			Metadata: ReportMetadata{
+				Name:      ReportName,
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "k8s-danger-scan"},
+			},
+			ScanTime: now.UTC().Format(time.RFC3339),
+			Summary: ReportSummary{
+				Critical:          summary.Critical,
+				High:              summary.High,
+				Medium:            summary.Medium,
+				Low:               summary.Low,
+				ResourcesScanned:  scanned[namespace],
+				ResourcesAffected: summary.ResourcesAffected,
+			},
+			Findings: []ReportFinding{},
+		}
+
+		// Keep the most severe findings when they don't all fit
+		sort.SliceStable(nsFindings, func(i, j int) bool {
+			return nsFindings[i].Severity.Rank() > nsFindings[j].Severity.Rank()
+		})
+		if len(nsFindings) > maxReportFindings {
+			nsFindings = nsFindings[:maxReportFindings]
+			report.Truncated = true
+		}
+		for _, f := range nsFindings {
+			report.Findings = append(report.Findings, ReportFinding{
+				RuleID:      f.RuleID,
+				Severity:    f.Severity,
+				Category:    f.Category,
+				Kind:        f.Kind,
+				Name:        f.Name,
+				Namespace:   f.Namespace,
+				Container:   f.Container,
+				Reason:      f.Reason,
+				Fix:         f.Fix,
+				CISControl:  f.CISControl,
+				Fingerprint: f.Fingerprint,
+			})
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// apply writes reports with kubectl server-side apply, replacing each
+// namespace's previous report
+func apply(ctx context.Context, kubeContext string, reports []Report) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	bin, err := exec.LookPath("kubectl")
+	if err != nil {
+		return cluster.ErrKubectlNotFound
+	}
+
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      reports,
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to encode reports: %w", err)
+	}
+
+	args := []string{"apply", "--server-side", "--force-conflicts", "--field-manager", fieldManager, "-f", "-"}
+	if kubeContext != "" {
+		args = append(args, "--context", kubeContext)
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		if strings.Contains(msg, "no matches for kind") {
+			return errors.New("the DangerScanReport CRD is not installed (apply the output of k8s-danger-scan operator crd)")
+		}
+		return fmt.Errorf("kubectl apply failed: %s", msg)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// controlGroup holds the findings reported against one benchmark control
+type controlGroup struct {
+	ID       string // Empty for findings that map to no control
+	Title    string
+	Findings []types.Finding
+}
+
+// name labels the group in reports, e.g. "CIS 5.2.1"
+func (g controlGroup) name() string {
+	if g.ID == "" {
+		return "Not mapped to a CIS control"
+	}
+	return "CIS " + g.ID
+}
+
+// groupByControl splits findings by CIS control in benchmark order. A
+// finding mapped to several controls, such as "5.2.2, 5.2.3", is listed under
+// each; findings mapped to none come last.
+func groupByControl(findings []types.Finding) []controlGroup {
+	index := make(map[string]int)
+	var groups []controlGroup
+	var unmapped []types.Finding
+	for _, finding := range findings {
+		if finding.CISControl == "" {
+			unmapped = append(unmapped, finding)
+			continue
+		}
+		for _, id := range strings.Split(finding.CISControl, ",") {
+			id = strings.TrimSpace(id)
+			i, ok := index[id]
+			if !ok {
+				i = len(groups)
+				index[id] = i
+				groups = append(groups, controlGroup{ID: id, Title: rules.CISControlTitle(id)})
+			}
+			groups[i].Findings = append(groups[i].Findings, finding)
+		}
+	}
+
+	sortControlGroups(groups)
+	if len(unmapped) > 0 {
+		groups = append(groups, controlGroup{Findings: unmapped})
+	}
+	return groups
+}
+
+// sortControlGroups orders groups by control number, comparing each dotted
+// part numerically so that 5.2.10 follows 5.2.9
+func sortControlGroups(groups []controlGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := strings.Split(groups[i].ID, "."), strings.Split(groups[j].ID, ".")
+		for k := 0; k < len(a) && k < len(b); k++ {
+			an, aErr := strconv.Atoi(a[k])
+			bn, bErr := strconv.Atoi(b[k])
+			if aErr != nil || bErr != nil {
+				if a[k] != b[k] {
+					return a[k] < b[k]
+				}
+				continue
+			}
+			if an != bn {
+				return an < bn
+			}
+		}
+		return len(a) < len(b)
+	})
+}
+
+// mappedControls counts the groups that name a control
+func mappedControls(groups []controlGroup) int {
+	n := 0
+	for _, group := range groups {
+		if group.ID != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"encoding/csv"
+	"strconv"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// csvHeader lists the CSV output columns in order. The container column is
+// empty for findings about a whole resource, and line is empty when the
+// position isn't known.
+var csvHeader = []string{"severity", "rule_id", "kind", "name", "namespace", "container", "reason", "fix", "file", "line"}
+
+// outputCSV outputs one row per finding, preceded by a header row
+func (f *Formatter) outputCSV(result types.ScanResult) error {
+	w := csv.NewWriter(f.writer)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, finding := range result.Findings {
+		// Rows are actionable findings; suppressed and resolved ones have no
+		// column to mark them
+		if finding.Suppressed || finding.Status == types.StatusResolved {
+			continue
+		}
+		line := ""
+		if finding.Line > 0 {
+			line = strconv.Itoa(finding.Line)
+		}
+		row := []string{
+			string(finding.Severity),
+			finding.RuleID,
+			finding.Kind,
+			finding.Name,
+			finding.Namespace,
+			finding.Container,
+			finding.Reason,
+			finding.Fix,
+			finding.File,
+			line,
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"sort"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+//go:embed report.html
+var reportTemplate string
+
+// report is the self-contained HTML report, parsed once
+var report = template.Must(template.New("report").Funcs(template.FuncMap{
+	"location":   location,
+	"references": formatReferences,
+	"resource":   resourceName,
+	"severityClass": func(severity types.Severity) string {
+		return "sev-" + string(severity)
+	},
+}).Parse(reportTemplate))
+
+// htmlSeverities lists severities from most to least severe, as the report
+// charts them
+var htmlSeverities = []types.Severity{types.Critical, types.High, types.Medium, types.Low}
+
+// htmlBar is one bar of a report chart
+type htmlBar struct {
+	Severity types.Severity
+	Count    int
+	Percent  float64 // Share of the chart's scale, for the bar width
+}
+
+// htmlNamespace groups the findings in one namespace
+type htmlNamespace struct {
+	Name     string // Empty for resources that don't set one
+	Findings []types.Finding
+	Bars     []htmlBar // Per-severity segments of the namespace's stacked bar
+}
+
+// htmlData is the context the report template is executed with
+type htmlData struct {
+	Version    string
+	Summary    types.Summary
+	Total      int
+	Bars       []htmlBar
+	Namespaces []htmlNamespace
+	Warnings   []types.Warning
+	Stats      *types.Stats
+
+	ResourcesAdded   []types.ResourceRef
+	ResourcesRemoved []types.ResourceRef
+}
+
+// outputHTML writes a self-contained HTML report: severity and per-namespace
+// charts drawn in CSS, then the findings grouped by namespace, each
+// expandable for its impact, fix and snippet. Nothing is loaded from the
+// network, so the file can be attached to a ticket as is.
+func (f *Formatter) outputHTML(result types.ScanResult, summary types.Summary) error {
+	data := htmlData{
+		Version:          f.toolVersion,
+		Summary:          summary,
+		Total:            len(result.Findings),
+		Namespaces:       groupByNamespace(result.Findings),
+		Warnings:         result.Warnings,
+		ResourcesAdded:   result.ResourcesAdded,
+		ResourcesRemoved: result.ResourcesRemoved,
+	}
+	if f.showStats {
+		data.Stats = &result.Stats
+	}
+
+	counts := map[types.Severity]int{
+		types.Critical: summary.Critical,
+		types.High:     summary.High,
+		types.Medium:   summary.Medium,
+		types.Low:      summary.Low,
+	}
+	largest := 0
+	for _, count := range counts {
+		largest = max(largest, count)
+	}
+	for _, severity := range htmlSeverities {
+		data.Bars = append(data.Bars, htmlBar{Severity: severity, Count: counts[severity], Percent: percent(counts[severity], largest)})
+	}
+
+	// Namespace bars share one scale so their lengths compare
+	largest = 0
+	for _, namespace := range data.Namespaces {
+		largest = max(largest, len(namespace.Findings))
+	}
+	for i := range data.Namespaces {
+		bySeverity := make(map[types.Severity]int)
+		for _, finding := range data.Namespaces[i].Findings {
+			bySeverity[finding.Severity]++
+		}
+		for _, severity := range htmlSeverities {
+			if bySeverity[severity] > 0 {
+				data.Namespaces[i].Bars = append(data.Namespaces[i].Bars,
+					htmlBar{Severity: severity, Count: bySeverity[severity], Percent: percent(bySeverity[severity], largest)})
+			}
+		}
+	}
+
+	if err := report.Execute(f.writer, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+// groupByNamespace splits findings by namespace, sorted by name with
+// findings that have none last. Findings keep their order within a group.
+func groupByNamespace(findings []types.Finding) []htmlNamespace {
+	index := make(map[string]int)
+	var groups []htmlNamespace
+	for _, finding := range findings {
+		i, ok := index[finding.Namespace]
+		if !ok {
+			i = len(groups)
+			index[finding.Namespace] = i
+			groups = append(groups, htmlNamespace{Name: finding.Namespace})
+		}
+		groups[i].Findings = append(groups[i].Findings, finding)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Name == "" || groups[j].Name == "" {
+			return groups[j].Name == "" && groups[i].Name != ""
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// percent returns count as a percentage of scale, or 0 for an empty scale
+func percent(count, scale int) float64 {
+	if scale == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(scale)
+}
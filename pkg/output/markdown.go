@@ -0,0 +1,216 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// severityEmoji marks a severity in Markdown output and templates
+func severityEmoji(severity types.Severity) string {
+	switch severity {
+	case types.Critical:
+		return "🟣"
+	case types.High:
+		return "🔴"
+	case types.Medium:
+		return "🟡"
+	default:
+		return "⚪"
+	}
+}
+
+// outputMarkdown writes a GitHub-flavored Markdown report for PR comments:
+// a summary header, a table with one row per finding, then a collapsed
+// details block per finding with its impact, fix and snippet
+func (f *Formatter) outputMarkdown(result types.ScanResult, summary types.Summary) error {
+	var b strings.Builder
+
+	b.WriteString("## k8s-danger-scan\n\n")
+	if len(result.Findings) == 0 {
+		b.WriteString("✅ No security issues found.\n")
+	} else {
+		b.WriteString(markdownSummary(summary) + "\n\n")
+		if f.compliance == types.ComplianceCIS {
+			writeMarkdownControls(&b, groupByControl(result.Findings))
+		}
+
+		b.WriteString("| | Severity | Rule | Resource | File |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+		for _, finding := range result.Findings {
+			severity := string(finding.Severity)
+			emoji := severityEmoji(finding.Severity)
+			if finding.Suppressed {
+				severity += " (suppressed)"
+			}
+			if finding.Status == types.StatusResolved {
+				severity += " (resolved)"
+				emoji = "✅"
+			}
+			file := ""
+			if finding.File != "" {
+				file = "`" + markdownCell(location(finding)) + "`"
+			}
+			fmt.Fprintf(&b, "| %s | %s | `%s` | %s | %s |\n",
+				emoji, severity, finding.RuleID, markdownCell(resourceName(finding)), file)
+		}
+
+		b.WriteString("\n")
+		for _, finding := range result.Findings {
+			writeMarkdownDetails(&b, finding)
+		}
+	}
+
+	writeMarkdownChanges(&b, result)
+	writeMarkdownPSS(&b, result)
+	if summary.Warnings > 0 {
+		fmt.Fprintf(&b, "\n⚠️ %d file(s) could not be parsed and were skipped.\n", summary.Warnings)
+	}
+	if f.showStats {
+		stats := result.Stats
+		fmt.Fprintf(&b, "\n<sub>%d files parsed, %d resources scanned, %d rules run in %dms</sub>\n",
+			stats.FilesParsed, stats.ResourcesScanned, stats.RulesRun, stats.ElapsedMillis)
+	}
+
+	_, err := io.WriteString(f.writer, b.String())
+	return err
+}
+
+// markdownSummary is the one-line count shown above the findings table
+func markdownSummary(summary types.Summary) string {
+	var parts []string
+	if summary.Critical > 0 {
+		parts = append(parts, fmt.Sprintf("%s **%d critical**", severityEmoji(types.Critical), summary.Critical))
+	}
+	if summary.High > 0 {
+		parts = append(parts, fmt.Sprintf("%s **%d high**", severityEmoji(types.High), summary.High))
+	}
+	if summary.Medium > 0 {
+		parts = append(parts, fmt.Sprintf("%s **%d medium**", severityEmoji(types.Medium), summary.Medium))
+	}
+	if summary.Low > 0 {
+		parts = append(parts, fmt.Sprintf("%s **%d low**", severityEmoji(types.Low), summary.Low))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "No unsuppressed findings")
+	}
+
+	line := strings.Join(parts, " · ") + fmt.Sprintf(" across %d resource(s)", summary.ResourcesAffected)
+	if summary.Suppressed > 0 {
+		line += fmt.Sprintf(" (%d suppressed)", summary.Suppressed)
+	}
+	if summary.Baselined > 0 {
+		line += fmt.Sprintf(", %d more in the baseline", summary.Baselined)
+	}
+	if summary.Excepted > 0 {
+		line += fmt.Sprintf(", %d excepted", summary.Excepted)
+	}
+	if summary.Resolved > 0 {
+		line += fmt.Sprintf(", ✅ %d resolved", summary.Resolved)
+	}
+	return line
+}
+
+// writeMarkdownControls writes a table of the benchmark controls the
+// findings fail, with the number of findings and the highest severity of each
+func writeMarkdownControls(b *strings.Builder, groups []controlGroup) {
+	b.WriteString("| | CIS control | Findings |\n")
+	b.WriteString("|---|---|---|\n")
+	for _, group := range groups {
+		highest := group.Findings[0].Severity
+		for _, finding := range group.Findings {
+			if finding.Severity.Rank() > highest.Rank() {
+				highest = finding.Severity
+			}
+		}
+		name := group.name()
+		if group.Title != "" {
+			name = fmt.Sprintf("**%s** %s", group.name(), group.Title)
+		}
+		fmt.Fprintf(b, "| %s | %s | %d |\n", severityEmoji(highest), markdownCell(name), len(group.Findings))
+	}
+	b.WriteString("\n")
+}
+
+// writeMarkdownDetails writes the collapsible block for one finding
+func writeMarkdownDetails(b *strings.Builder, finding types.Finding) {
+	fmt.Fprintf(b, "<details>\n<summary>%s <code>%s</code> %s</summary>\n\n",
+		severityEmoji(finding.Severity), html.EscapeString(finding.RuleID), html.EscapeString(resourceName(finding)))
+
+	if finding.Container != "" {
+		fmt.Fprintf(b, "**Container:** %s<br>\n", html.EscapeString(finding.Container))
+	}
+	fmt.Fprintf(b, "**Reason:** %s<br>\n", html.EscapeString(finding.Reason))
+	fmt.Fprintf(b, "**Impact:** %s<br>\n", html.EscapeString(finding.Impact))
+	fmt.Fprintf(b, "**Fix:** %s\n", html.EscapeString(finding.Fix))
+	if refs := formatReferences(finding); refs != "" {
+		fmt.Fprintf(b, "<br>**References:** %s\n", html.EscapeString(refs))
+	}
+	if finding.Snippet != "" {
+		fmt.Fprintf(b, "\n```yaml\n%s\n```\n", finding.Snippet)
+	}
+	b.WriteString("\n</details>\n\n")
+}
+
+// writeMarkdownChanges lists the resources a diff added or removed
+func writeMarkdownChanges(b *strings.Builder, result types.ScanResult) {
+	sections := []struct {
+		title     string
+		resources []types.ResourceRef
+	}{
+		{"Resources added", result.ResourcesAdded},
+		{"Resources removed", result.ResourcesRemoved},
+	}
+
+	for _, section := range sections {
+		if len(section.resources) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n**%s**\n\n", section.title)
+		for _, r := range section.resources {
+			name := r.Kind + "/" + r.Name
+			if r.Namespace != "" {
+				name = r.Kind + "/" + r.Namespace + "/" + r.Name
+			}
+			fmt.Fprintf(b, "- %s\n", markdownCell(name))
+		}
+	}
+}
+
+// writeMarkdownPSS lists the workloads failing the evaluated Pod Security
+// Standards level, when one was evaluated
+func writeMarkdownPSS(b *strings.Builder, result types.ScanResult) {
+	if result.PSSLevel == "" {
+		return
+	}
+
+	passed := 0
+	var failed []string
+	for _, verdict := range result.PSSVerdicts {
+		if verdict.Violates == "" {
+			passed++
+			continue
+		}
+		name := verdict.Kind + "/" + verdict.Name
+		if verdict.Namespace != "" {
+			name = verdict.Kind + "/" + verdict.Namespace + "/" + verdict.Name
+		}
+		failed = append(failed, fmt.Sprintf("- %s violates **%s** (`%s`)",
+			markdownCell(name), verdict.Violates, strings.Join(verdict.Controls, "`, `")))
+	}
+
+	fmt.Fprintf(b, "\n**Pod Security Standards (%s):** %d of %d workload(s) pass\n", result.PSSLevel, passed, len(result.PSSVerdicts))
+	if len(failed) > 0 {
+		fmt.Fprintf(b, "\n%s\n", strings.Join(failed, "\n"))
+	}
+}
+
+// markdownCell escapes text for a table cell, where a pipe would end the
+// cell and a newline the row
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
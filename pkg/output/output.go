@@ -1,17 +1,32 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"text/template"
 
 	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
 )
 
+// SchemaVersion identifies the shape of the JSON output. Only
+// backward-compatible additions are made within a schema version.
+const SchemaVersion = "1"
+
+// ToolName is reported as the producing tool in machine-readable output
+const ToolName = "k8s-danger-scan"
+
 // Formatter handles output formatting
 type Formatter struct {
-	writer io.Writer
-	format types.OutputFormat
+	writer      io.Writer
+	format      types.OutputFormat
+	showStats   bool
+	toolVersion string
+	template    *template.Template
+	color       bool
+	compliance  types.ComplianceFramework
 }
 
 // NewFormatter creates a new output formatter
@@ -22,26 +37,106 @@ func NewFormatter(writer io.Writer, format types.OutputFormat) *Formatter {
 	}
 }
 
-// Output writes the findings and summary using the configured format
-func (f *Formatter) Output(findings []types.Finding, summary types.Summary) error {
+// WithStats toggles whether scan statistics are included in the output
+func (f *Formatter) WithStats(enabled bool) *Formatter {
+	f.showStats = enabled
+	return f
+}
+
+// WithColor toggles ANSI colors in human-readable and table output. Only
+// enable it when writing to a terminal.
+func (f *Formatter) WithColor(enabled bool) *Formatter {
+	f.color = enabled
+	return f
+}
+
+// WithCompliance groups human-readable, table and Markdown output by the
+// controls of a benchmark instead of by resource
+func (f *Formatter) WithCompliance(framework types.ComplianceFramework) *Formatter {
+	f.compliance = framework
+	return f
+}
+
+// WithVersion sets the tool version reported in machine-readable output
+func (f *Formatter) WithVersion(version string) *Formatter {
+	f.toolVersion = version
+	return f
+}
+
+// Output writes the scan result and summary using the configured format
+func (f *Formatter) Output(result types.ScanResult, summary types.Summary) error {
 	switch f.format {
 	case types.FormatJSON:
-		return f.outputJSON(findings, summary)
+		return f.outputJSON(result, summary)
+	case types.FormatCSV:
+		return f.outputCSV(result)
+	case types.FormatSARIF:
+		return f.outputSARIF(result)
+	case types.FormatMarkdown:
+		return f.outputMarkdown(result, summary)
+	case types.FormatHTML:
+		return f.outputHTML(result, summary)
+	case types.FormatTemplate:
+		return f.outputTemplate(result, summary)
+	case types.FormatTable:
+		return f.outputTable(result, summary)
 	case types.FormatHuman:
-		return f.outputHuman(findings, summary)
+		return f.outputHuman(result, summary)
 	default:
-		return f.outputHuman(findings, summary)
+		return f.outputHuman(result, summary)
 	}
 }
 
+// RenderToString renders a scan result and summary in the given format and
+// returns it as a string. It is intended for tests and golden-file snapshots
+// that would otherwise need to wire a Formatter to a buffer by hand.
+func RenderToString(format types.OutputFormat, result types.ScanResult, summary types.Summary) (string, error) {
+	var buf bytes.Buffer
+	if err := NewFormatter(&buf, format).Output(result, summary); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // outputJSON outputs findings in JSON format
-func (f *Formatter) outputJSON(findings []types.Finding, summary types.Summary) error {
+func (f *Formatter) outputJSON(result types.ScanResult, summary types.Summary) error {
+	warnings := result.Warnings
+	if warnings == nil {
+		warnings = []types.Warning{}
+	}
+
+	type tool struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+
 	output := struct {
-		Summary  types.Summary   `json:"summary"`
-		Findings []types.Finding `json:"findings"`
+		SchemaVersion string                   `json:"schema_version"`
+		Tool          tool                     `json:"tool"`
+		Summary       types.Summary            `json:"summary"`
+		Findings      []types.Finding          `json:"findings"`
+		Warnings      []types.Warning          `json:"warnings"`
+		Added         []types.ResourceRef      `json:"resources_added,omitempty"`
+		Removed       []types.ResourceRef      `json:"resources_removed,omitempty"`
+		Exceptions    []types.AppliedException `json:"exceptions_applied,omitempty"`
+		PSSLevel      types.PSSLevel           `json:"pss_level,omitempty"`
+		PSSVerdicts   []types.PSSVerdict       `json:"pss_verdicts,omitempty"`
+		Stats         *types.Stats             `json:"stats,omitempty"`
 	}{
-		Summary:  summary,
-		Findings: findings,
+		SchemaVersion: SchemaVersion,
+		Tool:          tool{Name: ToolName, Version: f.toolVersion},
+		Summary:       summary,
+		Findings:      result.Findings,
+		Warnings:      warnings,
+		Added:         result.ResourcesAdded,
+		Removed:       result.ResourcesRemoved,
+		Exceptions:    result.ExceptionsApplied,
+		PSSLevel:      result.PSSLevel,
+		PSSVerdicts:   result.PSSVerdicts,
+	}
+
+	if f.showStats {
+		output.Stats = &result.Stats
 	}
 
 	encoder := json.NewEncoder(f.writer)
@@ -49,39 +144,233 @@ func (f *Formatter) outputJSON(findings []types.Finding, summary types.Summary)
 	return encoder.Encode(output)
 }
 
-// outputHuman outputs findings in human-readable format
-func (f *Formatter) outputHuman(findings []types.Finding, summary types.Summary) error {
+// outputHuman outputs findings in human-readable format, grouped under the
+// resource they are about
+func (f *Formatter) outputHuman(result types.ScanResult, summary types.Summary) error {
+	findings := result.Findings
 	if len(findings) == 0 {
 		fmt.Fprintln(f.writer, "No security issues found.")
+		f.outputHumanChanges(result)
+		f.outputHumanPSS(result)
+		f.outputHumanStats(result.Stats)
 		return nil
 	}
 
-	// Print each finding
-	for i, finding := range findings {
-		if i > 0 {
-			fmt.Fprintln(f.writer, "")
+	var controls []controlGroup
+	if f.compliance == types.ComplianceCIS {
+		// Print the findings under each benchmark control they fail
+		controls = groupByControl(findings)
+		for i, group := range controls {
+			if i > 0 {
+				fmt.Fprintln(f.writer, "")
+			}
+			heading := group.name()
+			if group.Title != "" {
+				heading += ": " + group.Title
+			}
+			fmt.Fprintln(f.writer, f.paint(ansiBold, heading))
+			for _, finding := range group.Findings {
+				f.writeHumanFinding(finding, true)
+			}
 		}
+	} else {
+		// Print the findings under the resource they are about
+		for i, group := range groupByResource(findings) {
+			if i > 0 {
+				fmt.Fprintln(f.writer, "")
+			}
 
-		fmt.Fprintf(f.writer, "%s RISK\n", finding.Severity)
-		fmt.Fprintf(f.writer, "Resource: %s/%s\n", finding.Kind, finding.Name)
-		if finding.Namespace != "" {
-			fmt.Fprintf(f.writer, "Namespace: %s\n", finding.Namespace)
+			first := group[0]
+			fmt.Fprintln(f.writer, f.paint(ansiBold, "Resource: "+first.Kind+"/"+first.Name))
+			if first.Namespace != "" {
+				fmt.Fprintf(f.writer, "Namespace: %s\n", first.Namespace)
+			}
+			for _, finding := range group {
+				f.writeHumanFinding(finding, false)
+			}
 		}
-		fmt.Fprintf(f.writer, "Rule: %s\n", finding.RuleID)
-		fmt.Fprintf(f.writer, "Reason: %s\n", finding.Reason)
-		fmt.Fprintf(f.writer, "Impact: %s\n", finding.Impact)
-		fmt.Fprintf(f.writer, "Fix: %s\n", finding.Fix)
 	}
 
+	f.outputHumanChanges(result)
+	f.outputHumanPSS(result)
+
 	// Print summary
 	fmt.Fprintln(f.writer, "")
-	fmt.Fprintln(f.writer, "SUMMARY")
-	fmt.Fprintf(f.writer, "High risk: %d\n", summary.High)
-	fmt.Fprintf(f.writer, "Medium risk: %d\n", summary.Medium)
+	fmt.Fprintln(f.writer, f.paint(ansiBold, "SUMMARY"))
+	if summary.Critical > 0 {
+		fmt.Fprintln(f.writer, f.paint(ansiMagenta, fmt.Sprintf("Critical risk: %d", summary.Critical)))
+	}
+	fmt.Fprintln(f.writer, f.paintIf(summary.High > 0, ansiRed, fmt.Sprintf("High risk: %d", summary.High)))
+	fmt.Fprintln(f.writer, f.paintIf(summary.Medium > 0, ansiYellow, fmt.Sprintf("Medium risk: %d", summary.Medium)))
+	if summary.Low > 0 {
+		fmt.Fprintf(f.writer, "Low risk: %d\n", summary.Low)
+	}
 	fmt.Fprintf(f.writer, "Resources affected: %d\n", summary.ResourcesAffected)
 	if summary.NamespacesAffected > 0 {
 		fmt.Fprintf(f.writer, "Namespaces affected: %d\n", summary.NamespacesAffected)
 	}
+	if f.compliance == types.ComplianceCIS {
+		fmt.Fprintf(f.writer, "CIS controls affected: %d\n", mappedControls(controls))
+	}
+	if summary.Suppressed > 0 {
+		fmt.Fprintf(f.writer, "Suppressed: %d\n", summary.Suppressed)
+	}
+	if summary.Baselined > 0 {
+		fmt.Fprintf(f.writer, "In baseline (not shown): %d\n", summary.Baselined)
+	}
+	if summary.Excepted > 0 {
+		fmt.Fprintf(f.writer, "Excepted (not shown): %d\n", summary.Excepted)
+	}
+	if summary.Resolved > 0 {
+		fmt.Fprintln(f.writer, f.paint(ansiGreen, fmt.Sprintf("Resolved: %d", summary.Resolved)))
+	}
+	if summary.Unchanged > 0 {
+		fmt.Fprintf(f.writer, "Unchanged (not shown): %d\n", summary.Unchanged)
+	}
+	if summary.Warnings > 0 {
+		fmt.Fprintf(f.writer, "Warnings: %d\n", summary.Warnings)
+	}
 
+	f.outputHumanStats(result.Stats)
 	return nil
 }
+
+// writeHumanFinding prints one finding indented under its group heading.
+// withResource names the resource too, for groupings other than by resource.
+func (f *Formatter) writeHumanFinding(finding types.Finding, withResource bool) {
+	fmt.Fprintln(f.writer, "")
+	heading := string(finding.Severity) + " RISK"
+	if finding.Suppressed {
+		heading += " (suppressed)"
+	}
+	if finding.Status == types.StatusResolved {
+		heading = "RESOLVED " + heading
+	}
+	fmt.Fprintf(f.writer, "  %s %s\n", f.paint(severityColor(finding), heading), finding.RuleID)
+	if withResource {
+		fmt.Fprintf(f.writer, "  Resource: %s\n", resourceName(finding))
+	}
+	if finding.Container != "" {
+		fmt.Fprintf(f.writer, "  Container: %s\n", finding.Container)
+	}
+	if finding.File != "" {
+		fmt.Fprintf(f.writer, "  File: %s\n", location(finding))
+	}
+	fmt.Fprintf(f.writer, "  Reason: %s\n", finding.Reason)
+	fmt.Fprintf(f.writer, "  Impact: %s\n", finding.Impact)
+	fmt.Fprintf(f.writer, "  Fix: %s\n", finding.Fix)
+	if refs := formatReferences(finding); refs != "" {
+		fmt.Fprintf(f.writer, "  References: %s\n", refs)
+	}
+	if finding.Snippet != "" {
+		fmt.Fprintf(f.writer, "  Snippet (%s):\n", finding.Path)
+		fmt.Fprintf(f.writer, "      %s\n", strings.ReplaceAll(finding.Snippet, "\n", "\n      "))
+	}
+}
+
+// groupByResource splits findings by the resource they name, in the order
+// each resource first appears
+func groupByResource(findings []types.Finding) [][]types.Finding {
+	index := make(map[string]int)
+	var groups [][]types.Finding
+	for _, finding := range findings {
+		key := finding.Kind + "|" + finding.Namespace + "|" + finding.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], finding)
+	}
+	return groups
+}
+
+// location returns a finding's file with its line and column, in the
+// file:line:column form editors and terminals turn into links
+func location(finding types.Finding) string {
+	if finding.Line == 0 {
+		return finding.File
+	}
+	return fmt.Sprintf("%s:%d:%d", finding.File, finding.Line, finding.Column)
+}
+
+// formatReferences joins a finding's CIS control and other references
+func formatReferences(finding types.Finding) string {
+	var refs []string
+	if finding.CISControl != "" {
+		refs = append(refs, "CIS "+finding.CISControl)
+	}
+	refs = append(refs, finding.References...)
+	return strings.Join(refs, ", ")
+}
+
+// outputHumanChanges lists the resources a diff added or removed
+func (f *Formatter) outputHumanChanges(result types.ScanResult) {
+	sections := []struct {
+		title     string
+		resources []types.ResourceRef
+	}{
+		{"RESOURCES ADDED", result.ResourcesAdded},
+		{"RESOURCES REMOVED", result.ResourcesRemoved},
+	}
+
+	for _, section := range sections {
+		if len(section.resources) == 0 {
+			continue
+		}
+		fmt.Fprintln(f.writer, "")
+		fmt.Fprintln(f.writer, f.paint(ansiBold, section.title))
+		for _, r := range section.resources {
+			if r.Namespace != "" {
+				fmt.Fprintf(f.writer, "%s/%s (%s)\n", r.Kind, r.Name, r.Namespace)
+			} else {
+				fmt.Fprintf(f.writer, "%s/%s\n", r.Kind, r.Name)
+			}
+		}
+	}
+}
+
+// outputHumanPSS lists the workloads that fail the evaluated Pod Security
+// Standards level, and how many pass, when a level was evaluated
+func (f *Formatter) outputHumanPSS(result types.ScanResult) {
+	if result.PSSLevel == "" {
+		return
+	}
+
+	fmt.Fprintln(f.writer, "")
+	fmt.Fprintln(f.writer, f.paint(ansiBold, fmt.Sprintf("POD SECURITY STANDARDS (%s)", result.PSSLevel)))
+	passed := 0
+	for _, verdict := range result.PSSVerdicts {
+		if verdict.Violates == "" {
+			passed++
+			continue
+		}
+		name := verdict.Kind + "/" + verdict.Name
+		if verdict.Namespace != "" {
+			name += " (" + verdict.Namespace + ")"
+		}
+		style := ansiYellow
+		if verdict.Violates == types.PSSBaseline {
+			style = ansiRed
+		}
+		fmt.Fprintf(f.writer, "%s: %s (%s)\n", name, f.paint(style, "violates "+string(verdict.Violates)),
+			strings.Join(verdict.Controls, ", "))
+	}
+	fmt.Fprintf(f.writer, "%d of %d workload(s) pass %s\n", passed, len(result.PSSVerdicts), result.PSSLevel)
+}
+
+// outputHumanStats prints the scan statistics footer when enabled
+func (f *Formatter) outputHumanStats(stats types.Stats) {
+	if !f.showStats {
+		return
+	}
+
+	fmt.Fprintln(f.writer, "")
+	fmt.Fprintln(f.writer, f.paint(ansiBold, "STATS"))
+	fmt.Fprintf(f.writer, "Files parsed: %d\n", stats.FilesParsed)
+	fmt.Fprintf(f.writer, "Resources scanned: %d\n", stats.ResourcesScanned)
+	fmt.Fprintf(f.writer, "Resources skipped: %d\n", stats.ResourcesSkipped)
+	fmt.Fprintf(f.writer, "Rules run: %d (%d executions)\n", stats.RulesRun, stats.RuleExecutions)
+	fmt.Fprintf(f.writer, "Elapsed: %dms\n", stats.ElapsedMillis)
+}
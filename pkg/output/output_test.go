@@ -0,0 +1,128 @@
+package output
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// update rewrites the golden files from the current output:
+//
+//	go test ./pkg/output -update
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// goldenResult is a scan result exercising each part of a report: findings
+// of several severities, a container finding with a position, a warning and
+// a PSS verdict
+func goldenResult() (types.ScanResult, types.Summary) {
+	result := types.ScanResult{
+		Findings: []types.Finding{
+			{
+				RuleID:      "privileged-container",
+				Severity:    types.Critical,
+				Category:    types.CategorySecurity,
+				Kind:        "Deployment",
+				Name:        "api",
+				Namespace:   "shop",
+				Container:   "app",
+				Reason:      "Container app runs in privileged mode",
+				Impact:      "Full access to the host, including all devices",
+				Fix:         "Set securityContext.privileged to false",
+				File:        "manifests/api.yaml",
+				Path:        "spec.template.spec.containers[0]",
+				Line:        14,
+				Column:      9,
+				CISControl:  "5.2.2",
+				Fingerprint: "4f1d2c",
+				References:  []string{"NSA/CISA Pod security"},
+			},
+			{
+				RuleID:      "hostpath-volume",
+				Severity:    types.High,
+				Category:    types.CategorySecurity,
+				Kind:        "Deployment",
+				Name:        "api",
+				Namespace:   "shop",
+				Reason:      "Uses hostPath volume mount",
+				Impact:      "Direct filesystem access enables container escape",
+				Fix:         "Use PersistentVolumes or emptyDir instead",
+				File:        "manifests/api.yaml",
+				Path:        "spec.template.spec.volumes[0]",
+				Line:        20,
+				Column:      9,
+				Fingerprint: "9a07be",
+			},
+			{
+				RuleID:      "default-namespace",
+				Severity:    types.Medium,
+				Category:    types.CategoryGovernance,
+				Kind:        "Service",
+				Name:        "web",
+				Reason:      "Resource has no namespace",
+				Impact:      "Complicates RBAC, quotas and policy targeting",
+				Fix:         "Set metadata.namespace",
+				File:        "manifests/web.yaml",
+				Fingerprint: "c3e851",
+			},
+		},
+		Warnings: []types.Warning{{Path: "manifests/broken.yaml", Message: "yaml: line 3: did not find expected key"}},
+		PSSLevel: types.PSSRestricted,
+		PSSVerdicts: []types.PSSVerdict{{
+			Kind:      "Deployment",
+			Name:      "api",
+			Namespace: "shop",
+			File:      "manifests/api.yaml",
+			Violates:  types.PSSBaseline,
+			Controls:  []string{"privileged-container"},
+		}},
+	}
+	summary := types.Summary{
+		Critical:           1,
+		High:               1,
+		Medium:             1,
+		ResourcesAffected:  2,
+		NamespacesAffected: 2,
+		Warnings:           1,
+	}
+	return result, summary
+}
+
+func TestRenderGolden(t *testing.T) {
+	formats := []types.OutputFormat{
+		types.FormatHuman,
+		types.FormatTable,
+		types.FormatJSON,
+		types.FormatSARIF,
+		types.FormatMarkdown,
+		types.FormatCSV,
+		types.FormatHTML,
+	}
+
+	result, summary := goldenResult()
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			got, err := RenderToString(format, result, summary)
+			if err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+
+			golden := filepath.Join("testdata", string(format)+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read %s (run with -update to create it): %v", golden, err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s; rerun with -update if the change is intended\n--- got\n%s", golden, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package output
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// sarifSchema and sarifVersion identify the SARIF revision written, the one
+// GitHub code scanning accepts
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// informationURI is reported as the tool's home page in SARIF output
+const informationURI = "https://github.com/palthisailohith/k8s-danger-scan"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string          `json:"id"`
+	ShortDescription     sarifText       `json:"shortDescription"`
+	FullDescription      *sarifText      `json:"fullDescription,omitempty"`
+	Help                 *sarifText      `json:"help,omitempty"`
+	DefaultConfiguration sarifLevel      `json:"defaultConfiguration"`
+	Properties           sarifProperties `json:"properties"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifLevel struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Tags             []string `json:"tags,omitempty"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifText          `json:"message"`
+	Locations           []sarifLocation    `json:"locations,omitempty"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	BaselineState       string             `json:"baselineState,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevels maps severities onto SARIF result levels
+var sarifLevels = map[types.Severity]string{
+	types.Critical: "error",
+	types.High:     "error",
+	types.Medium:   "warning",
+	types.Low:      "note",
+}
+
+// securitySeverities are the CVSS-style scores GitHub uses to rank
+// security alerts as critical (9.0+), high (7.0+), medium (4.0+) or low
+var securitySeverities = map[types.Severity]string{
+	types.Critical: "9.5",
+	types.High:     "8.0",
+	types.Medium:   "5.5",
+	types.Low:      "3.0",
+}
+
+// outputSARIF writes findings as a SARIF 2.1.0 log for GitHub code scanning.
+// Each rule that fired is described once in the driver; results refer to it
+// by index and carry the finding fingerprint so alerts track across runs.
+func (f *Formatter) outputSARIF(result types.ScanResult) error {
+	driver := sarifDriver{
+		Name:           ToolName,
+		Version:        f.toolVersion,
+		InformationURI: informationURI,
+		Rules:          []sarifRule{},
+	}
+	ruleIndex := make(map[string]int)
+	results := []sarifResult{}
+
+	for _, finding := range result.Findings {
+		index, ok := ruleIndex[finding.RuleID]
+		if !ok {
+			index = len(driver.Rules)
+			ruleIndex[finding.RuleID] = index
+			driver.Rules = append(driver.Rules, sarifRuleFor(finding))
+		}
+
+		res := sarifResult{
+			RuleID:    finding.RuleID,
+			RuleIndex: index,
+			Level:     sarifLevels[finding.Severity],
+			Message:   sarifText{Text: sarifMessage(finding)},
+		}
+
+		location := sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{
+				FullyQualifiedName: resourceName(finding),
+				Kind:               "resource",
+			}},
+		}
+		if finding.File != "" {
+			// Without a known position, results point at the top of the file
+			region := sarifRegion{StartLine: 1}
+			if finding.Line > 0 {
+				region = sarifRegion{StartLine: finding.Line, StartColumn: finding.Column}
+			}
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(finding.File)},
+				Region:           region,
+			}
+		}
+		res.Locations = []sarifLocation{location}
+
+		if finding.Fingerprint != "" {
+			res.PartialFingerprints = map[string]string{"findingFingerprint/v1": finding.Fingerprint}
+		}
+		if finding.Suppressed {
+			res.Suppressions = []sarifSuppression{{
+				Kind:          "inSource",
+				Justification: "Suppressed by the " + types.IgnoreAnnotation + " annotation",
+			}}
+		}
+		// Diffs mark findings as new, or absent once resolved
+		switch finding.Status {
+		case types.StatusNew:
+			res.BaselineState = "new"
+		case types.StatusResolved:
+			res.BaselineState = "absent"
+		}
+		results = append(results, res)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+
+	encoder := json.NewEncoder(f.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifRuleFor describes the rule behind a finding, using its explanation
+// when one exists and the finding's own text otherwise
+func sarifRuleFor(finding types.Finding) sarifRule {
+	rule := sarifRule{
+		ID:                   finding.RuleID,
+		ShortDescription:     sarifText{Text: finding.Reason},
+		DefaultConfiguration: sarifLevel{Level: sarifLevels[finding.Severity]},
+		Properties: sarifProperties{
+			Tags:             []string{"security", "kubernetes"},
+			SecuritySeverity: securitySeverities[finding.Severity],
+		},
+	}
+	// GitHub only ranks results by security-severity when tagged "security"
+	if finding.Category != "" && finding.Category != types.CategorySecurity {
+		rule.Properties.Tags = append(rule.Properties.Tags, string(finding.Category))
+	}
+
+	if e, ok := rules.Explain(finding.RuleID); ok {
+		rule.ShortDescription = sarifText{Text: e.Title}
+		rule.FullDescription = &sarifText{Text: e.Description}
+		rule.Help = &sarifText{Text: e.Why}
+	}
+	return rule
+}
+
+// sarifMessage is the result text shown on the alert
+func sarifMessage(finding types.Finding) string {
+	subject := resourceName(finding)
+	if finding.Container != "" {
+		subject += " container " + finding.Container
+	}
+	parts := []string{subject + ": " + finding.Reason}
+	if finding.Impact != "" {
+		parts = append(parts, "Impact: "+finding.Impact)
+	}
+	if finding.Fix != "" {
+		parts = append(parts, "Fix: "+finding.Fix)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// resourceName returns Kind/namespace/name, or Kind/name without a namespace
+func resourceName(finding types.Finding) string {
+	if finding.Namespace == "" {
+		return finding.Kind + "/" + finding.Name
+	}
+	return finding.Kind + "/" + finding.Namespace + "/" + finding.Name
+}
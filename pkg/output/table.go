@@ -0,0 +1,178 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// ANSI escape sequences used for terminal output
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiRed     = "\033[31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiMagenta = "\033[35m"
+	ansiGrey    = "\033[90m"
+)
+
+// paint wraps text in an ANSI style when color is enabled
+func (f *Formatter) paint(style, text string) string {
+	if !f.color {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// paintIf paints text only when cond holds, e.g. to highlight nonzero counts
+func (f *Formatter) paintIf(cond bool, style, text string) string {
+	if !cond {
+		return text
+	}
+	return f.paint(style, text)
+}
+
+// severityColor returns the style a finding's severity is shown in.
+// Suppressed findings are greyed out and resolved ones green, whatever their
+// severity.
+func severityColor(finding types.Finding) string {
+	if finding.Suppressed {
+		return ansiGrey
+	}
+	if finding.Status == types.StatusResolved {
+		return ansiGreen
+	}
+	switch finding.Severity {
+	case types.Critical:
+		return ansiMagenta + ansiBold
+	case types.High:
+		return ansiRed + ansiBold
+	case types.Medium:
+		return ansiYellow
+	default:
+		return ansiGrey
+	}
+}
+
+// tableHeader names the columns of table output
+var tableHeader = []string{"SEVERITY", "RULE", "RESOURCE", "CONTAINER", "LOCATION"}
+
+// outputTable writes one aligned row per finding followed by a one-line
+// summary, for a quick overview of large scans
+func (f *Formatter) outputTable(result types.ScanResult, summary types.Summary) error {
+	if len(result.Findings) == 0 {
+		fmt.Fprintln(f.writer, "No security issues found.")
+		f.outputHumanChanges(result)
+		f.outputHumanPSS(result)
+		f.outputHumanStats(result.Stats)
+		return nil
+	}
+
+	// Under a compliance framework, rows are sorted by control and lead
+	// with it; a finding failing several controls gets a row for each
+	header, findings := tableHeader, result.Findings
+	var controls []string
+	if f.compliance == types.ComplianceCIS {
+		header = append([]string{"CIS"}, tableHeader...)
+		findings = nil
+		for _, group := range groupByControl(result.Findings) {
+			for _, finding := range group.Findings {
+				findings = append(findings, finding)
+				controls = append(controls, group.ID)
+			}
+		}
+	}
+
+	rows := make([][]string, 0, len(findings))
+	for i, finding := range findings {
+		severity := string(finding.Severity)
+		if finding.Suppressed {
+			severity += " (suppressed)"
+		}
+		if finding.Status == types.StatusResolved {
+			severity += " (resolved)"
+		}
+		row := []string{severity, finding.RuleID, resourceName(finding), finding.Container, location(finding)}
+		if controls != nil {
+			control := controls[i]
+			if control == "" {
+				control = "-"
+			}
+			row = append([]string{control}, row...)
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	// Pad before painting so escape sequences don't throw off alignment
+	writeRow := func(row []string, style func(column int, cell string) string) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i < len(row)-1 {
+				cell += strings.Repeat(" ", widths[i]-len(cell))
+			}
+			cells[i] = style(i, cell)
+		}
+		fmt.Fprintln(f.writer, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+
+	severityColumn := len(header) - len(tableHeader)
+	writeRow(header, func(_ int, cell string) string { return f.paint(ansiBold, cell) })
+	for i, row := range rows {
+		finding := findings[i]
+		writeRow(row, func(column int, cell string) string {
+			if column == severityColumn {
+				return f.paint(severityColor(finding), cell)
+			}
+			return cell
+		})
+	}
+
+	f.outputHumanChanges(result)
+	f.outputHumanPSS(result)
+	fmt.Fprintln(f.writer, "")
+	fmt.Fprintln(f.writer, tableSummary(summary))
+	f.outputHumanStats(result.Stats)
+	return nil
+}
+
+// tableSummary condenses the summary into one line
+func tableSummary(summary types.Summary) string {
+	var parts []string
+	if summary.Critical > 0 {
+		parts = append(parts, fmt.Sprintf("%d critical", summary.Critical))
+	}
+	parts = append(parts, fmt.Sprintf("%d high", summary.High), fmt.Sprintf("%d medium", summary.Medium))
+	if summary.Low > 0 {
+		parts = append(parts, fmt.Sprintf("%d low", summary.Low))
+	}
+
+	line := strings.Join(parts, ", ") + fmt.Sprintf(" across %d resource(s)", summary.ResourcesAffected)
+	if summary.Suppressed > 0 {
+		line += fmt.Sprintf(", %d suppressed", summary.Suppressed)
+	}
+	if summary.Baselined > 0 {
+		line += fmt.Sprintf(", %d more in the baseline", summary.Baselined)
+	}
+	if summary.Excepted > 0 {
+		line += fmt.Sprintf(", %d excepted", summary.Excepted)
+	}
+	if summary.Resolved > 0 {
+		line += fmt.Sprintf(", %d resolved", summary.Resolved)
+	}
+	if summary.Unchanged > 0 {
+		line += fmt.Sprintf(", %d unchanged", summary.Unchanged)
+	}
+	if summary.Warnings > 0 {
+		line += fmt.Sprintf(", %d warning(s)", summary.Warnings)
+	}
+	return line
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// Target is a requested output: a format and where to write it
+type Target struct {
+	Format types.OutputFormat
+	Path   string // Empty writes to stdout
+}
+
+// ParseFormat converts a case-insensitive format name to an OutputFormat
+func ParseFormat(name string) (types.OutputFormat, error) {
+	switch format := types.OutputFormat(strings.ToLower(name)); format {
+	case types.FormatHuman, types.FormatTable, types.FormatJSON, types.FormatCSV, types.FormatSARIF, types.FormatMarkdown, types.FormatHTML, types.FormatTemplate:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want human, table, json, csv, sarif, markdown, html or template)", name)
+	}
+}
+
+// ParseTarget parses a "format" or "format=path" output specification
+func ParseTarget(spec string) (Target, error) {
+	name, path, _ := strings.Cut(spec, "=")
+	format, err := ParseFormat(strings.TrimSpace(name))
+	if err != nil {
+		return Target{}, err
+	}
+	return Target{Format: format, Path: strings.TrimSpace(path)}, nil
+}
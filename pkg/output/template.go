@@ -0,0 +1,85 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// TemplateData is the context passed to user-supplied output templates
+type TemplateData struct {
+	Findings []types.Finding
+	Summary  types.Summary
+	Warnings []types.Warning
+	Stats    types.Stats
+	Version  string
+
+	// Set by diffs only
+	ResourcesAdded   []types.ResourceRef
+	ResourcesRemoved []types.ResourceRef
+}
+
+// TemplateFuncs returns the helper functions available to output templates
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"upper": func(v interface{}) string { return strings.ToUpper(fmt.Sprint(v)) },
+		"lower": func(v interface{}) string { return strings.ToLower(fmt.Sprint(v)) },
+		"join":  strings.Join,
+		"severityColor": func(severity types.Severity) string {
+			switch severity {
+			case types.Critical:
+				return "purple"
+			case types.High:
+				return "red"
+			case types.Medium:
+				return "yellow"
+			default:
+				return "grey"
+			}
+		},
+		"severityEmoji": severityEmoji,
+	}
+}
+
+// ParseTemplate loads and validates a Go text/template from path
+func ParseTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs()).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// WithTemplate renders output through tmpl instead of a built-in format
+func (f *Formatter) WithTemplate(tmpl *template.Template) *Formatter {
+	f.format = types.FormatTemplate
+	f.template = tmpl
+	return f
+}
+
+// outputTemplate executes the configured template against the result
+func (f *Formatter) outputTemplate(result types.ScanResult, summary types.Summary) error {
+	if f.template == nil {
+		return fmt.Errorf("no template configured")
+	}
+
+	return f.template.Execute(f.writer, TemplateData{
+		Findings: result.Findings,
+		Summary:  summary,
+		Warnings: result.Warnings,
+		Stats:    result.Stats,
+		Version:  f.toolVersion,
+
+		ResourcesAdded:   result.ResourcesAdded,
+		ResourcesRemoved: result.ResourcesRemoved,
+	})
+}
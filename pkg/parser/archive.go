@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// IsArchivePath reports whether a file is a tarball of manifests
+func IsArchivePath(p string) bool {
+	return strings.HasSuffix(p, ".tar.gz") || strings.HasSuffix(p, ".tgz") || strings.HasSuffix(p, ".tar")
+}
+
+// parseArchive reads manifests from a tar or gzipped tar archive entirely in
+// memory and adds them to result. Each resource's Source is set to
+// "<archive>:<entry path>". Entries that fail to parse are reported as warnings.
+func parseArchive(archivePath string, limits Limits, result *ParseResult) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	var reader io.Reader = f
+	if !strings.HasSuffix(archivePath, ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if !IsManifestPath(name) {
+			continue
+		}
+
+		source := archivePath + ":" + name
+
+		if err := limits.checkSize(header.Size); err != nil {
+			result.Warnings = append(result.Warnings, types.Warning{
+				Path:    source,
+				Message: fmt.Sprintf("failed to parse: %v", err),
+			})
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", source, err)
+		}
+
+		res, err := ParseSourceWithLimits(source, data, limits)
+		if err != nil {
+			result.Warnings = append(result.Warnings, types.Warning{
+				Path:    source,
+				Message: fmt.Sprintf("failed to parse: %v", err),
+			})
+			continue
+		}
+
+		result.Resources = append(result.Resources, res...)
+		result.FilesParsed++
+	}
+
+	return nil
+}
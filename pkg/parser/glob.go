@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// hasGlobMeta reports whether a path argument is a glob pattern
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchGlob reports whether a slash-separated path matches pattern. A "**"
+// segment matches any number of directories, including none; other segments
+// use path.Match syntax.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// validateGlob returns an error if any segment of pattern is malformed
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// expandGlob returns the manifests, archives and directories matching
+// pattern, in walk order. A matching directory is returned whole rather than
+// descended into.
+func expandGlob(pattern string) ([]string, error) {
+	// Walked paths come back cleaned, so clean the pattern to match them
+	cleaned := path.Clean(filepath.ToSlash(pattern))
+	if err := validateGlob(cleaned); err != nil {
+		return nil, err
+	}
+
+	// Walk from the longest directory prefix without wildcards
+	segments := strings.Split(cleaned, "/")
+	static := 0
+	for static < len(segments)-1 && !hasGlobMeta(segments[static]) {
+		static++
+	}
+	root := strings.Join(segments[:static], "/")
+	switch {
+	case root == "" && static > 0:
+		root = "/"
+	case root == "":
+		root = "."
+	}
+
+	var matches []string
+	err := filepath.Walk(filepath.FromSlash(root), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !matchGlob(cleaned, filepath.ToSlash(p)) {
+			return nil
+		}
+		if !info.IsDir() && !IsManifestPath(p) && !IsArchivePath(p) {
+			return nil
+		}
+		matches = append(matches, p)
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to expand %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %s", pattern)
+	}
+	return matches, nil
+}
+
+// excluded reports whether p matches one of the exclude patterns, either as
+// given or relative to the scan root it was found under
+func (opts ParseOptions) excluded(root, p string) bool {
+	if len(opts.Exclude) == 0 {
+		return false
+	}
+	names := []string{filepath.ToSlash(filepath.Clean(p))}
+	if rel, err := filepath.Rel(root, p); err == nil && rel != "." {
+		names = append(names, filepath.ToSlash(rel))
+	}
+	for _, pattern := range opts.Exclude {
+		for _, name := range names {
+			if matchGlob(path.Clean(pattern), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// ErrHelmNotFound is returned when a chart needs rendering but helm is not
+// installed
+var ErrHelmNotFound = errors.New("helm not found in PATH (install helm, or render the chart with helm template and scan the output)")
+
+// helmSourceComment matches the "# Source: chart/templates/x.yaml" line helm
+// template writes above each rendered document
+var helmSourceComment = regexp.MustCompile(`(?m)^# Source: (.+?)\s*$`)
+
+// helmDocumentSeparator splits helm template output into documents
+var helmDocumentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// IsHelmChart reports whether dir contains a Chart.yaml
+func IsHelmChart(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "Chart.yaml"))
+	return err == nil
+}
+
+// renderHelmChart runs `helm template` on the chart in dir with the given
+// values files and returns the rendered manifests
+func renderHelmChart(ctx context.Context, dir string, values []string) ([]byte, error) {
+	bin, err := exec.LookPath("helm")
+	if err != nil {
+		return nil, ErrHelmNotFound
+	}
+
+	args := []string{"template", dir}
+	for _, v := range values {
+		args = append(args, "--values", v)
+	}
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("helm template failed: %s", msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseHelmChart renders the chart in dir and adds its resources to result.
+// Each resource is attributed to the template that produced it, so findings
+// point at a file under templates/. Render failures are recorded as
+// warnings; a missing helm binary is returned as an error.
+func parseHelmChart(ctx context.Context, dir string, values []string, limits Limits, result *ParseResult) error {
+	data, err := renderHelmChart(ctx, dir, values)
+	if errors.Is(err, ErrHelmNotFound) || ctx.Err() != nil {
+		return err
+	}
+	if err != nil {
+		result.Warnings = append(result.Warnings, types.Warning{
+			Path:    dir,
+			Message: err.Error(),
+		})
+		return nil
+	}
+	if err := limits.checkSize(int64(len(data))); err != nil {
+		result.Warnings = append(result.Warnings, types.Warning{
+			Path:    dir,
+			Message: fmt.Sprintf("failed to parse helm output: %v", err),
+		})
+		return nil
+	}
+
+	for _, doc := range helmDocumentSeparator.Split(string(data), -1) {
+		source := dir
+		if m := helmSourceComment.FindStringSubmatch(doc); m != nil {
+			// Paths start with the chart's name, which need not match dir
+			if _, rest, ok := strings.Cut(m[1], "/"); ok {
+				source = filepath.Join(dir, filepath.FromSlash(rest))
+			}
+		}
+
+		res, err := ParseSourceWithLimits(source, []byte(doc), limits)
+		if err != nil {
+			result.Warnings = append(result.Warnings, types.Warning{
+				Path:    source,
+				Message: fmt.Sprintf("failed to parse helm output: %v", err),
+			})
+			continue
+		}
+		result.Resources = append(result.Resources, withoutPositions(res)...)
+	}
+	result.FilesParsed++
+	return nil
+}
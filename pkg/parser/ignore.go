@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the file, in any walked directory, listing paths under
+// that directory to leave out of a scan, in .gitignore syntax
+const IgnoreFileName = ".dangerscanignore"
+
+// ignorePattern is one line of an ignore file
+type ignorePattern struct {
+	glob    string // Slash-separated, relative to the ignore file's directory
+	negate  bool   // A "!" line, re-including what earlier lines ignored
+	dirOnly bool   // A trailing "/", matching directories only
+}
+
+// ignoreFiles holds the ignore files read so far in a walk, keyed by the
+// directory they were found in
+type ignoreFiles map[string][]ignorePattern
+
+// load reads the ignore file in dir, if there is one
+func (files ignoreFiles) load(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, IgnoreFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Join(dir, IgnoreFileName), err)
+	}
+
+	var patterns []ignorePattern
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pattern ignorePattern
+		if strings.HasPrefix(line, "!") {
+			pattern.negate = true
+			line = line[1:]
+		}
+		// A backslash escapes a leading "#" or "!"
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			pattern.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// As in .gitignore, a pattern with no slash other than a trailing
+		// one matches at any depth; anything else is relative to the file
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		if validateGlob(line) != nil {
+			continue
+		}
+		pattern.glob = line
+		patterns = append(patterns, pattern)
+	}
+	files[dir] = patterns
+	return nil
+}
+
+// ignored reports whether p, found while walking root, is ignored by the
+// ignore files in root or the directories between root and p. Later lines,
+// and files deeper in the tree, take precedence.
+func (files ignoreFiles) ignored(root, p string, isDir bool) bool {
+	var dirs []string
+	for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	ignored := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(dirs[i], p)
+		if err != nil {
+			continue
+		}
+		for _, pattern := range files[dirs[i]] {
+			if pattern.dirOnly && !isDir {
+				continue
+			}
+			if matchGlob(pattern.glob, filepath.ToSlash(rel)) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrKustomizeNotFound is returned when a kustomization needs rendering but
+// neither kustomize nor kubectl is installed
+var ErrKustomizeNotFound = errors.New("kustomize not found in PATH (install kustomize or kubectl, or pass --raw to scan files individually)")
+
+// kustomizationFiles are the file names kustomize recognizes as a kustomization root
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// IsKustomization reports whether dir contains a kustomization file
+func IsKustomization(dir string) bool {
+	for _, name := range kustomizationFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// renderKustomization runs `kustomize build` on dir and returns the rendered
+// manifests. `kubectl kustomize` is used as a fallback when the standalone
+// binary isn't installed.
+func renderKustomization(ctx context.Context, dir string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if bin, err := exec.LookPath("kustomize"); err == nil {
+		cmd = exec.CommandContext(ctx, bin, "build", dir)
+	} else if bin, err := exec.LookPath("kubectl"); err == nil {
+		cmd = exec.CommandContext(ctx, bin, "kustomize", dir)
+	} else {
+		return nil, ErrKustomizeNotFound
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("kustomize build failed: %s", msg)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// kustomizeTree lays out an overlay, with a patch that only makes sense once
+// rendered, next to a plain manifest, and returns the tree's root
+func kustomizeTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	overlay := filepath.Join(root, "overlays", "prod")
+	if err := os.MkdirAll(overlay, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, overlay, "kustomization.yaml", "namePrefix: prod-\nresources:\n- ../../base\npatches:\n- path: replicas.yaml\n")
+	writeFile(t, overlay, "replicas.yaml", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\nspec:\n  replicas: 3\n")
+	writeFile(t, root, "standalone.yaml", "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n")
+	return root
+}
+
+// fakeKustomize puts a kustomize on the PATH that renders any directory to
+// one Deployment, and nothing else
+func fakeKustomize(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = build ] && [ -f "$2/kustomization.yaml" ] || { echo "unexpected arguments: $*" >&2; exit 1; }
+cat <<'YAML'
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-api
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: api:1.4
+YAML
+`
+	if err := os.WriteFile(filepath.Join(bin, "kustomize"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// names lists the kind/name of each resource, sorted
+func names(resources []K8sResource) []string {
+	var list []string
+	for _, r := range resources {
+		list = append(list, r.Kind+"/"+r.Metadata.Name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func TestParseFilesRendersKustomization(t *testing.T) {
+	root := kustomizeTree(t)
+	fakeKustomize(t)
+
+	result, err := ParseFiles(root)
+	if err != nil {
+		t.Fatalf("ParseFiles failed: %v", err)
+	}
+	if len(result.Warnings) > 0 {
+		t.Fatalf("unexpected warnings: %+v", result.Warnings)
+	}
+
+	got := names(result.Resources)
+	if want := []string{"Deployment/prod-api", "Service/web"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got resources %v, want %v", got, want)
+	}
+	for _, r := range result.Resources {
+		if r.Kind != "Deployment" {
+			continue
+		}
+		if r.Source != filepath.Join(root, "overlays", "prod") {
+			t.Errorf("rendered resource has source %q, want the kustomization directory", r.Source)
+		}
+		if r.Line != 0 {
+			t.Errorf("rendered resource has line %d, want none", r.Line)
+		}
+	}
+}
+
+func TestParseFilesRawKustomization(t *testing.T) {
+	root := kustomizeTree(t)
+	t.Setenv("PATH", t.TempDir())
+
+	result, err := ParseFilesWithOptions(ParseOptions{Raw: true}, root)
+	if err != nil {
+		t.Fatalf("ParseFiles failed: %v", err)
+	}
+	got := names(result.Resources)
+	if want := []string{"Deployment/api", "Service/web"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got resources %v, want the loose files %v", got, want)
+	}
+}
+
+func TestParseFilesKustomizeNotFound(t *testing.T) {
+	root := kustomizeTree(t)
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := ParseFiles(root); !errors.Is(err, ErrKustomizeNotFound) {
+		t.Errorf("got error %v, want ErrKustomizeNotFound", err)
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default parse limits. They are far above anything a real manifest needs
+// and only exist so a hostile file can't exhaust memory or the stack.
+const (
+	DefaultMaxFileBytes = 32 << 20 // 32 MiB
+	DefaultMaxDepth     = 100
+)
+
+// Limits bounds the input a single file may feed the parser. Zero fields take
+// the defaults; negative fields disable that limit.
+type Limits struct {
+	MaxFileBytes int64 // Largest file, archive entry or kustomize output parsed
+	MaxDepth     int   // Deepest nesting of maps and lists within a document
+}
+
+// withDefaults fills unset limits with the defaults
+func (l Limits) withDefaults() Limits {
+	if l.MaxFileBytes == 0 {
+		l.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if l.MaxDepth == 0 {
+		l.MaxDepth = DefaultMaxDepth
+	}
+	return l
+}
+
+// checkSize returns an error if size exceeds the file size limit
+func (l Limits) checkSize(size int64) error {
+	if l.MaxFileBytes > 0 && size > l.MaxFileBytes {
+		return fmt.Errorf("file is %d bytes, over the %d byte limit", size, l.MaxFileBytes)
+	}
+	return nil
+}
+
+// checkDepth returns an error if a document nests deeper than the limit.
+// Aliases are not followed; yaml.v3 already rejects excessive alias
+// expansion when decoding.
+func (l Limits) checkDepth(node *yaml.Node) error {
+	if l.MaxDepth > 0 && nodeDepth(node, l.MaxDepth+1) > l.MaxDepth {
+		return fmt.Errorf("document nests deeper than %d levels", l.MaxDepth)
+	}
+	return nil
+}
+
+// nodeDepth returns the nesting depth of maps and lists under node, counting
+// no further than limit
+func nodeDepth(node *yaml.Node, limit int) int {
+	depth := 0
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		depth = 1
+	}
+	if depth >= limit {
+		return depth
+	}
+
+	deepest := 0
+	for _, child := range node.Content {
+		if d := nodeDepth(child, limit-depth); d > deepest {
+			deepest = d
+		}
+	}
+	return depth + deepest
+}
@@ -2,12 +2,18 @@ package parser
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,10 +23,16 @@ type K8sResource struct {
 	Kind       string                 `yaml:"kind"`
 	Metadata   Metadata               `yaml:"metadata"`
 	Spec       map[string]interface{} `yaml:"spec"`
-	Rules      []Rule                 `yaml:"rules,omitempty"`      // For Role/ClusterRole
-	RoleRef    *RoleRef               `yaml:"roleRef,omitempty"`    // For RoleBinding/ClusterRoleBinding
-	Subjects   []Subject              `yaml:"subjects,omitempty"`   // For RoleBinding/ClusterRoleBinding
+	Rules      []Rule                 `yaml:"rules,omitempty"`    // For Role/ClusterRole
+	RoleRef    *RoleRef               `yaml:"roleRef,omitempty"`  // For RoleBinding/ClusterRoleBinding
+	Subjects   []Subject              `yaml:"subjects,omitempty"` // For RoleBinding/ClusterRoleBinding
 	Raw        map[string]interface{} // Full raw resource
+	Source     string                 `yaml:"-"` // File (or archive entry) the resource was read from
+	Line       int                    `yaml:"-"` // Line of the resource in Source, from 1; 0 if unknown
+	Column     int                    `yaml:"-"` // Column of the resource in Source, from 1; 0 if unknown
+
+	// node is the parsed document, kept to locate fields within it
+	node *yaml.Node
 }
 
 type Metadata struct {
@@ -48,93 +60,527 @@ type Subject struct {
 	Namespace string `yaml:"namespace,omitempty"`
 }
 
-// ParseFiles parses one or more YAML files
-func ParseFiles(paths ...string) ([]K8sResource, error) {
-	var resources []K8sResource
+// ParseResult holds the resources parsed from a set of paths along with any
+// non-fatal warnings raised while walking them
+type ParseResult struct {
+	Resources   []K8sResource
+	Warnings    []types.Warning
+	FilesParsed int
+}
+
+// ParseOptions controls how paths are expanded into resources
+type ParseOptions struct {
+	// Raw walks kustomization directories file by file instead of rendering
+	// them with kustomize
+	Raw bool
+
+	// Helm renders directories containing a Chart.yaml with helm template,
+	// using HelmValues as --values files, instead of walking the templates
+	Helm       bool
+	HelmValues []string
+
+	// SkipNonManifests silently skips file arguments that are not .yaml,
+	// .yml or .json files instead of trying to parse them, so a list of
+	// changed files from a git hook can be passed through unfiltered
+	SkipNonManifests bool
+
+	// Limits bounds file size and nesting depth; the zero value applies
+	// DefaultMaxFileBytes and DefaultMaxDepth
+	Limits Limits
+
+	// Exclude lists glob patterns for paths to leave out, matched against
+	// each path as found and relative to the argument it was found under.
+	// "**" matches any number of directories.
+	Exclude []string
+
+	// Concurrency is the number of inputs parsed at once; zero or less uses
+	// one worker per CPU. Results keep the order of the inputs either way.
+	Concurrency int
+
+	// Progress, if set, is called after each input (a file, archive or
+	// kustomization) is parsed with the number done so far and the total
+	Progress func(done, total int)
+
+	// Logger, if set, receives debug messages explaining which paths were
+	// skipped and why, and what each input yielded. Failures are returned
+	// as warnings either way; the parser never writes to stderr itself.
+	Logger *slog.Logger
+}
+
+// debug logs a debug message to opts.Logger, if set
+func (opts ParseOptions) debug(msg string, args ...any) {
+	if opts.Logger != nil {
+		opts.Logger.Debug(msg, args...)
+	}
+}
+
+// ParseFiles parses one or more YAML files, directories or manifest tarballs
+// using the default options.
+func ParseFiles(paths ...string) (ParseResult, error) {
+	return ParseFilesWithOptions(ParseOptions{}, paths...)
+}
+
+// ParseFilesWithOptions parses one or more YAML files, directories or manifest
+// tarballs. Directories containing a kustomization are rendered with
+// kustomize unless opts.Raw is set, and with opts.Helm, chart directories are
+// rendered with helm. Files that fail to parse are recorded as warnings
+// rather than aborting the whole run; only unreadable paths, archives and a
+// missing kustomize or helm binary return an error.
+func ParseFilesWithOptions(opts ParseOptions, paths ...string) (ParseResult, error) {
+	return ParseFilesContext(context.Background(), opts, paths...)
+}
+
+// ParseFilesContext is ParseFilesWithOptions with a context. Once ctx is
+// done, no further inputs are parsed, running kustomize and helm processes
+// are killed, and ctx's error is returned.
+func ParseFilesContext(ctx context.Context, opts ParseOptions, paths ...string) (ParseResult, error) {
+	limits := opts.Limits.withDefaults()
+	for _, pattern := range opts.Exclude {
+		if err := validateGlob(filepath.ToSlash(pattern)); err != nil {
+			return ParseResult{}, fmt.Errorf("failed to parse exclude pattern: %w", err)
+		}
+	}
+	inputs, err := collectInputs(opts, limits, paths)
+	if err != nil {
+		return ParseResult{}, err
+	}
+
+	// Each input is parsed into a result of its own, merged in input order
+	// afterwards so the output doesn't depend on which worker finished first
+	results := make([]ParseResult, len(inputs))
+	errs := make([]error, len(inputs))
+
+	var mu sync.Mutex
+	done := 0
+	advance := func() {
+		if opts.Progress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		opts.Progress(done, len(inputs))
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(opts.workers(), max(len(inputs), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = inputs[i](ctx, &results[i])
+				advance()
+			}
+		}()
+	}
+	for i := range inputs {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return ParseResult{}, err
+	}
+
+	var result ParseResult
+	for i := range results {
+		if errs[i] != nil {
+			return ParseResult{}, errs[i]
+		}
+		result.Resources = append(result.Resources, results[i].Resources...)
+		result.Warnings = append(result.Warnings, results[i].Warnings...)
+		result.FilesParsed += results[i].FilesParsed
+	}
+	return result, nil
+}
 
+// workers returns the number of inputs parsed at once
+func (opts ParseOptions) workers() int {
+	if opts.Concurrency > 0 {
+		return opts.Concurrency
+	}
+	return runtime.NumCPU()
+}
+
+// input parses one file, archive, chart or kustomization into result
+type input func(ctx context.Context, result *ParseResult) error
+
+// collectInputs walks paths in order and returns the inputs found in them.
+// Paths that don't exist but contain wildcards are expanded as globs, and
+// directory walks honor the .dangerscanignore files they come across.
+func collectInputs(opts ParseOptions, limits Limits, paths []string) ([]input, error) {
+	var expanded []string
 	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil && hasGlobMeta(path) {
+			matches, err := expandGlob(path)
+			if err != nil {
+				return nil, err
+			}
+			opts.debug("expanded glob", "pattern", path, "matches", len(matches))
+			expanded = append(expanded, matches...)
+			continue
+		}
+		expanded = append(expanded, path)
+	}
+
+	var inputs []input
+	for _, path := range expanded {
 		info, err := os.Stat(path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
 		}
 
+		if opts.skips(path, info) {
+			opts.debug("skipping path", "path", path, "reason", "not a .yaml, .yml or .json file")
+			continue
+		}
+		if opts.excluded(path, path) {
+			opts.debug("skipping path", "path", path, "reason", "matches an exclude pattern")
+			continue
+		}
+
 		if info.IsDir() {
 			// Recursively parse directory
+			ignores := make(ignoreFiles)
 			err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
 				if err != nil {
 					return err
 				}
-				if !info.IsDir() && (strings.HasSuffix(p, ".yaml") || strings.HasSuffix(p, ".yml")) {
-					res, err := parseFile(p)
-					if err != nil {
-						// Log warning but continue
-						fmt.Fprintf(os.Stderr, "Warning: failed to parse %s: %v\n", p, err)
-						return nil
+				skip := ""
+				if p != path && ignores.ignored(path, p, info.IsDir()) {
+					skip = "listed in " + IgnoreFileName
+				} else if opts.excluded(path, p) {
+					skip = "matches an exclude pattern"
+				}
+				if skip != "" {
+					opts.debug("skipping path", "path", p, "reason", skip)
+					if info.IsDir() {
+						return filepath.SkipDir
 					}
-					resources = append(resources, res...)
+					return nil
+				}
+				if info.IsDir() {
+					if err := ignores.load(p); err != nil {
+						return err
+					}
+				}
+				if info.IsDir() && opts.Helm && IsHelmChart(p) {
+					// Templates aren't valid YAML until rendered
+					opts.debug("rendering helm chart", "path", p)
+					inputs = append(inputs, func(ctx context.Context, result *ParseResult) error {
+						return parseHelmChart(ctx, p, opts.HelmValues, limits, result)
+					})
+					return filepath.SkipDir
+				}
+				if info.IsDir() && !opts.Raw && IsKustomization(p) {
+					// Loose files under a kustomization are patches and bases
+					// that don't stand alone; scan the rendered output instead
+					opts.debug("rendering kustomization", "path", p)
+					inputs = append(inputs, func(ctx context.Context, result *ParseResult) error {
+						return parseKustomization(ctx, p, limits, result)
+					})
+					return filepath.SkipDir
+				}
+				if !info.IsDir() && IsManifestPath(p) {
+					inputs = append(inputs, manifestInput(opts, p, limits))
+				} else if !info.IsDir() {
+					opts.debug("skipping path", "path", p, "reason", "not a .yaml, .yml or .json file")
 				}
 				return nil
 			})
 			if err != nil {
 				return nil, err
 			}
+		} else if IsArchivePath(path) {
+			// Parse manifests packed in a tarball
+			inputs = append(inputs, func(_ context.Context, result *ParseResult) error {
+				return parseArchive(path, limits, result)
+			})
 		} else {
-			// Parse single file
-			res, err := parseFile(path)
-			if err != nil {
-				return nil, err
-			}
-			resources = append(resources, res...)
+			inputs = append(inputs, manifestInput(opts, path, limits))
 		}
 	}
+	return inputs, nil
+}
 
-	return resources, nil
+// manifestInput parses a single manifest file. Failures are recorded as
+// warnings rather than aborting the run.
+func manifestInput(opts ParseOptions, path string, limits Limits) input {
+	return func(_ context.Context, result *ParseResult) error {
+		res, err := parseFile(path, limits)
+		if err != nil {
+			result.Warnings = append(result.Warnings, types.Warning{
+				Path:    path,
+				Message: fmt.Sprintf("failed to parse: %v", err),
+			})
+			return nil
+		}
+		opts.debug("parsed file", "path", path, "resources", len(res))
+		result.Resources = append(result.Resources, res...)
+		result.FilesParsed++
+		return nil
+	}
+}
+
+// skips reports whether a path argument is left out under SkipNonManifests
+func (opts ParseOptions) skips(path string, info os.FileInfo) bool {
+	if !opts.SkipNonManifests || info.IsDir() {
+		return false
+	}
+	return !IsManifestPath(path)
+}
+
+// parseKustomization renders the kustomization in dir and adds its resources
+// to result. Build failures are recorded as warnings; a missing kustomize
+// binary is returned as an error.
+func parseKustomization(ctx context.Context, dir string, limits Limits, result *ParseResult) error {
+	data, err := renderKustomization(ctx, dir)
+	if errors.Is(err, ErrKustomizeNotFound) || ctx.Err() != nil {
+		return err
+	}
+	if err != nil {
+		result.Warnings = append(result.Warnings, types.Warning{
+			Path:    dir,
+			Message: err.Error(),
+		})
+		return nil
+	}
+
+	res, err := ParseSourceWithLimits(dir, data, limits)
+	if err != nil {
+		result.Warnings = append(result.Warnings, types.Warning{
+			Path:    dir,
+			Message: fmt.Sprintf("failed to parse kustomize output: %v", err),
+		})
+		return nil
+	}
+	result.Resources = append(result.Resources, withoutPositions(res)...)
+	result.FilesParsed++
+	return nil
+}
+
+// withoutPositions clears the line and column of rendered resources, which
+// refer to generated output rather than to any file the user can open
+func withoutPositions(resources []K8sResource) []K8sResource {
+	for i := range resources {
+		resources[i].Line, resources[i].Column, resources[i].node = 0, 0, nil
+	}
+	return resources
+}
+
+// IsManifestPath reports whether a file looks like a manifest based on its
+// extension. JSON is read as YAML, of which it is a subset.
+func IsManifestPath(path string) bool {
+	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".json")
 }
 
 // parseFile parses a single YAML file (may contain multiple documents)
-func parseFile(path string) ([]K8sResource, error) {
+func parseFile(path string, limits Limits) ([]K8sResource, error) {
+	// Check the size before reading so an oversized file is never loaded
+	if info, err := os.Stat(path); err == nil {
+		if err := limits.checkSize(info.Size()); err != nil {
+			return nil, err
+		}
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return ParseYAML(data)
+	return ParseSourceWithLimits(path, data, limits)
+}
+
+// ParseSource parses YAML data read from somewhere other than the local
+// filesystem, such as an archive entry or a git revision, and records source
+// as the location of each resource
+func ParseSource(source string, data []byte) ([]K8sResource, error) {
+	return ParseSourceWithLimits(source, data, Limits{})
+}
+
+// ParseSourceWithLimits is ParseSource with explicit parse limits
+func ParseSourceWithLimits(source string, data []byte, limits Limits) ([]K8sResource, error) {
+	resources, err := ParseYAMLWithLimits(data, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range resources {
+		resources[i].Source = source
+	}
+	return resources, nil
 }
 
 // ParseYAML parses YAML data containing one or more Kubernetes resources
+// using the default parse limits
 func ParseYAML(data []byte) ([]K8sResource, error) {
+	return ParseYAMLWithLimits(data, Limits{})
+}
+
+// ParseYAMLWithLimits parses YAML data containing one or more Kubernetes
+// resources, rejecting input beyond limits. Errors name the offending
+// document, counting from 1.
+func ParseYAMLWithLimits(data []byte, limits Limits) ([]K8sResource, error) {
+	limits = limits.withDefaults()
+	if err := limits.checkSize(int64(len(data))); err != nil {
+		return nil, err
+	}
+
 	var resources []K8sResource
 
 	// Split by YAML document separator
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 
-	for {
-		var raw map[string]interface{}
-		err := decoder.Decode(&raw)
+	for n := 1; ; n++ {
+		decoded, err := decodeDocument(decoder, limits)
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+			return nil, fmt.Errorf("document %d: %w", n, err)
 		}
+		resources = append(resources, decoded...)
+	}
+
+	return resources, nil
+}
 
-		// Skip empty documents
-		if len(raw) == 0 {
+// decodeDocument decodes the next document into the resources it holds:
+// none for documents that are skipped, one for an object, and one per item
+// for a List. A panic anywhere in decoding is returned as an error so one
+// malformed document can't crash a scan of a whole directory.
+func decodeDocument(decoder *yaml.Decoder, limits Limits) (resources []K8sResource, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			resources, err = nil, fmt.Errorf("failed to decode YAML: %v", r)
+		}
+	}()
+
+	var node yaml.Node
+	if err := decoder.Decode(&node); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+	if err := limits.checkDepth(&node); err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := node.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+
+	// Skip empty documents and ones that aren't Kubernetes objects, such
+	// as plain config or Helm values sharing a file with manifests
+	raw, isMap := doc.(map[string]interface{})
+	if !isMap || !IsResourceDocument(raw) {
+		return nil, nil
+	}
+	var root *yaml.Node
+	if len(node.Content) > 0 {
+		root = node.Content[0]
+	}
+
+	if isList(raw) {
+		return flattenList(raw, root, "items")
+	}
+
+	resource, err := parseResource(raw)
+	if err != nil {
+		return nil, err
+	}
+	if root != nil {
+		resource.setNode(root)
+	}
+	return []K8sResource{resource}, nil
+}
+
+// isList reports whether a document is a list of objects: a kind: List as
+// written by kubectl get -o yaml or -o json for more than one object, or a
+// typed list such as DeploymentList from the API
+func isList(raw map[string]interface{}) bool {
+	kind, _ := raw["kind"].(string)
+	_, hasItems := raw["items"].([]interface{})
+	return hasItems && strings.HasSuffix(kind, "List")
+}
+
+// flattenList returns the resources in a list, descending into lists nested
+// in it. path names the list's items in errors, e.g. items[2].items.
+func flattenList(list map[string]interface{}, node *yaml.Node, path string) ([]K8sResource, error) {
+	items, _ := list["items"].([]interface{})
+	var itemNodes *yaml.Node
+	if node != nil {
+		_, itemNodes = mappingEntry(node, "items")
+	}
+
+	// Items of a typed list leave out the kind and apiVersion they share
+	kind, _ := list["kind"].(string)
+	itemKind := strings.TrimSuffix(kind, "List")
+	apiVersion, _ := list["apiVersion"].(string)
+
+	var resources []K8sResource
+	for i, item := range items {
+		raw, isMap := item.(map[string]interface{})
+		if !isMap {
+			continue
+		}
+		var itemNode *yaml.Node
+		if itemNodes != nil && i < len(itemNodes.Content) {
+			itemNode = itemNodes.Content[i]
+		}
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+		if itemKind != "" && !IsResourceDocument(raw) {
+			raw["kind"] = itemKind
+			if apiVersion != "" {
+				raw["apiVersion"] = apiVersion
+			}
+		}
+		if !IsResourceDocument(raw) {
+			continue
+		}
+
+		if isList(raw) {
+			nested, err := flattenList(raw, itemNode, itemPath+".items")
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, nested...)
 			continue
 		}
 
-		// Parse into K8sResource
 		resource, err := parseResource(raw)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%s: %w", itemPath, err)
+		}
+		if itemNode != nil {
+			resource.setNode(itemNode)
 		}
-
 		resources = append(resources, resource)
 	}
-
 	return resources, nil
 }
 
+// setNode records the node a resource was decoded from, and its position
+func (r *K8sResource) setNode(node *yaml.Node) {
+	r.node = node
+	r.Line, r.Column = node.Line, node.Column
+}
+
+// IsResourceDocument reports whether a decoded YAML document looks like a
+// Kubernetes object. Documents with neither apiVersion nor kind are not.
+func IsResourceDocument(raw map[string]interface{}) bool {
+	_, hasAPIVersion := raw["apiVersion"]
+	_, hasKind := raw["kind"]
+	return hasAPIVersion || hasKind
+}
+
 // parseResource converts raw YAML to K8sResource
 func parseResource(raw map[string]interface{}) (K8sResource, error) {
 	// Re-marshal and unmarshal for clean parsing
@@ -155,28 +601,39 @@ func parseResource(raw map[string]interface{}) (K8sResource, error) {
 // IsSupportedKind checks if the resource kind is supported
 func IsSupportedKind(kind string) bool {
 	supported := map[string]bool{
-		"Pod":                true,
-		"Deployment":         true,
-		"StatefulSet":        true,
-		"DaemonSet":          true,
-		"Job":                true,
-		"CronJob":            true,
-		"Service":            true,
-		"Role":               true,
-		"ClusterRole":        true,
-		"RoleBinding":        true,
-		"ClusterRoleBinding": true,
+		"Pod":                   true,
+		"Deployment":            true,
+		"StatefulSet":           true,
+		"DaemonSet":             true,
+		"ReplicaSet":            true,
+		"ReplicationController": true,
+		"Job":                   true,
+		"CronJob":               true,
+		"Service":               true,
+		"Role":                  true,
+		"ClusterRole":           true,
+		"RoleBinding":           true,
+		"ClusterRoleBinding":    true,
+		"Secret":                true,
+		"DeploymentConfig":      true, // OpenShift
+		"Route":                 true, // OpenShift
+		"PodSecurityPolicy":     true, // Removed in Kubernetes 1.25 but still found in older clusters
 	}
 	return supported[kind]
 }
 
-// GetPodSpec extracts the pod spec from various resource types
+// GetPodSpec extracts the pod spec from various resource types. Resources
+// without a kind never have one.
 func GetPodSpec(resource K8sResource) (map[string]interface{}, bool) {
+	if resource.Kind == "" {
+		return nil, false
+	}
 	if resource.Kind == "Pod" {
 		return resource.Spec, true
 	}
 
-	// For Deployment, StatefulSet, DaemonSet, Job
+	// For Deployment, StatefulSet, DaemonSet, ReplicaSet, ReplicationController,
+	// Job and OpenShift DeploymentConfig
 	if template, ok := resource.Spec["template"].(map[string]interface{}); ok {
 		if spec, ok := template["spec"].(map[string]interface{}); ok {
 			return spec, true
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// aliasBomb expands each level of aliases tenfold, a "billion laughs" input
+const aliasBomb = `a: &a ["lol","lol","lol","lol","lol","lol","lol","lol","lol"]
+b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a]
+c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b]
+d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c]
+e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d]
+f: &f [*e,*e,*e,*e,*e,*e,*e,*e,*e]
+g: &g [*f,*f,*f,*f,*f,*f,*f,*f,*f]
+h: &h [*g,*g,*g,*g,*g,*g,*g,*g,*g]
+i: &i [*h,*h,*h,*h,*h,*h,*h,*h,*h]
+`
+
+// fuzzLimits keeps fuzzed inputs small enough to hit the size and depth
+// limits
+var fuzzLimits = Limits{MaxFileBytes: 1024, MaxDepth: 32}
+
+func FuzzParseYAML(f *testing.F) {
+	f.Add([]byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\nspec:\n  containers:\n  - name: app\n    image: nginx\n"))
+	f.Add([]byte("---\nkind: Pod\n---\n---\napiVersion: v1\nkind: List\nitems:\n- kind: Service\n  metadata: {name: svc}\n"))
+	f.Add([]byte(aliasBomb))
+	f.Add([]byte("kind: Pod\nspec: " + strings.Repeat("[", DefaultMaxDepth+50) + strings.Repeat("]", DefaultMaxDepth+50) + "\n"))
+	f.Add([]byte("kind: Pod\nspec:\n" + strings.Repeat("  x:\n", 40)))
+	f.Add([]byte("kind: ConfigMap\ndata:\n  big: " + strings.Repeat("x", 2048) + "\n"))
+	f.Add([]byte("kind: [unterminated\n\t- : :"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Any input may be rejected, but none may panic
+		ParseYAML(data)
+
+		if _, err := ParseYAMLWithLimits(data, fuzzLimits); err == nil && int64(len(data)) > fuzzLimits.MaxFileBytes {
+			t.Fatalf("parsed %d bytes despite a %d byte limit", len(data), fuzzLimits.MaxFileBytes)
+		}
+	})
+}
+
+// writeFile creates a file under dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFilesSkipsBadFile(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFile(t, dir, "first.yaml", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: first\n")
+	bad := writeFile(t, dir, "bad.yaml", "kind: Pod\nmetadata: [unterminated\n")
+	second := writeFile(t, dir, "second.yaml", "apiVersion: v1\nkind: Service\nmetadata:\n  name: second\n")
+
+	result, err := ParseFiles(first, bad, second)
+	if err != nil {
+		t.Fatalf("ParseFiles failed: %v", err)
+	}
+
+	var names []string
+	for _, resource := range result.Resources {
+		names = append(names, resource.Metadata.Name)
+	}
+	if strings.Join(names, ",") != "first,second" {
+		t.Errorf("got resources %v, want first and second", names)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Path != bad {
+		t.Fatalf("got warnings %+v, want one for %s", result.Warnings, bad)
+	}
+	if result.Warnings[0].Message == "" {
+		t.Error("warning has no message")
+	}
+}
+
+func TestParseFilesOversizeFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "small.yaml", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: small\n")
+	big := writeFile(t, dir, "big.yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: big\ndata:\n  blob: "+strings.Repeat("x", 200)+"\n")
+
+	result, err := ParseFilesWithOptions(ParseOptions{Limits: Limits{MaxFileBytes: 128}}, dir)
+	if err != nil {
+		t.Fatalf("ParseFiles failed: %v", err)
+	}
+	if len(result.Resources) != 1 || result.Resources[0].Metadata.Name != "small" {
+		t.Errorf("got %d resources, want only the small file's", len(result.Resources))
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Path != big || !strings.Contains(result.Warnings[0].Message, "128 byte limit") {
+		t.Errorf("got warnings %+v, want one about %s exceeding the limit", result.Warnings, big)
+	}
+}
+
+func TestParseYAMLDepthLimit(t *testing.T) {
+	// nested returns a document whose root and spec mappings hold depth-2 nested lists
+	nested := func(depth int) []byte {
+		return []byte("kind: Pod\nspec:\n  x: " + strings.Repeat("[", depth-2) + strings.Repeat("]", depth-2) + "\n")
+	}
+
+	if _, err := ParseYAML(nested(DefaultMaxDepth)); err != nil {
+		t.Errorf("rejected a document at the depth limit: %v", err)
+	}
+
+	_, err := ParseYAML(nested(DefaultMaxDepth + 1))
+	if err == nil || !strings.Contains(err.Error(), "nests deeper than") {
+		t.Errorf("got error %v, want the depth limit", err)
+	}
+
+	// Far deeper than any stack could recurse into
+	if _, err := ParseYAML(nested(1_000_000)); err == nil {
+		t.Error("accepted a document nested a million levels deep")
+	}
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PodSpecPath returns the path of the pod spec within a resource, in the
+// form accepted by Lookup, or "" if the resource has none. It mirrors
+// GetPodSpec.
+func PodSpecPath(resource K8sResource) string {
+	if resource.Kind == "" {
+		return ""
+	}
+	if resource.Kind == "Pod" {
+		return "spec"
+	}
+	if template, ok := resource.Spec["template"].(map[string]interface{}); ok {
+		if _, ok := template["spec"].(map[string]interface{}); ok {
+			return "spec.template.spec"
+		}
+	}
+	if _, ok := GetPodSpec(resource); ok {
+		return "spec.jobTemplate.spec.template.spec"
+	}
+	return ""
+}
+
+// Lookup follows a dotted path such as "spec.template.spec.containers[0]"
+// through a decoded YAML document. It returns the value found there and the
+// last path element, a map key or a list index.
+func Lookup(raw map[string]interface{}, path string) (value interface{}, last string, ok bool) {
+	value = raw
+	for _, part := range strings.Split(path, ".") {
+		key, indexes := part, []string(nil)
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+			indexes = strings.Split(strings.TrimSuffix(part[i+1:], "]"), "][")
+		}
+
+		m, isMap := value.(map[string]interface{})
+		if !isMap {
+			return nil, "", false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, "", false
+		}
+		last = key
+
+		for _, index := range indexes {
+			n, err := strconv.Atoi(index)
+			list, isList := value.([]interface{})
+			if err != nil || !isList || n < 0 || n >= len(list) {
+				return nil, "", false
+			}
+			value = list[n]
+			last = "[" + index + "]"
+		}
+	}
+	return value, last, true
+}
+
+// Position returns the line and column, counting from 1, of the element at
+// path in the resource's source, in the form accepted by Lookup. When path is
+// empty or can't be followed, the position of the nearest element along it
+// is returned. Both are 0 if the resource's position is unknown.
+func (r K8sResource) Position(path string) (line, column int) {
+	node := r.node
+	if node == nil {
+		return r.Line, r.Column
+	}
+	if path == "" {
+		return node.Line, node.Column
+	}
+
+	// A map entry is located at its key, which is where a nested value starts
+	at := node
+	for _, part := range strings.Split(path, ".") {
+		key, indexes := part, []string(nil)
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+			indexes = strings.Split(strings.TrimSuffix(part[i+1:], "]"), "][")
+		}
+
+		keyNode, value := mappingEntry(node, key)
+		if value == nil {
+			break
+		}
+		node, at = value, keyNode
+
+		for _, index := range indexes {
+			n, err := strconv.Atoi(index)
+			if err != nil || node.Kind != yaml.SequenceNode || n < 0 || n >= len(node.Content) {
+				return at.Line, at.Column
+			}
+			node = node.Content[n]
+			at = node
+		}
+	}
+	return at.Line, at.Column
+}
+
+// mappingEntry returns the key and value nodes for key in a mapping node, or
+// nils
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
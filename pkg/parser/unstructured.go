@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// FromUnstructured builds a K8sResource directly from an already-decoded
+// object, such as the Object map of a controller-runtime
+// unstructured.Unstructured, without round-tripping through YAML.
+//
+// Numbers are normalized to int where they are integral (JSON decoders yield
+// int64, float64 or json.Number) so rules see the same types as for parsed
+// YAML. The input map is not modified.
+func FromUnstructured(obj map[string]interface{}) K8sResource {
+	raw, _ := normalize(obj).(map[string]interface{})
+
+	resource := K8sResource{Raw: raw}
+	resource.APIVersion, _ = raw["apiVersion"].(string)
+	resource.Kind, _ = raw["kind"].(string)
+	resource.Spec, _ = raw["spec"].(map[string]interface{})
+
+	if metadata, ok := raw["metadata"].(map[string]interface{}); ok {
+		resource.Metadata.Name, _ = metadata["name"].(string)
+		resource.Metadata.Namespace, _ = metadata["namespace"].(string)
+		resource.Metadata.Labels = stringMap(metadata["labels"])
+		resource.Metadata.Annotations = stringMap(metadata["annotations"])
+	}
+
+	if rules, ok := raw["rules"].([]interface{}); ok {
+		for _, r := range rules {
+			rule, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			resource.Rules = append(resource.Rules, Rule{
+				APIGroups: stringSlice(rule["apiGroups"]),
+				Resources: stringSlice(rule["resources"]),
+				Verbs:     stringSlice(rule["verbs"]),
+			})
+		}
+	}
+
+	if roleRef, ok := raw["roleRef"].(map[string]interface{}); ok {
+		ref := &RoleRef{}
+		ref.APIGroup, _ = roleRef["apiGroup"].(string)
+		ref.Kind, _ = roleRef["kind"].(string)
+		ref.Name, _ = roleRef["name"].(string)
+		resource.RoleRef = ref
+	}
+
+	if subjects, ok := raw["subjects"].([]interface{}); ok {
+		for _, s := range subjects {
+			subject, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var sub Subject
+			sub.Kind, _ = subject["kind"].(string)
+			sub.Name, _ = subject["name"].(string)
+			sub.Namespace, _ = subject["namespace"].(string)
+			resource.Subjects = append(resource.Subjects, sub)
+		}
+	}
+
+	return resource
+}
+
+// normalize deep-copies a decoded value, converting integral numbers to int
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalize(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	case int64:
+		return int(v)
+	case int32:
+		return int(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+			return int(v)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	default:
+		return v
+	}
+}
+
+// stringMap converts a decoded map to map[string]string, dropping non-strings
+func stringMap(value interface{}) map[string]string {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	out := make(map[string]string, len(m))
+	for key, item := range m {
+		if s, ok := item.(string); ok {
+			out[key] = s
+		}
+	}
+	return out
+}
+
+// stringSlice converts a decoded list to []string, dropping non-strings
+func stringSlice(value interface{}) []string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
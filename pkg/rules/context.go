@@ -0,0 +1,169 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// ContextRule checks one resource with the whole scanned set at hand, for
+// findings about that resource that depend on the objects around it. Unlike
+// an AggregateRule, its findings are attributed, suppressed and located
+// like those of a per-resource Rule.
+type ContextRule func(resource parser.K8sResource, scan *ScanContext) []types.Finding
+
+// ScanContext indexes the resources of one scan for ContextRules
+type ScanContext struct {
+	resources []parser.K8sResource
+	services  map[string][]parser.K8sResource // Keyed by namespace
+}
+
+// NewScanContext indexes resources for ContextRules
+func NewScanContext(resources []parser.K8sResource) *ScanContext {
+	scan := &ScanContext{
+		resources: resources,
+		services:  make(map[string][]parser.K8sResource),
+	}
+	for _, resource := range resources {
+		if resource.Kind == "Service" {
+			namespace := namespaceOf(resource)
+			scan.services[namespace] = append(scan.services[namespace], resource)
+		}
+	}
+	return scan
+}
+
+// Resources returns every resource in the scan, in the order given
+func (c *ScanContext) Resources() []parser.K8sResource {
+	return c.resources
+}
+
+// ServicesSelecting returns the Services in the workload's namespace whose
+// selector matches its pod labels. Services without a selector are skipped,
+// since their endpoints are managed by hand.
+func (c *ScanContext) ServicesSelecting(workload parser.K8sResource) []parser.K8sResource {
+	labels := podLabels(workload)
+	if len(labels) == 0 {
+		return nil
+	}
+
+	var selecting []parser.K8sResource
+	for _, service := range c.services[namespaceOf(workload)] {
+		selector, _ := service.Spec["selector"].(map[string]interface{})
+		if len(selector) == 0 {
+			continue
+		}
+		matches := true
+		for key, want := range selector {
+			if labels[key] != fmt.Sprint(want) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			selecting = append(selecting, service)
+		}
+	}
+	return selecting
+}
+
+// podLabels returns the labels of the pods a resource creates: its own for a
+// Pod, the pod template's for a workload
+func podLabels(resource parser.K8sResource) map[string]string {
+	if resource.Kind == "Pod" {
+		return resource.Metadata.Labels
+	}
+
+	specPath := parser.PodSpecPath(resource)
+	if specPath == "" {
+		return nil
+	}
+	value, _, ok := parser.Lookup(resource.Raw, strings.TrimSuffix(specPath, ".spec")+".metadata.labels")
+	if !ok {
+		return nil
+	}
+	raw, _ := value.(map[string]interface{})
+	labels := make(map[string]string, len(raw))
+	for key, v := range raw {
+		labels[key] = fmt.Sprint(v)
+	}
+	return labels
+}
+
+// ContextRulesForCategories returns the context rules registered under any
+// of the given categories
+func ContextRulesForCategories(categories ...types.Category) []ContextRule {
+	var selected []ContextRule
+	for _, category := range categories {
+		switch category {
+		case types.CategorySecurity:
+			selected = append(selected, contextInCategory(category, CheckExposedPrivilegedWorkload)...)
+		}
+	}
+	return selected
+}
+
+// contextInCategory is inCategory for context rules
+func contextInCategory(category types.Category, checks ...ContextRule) []ContextRule {
+	wrapped := make([]ContextRule, len(checks))
+	for i, check := range checks {
+		check := check
+		wrapped[i] = func(resource parser.K8sResource, scan *ScanContext) []types.Finding {
+			findings := check(resource, scan)
+			for j := range findings {
+				findings[j].Category = category
+				if ref, ok := ruleReferences[findings[j].RuleID]; ok {
+					findings[j].CISControl = ref.cisControl
+					findings[j].References = ref.references
+				}
+			}
+			return findings
+		}
+	}
+	return wrapped
+}
+
+// CheckExposedPrivilegedWorkload checks for workloads with a privileged
+// container whose pods sit behind a LoadBalancer or NodePort Service, which
+// puts a node takeover one remote exploit away
+func CheckExposedPrivilegedWorkload(resource parser.K8sResource, scan *ScanContext) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+		securityContext, _ := container["securityContext"].(map[string]interface{})
+		if privileged, _ := securityContext["privileged"].(bool); !privileged {
+			continue
+		}
+
+		for _, service := range scan.ServicesSelecting(resource) {
+			serviceType, _ := service.Spec["type"].(string)
+			if serviceType != "LoadBalancer" && serviceType != "NodePort" {
+				continue
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "exposed-privileged-workload",
+				Severity:  types.Critical,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason: fmt.Sprintf("Privileged container %s is exposed outside the cluster by %s Service %s",
+					containerName(container), serviceType, service.Metadata.Name),
+				Impact: "A remote exploit in the exposed service gives an attacker root on the node",
+				Fix:    fmt.Sprintf("Drop privileged from container %s, or serve it through a ClusterIP Service behind an ingress that filters traffic", containerName(container)),
+				Path:   c.path(resource),
+			})
+			break
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,328 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// CustomRule is a rule defined in YAML rather than Go: a path expression
+// evaluated against the raw resource, a condition on what it finds, and the
+// text of the finding it produces
+type CustomRule struct {
+	ID       string         `yaml:"id"`
+	Severity types.Severity `yaml:"severity"`
+	Category types.Category `yaml:"category"`
+	Kinds    []string       `yaml:"kinds"` // Resource kinds checked; empty for all
+	Match    CustomMatch    `yaml:"match"`
+	Reason   string         `yaml:"reason"`
+	Impact   string         `yaml:"impact"`
+	Fix      string         `yaml:"fix"`
+}
+
+// CustomMatch selects values with a JSONPath-style expression and flags
+// those meeting exactly one condition
+type CustomMatch struct {
+	// Path is a JSONPath subset: $.a.b, [n], [*] and ['quoted.key'].
+	// $podSpec starts at the pod spec of any workload kind.
+	Path       string      `yaml:"path"`
+	Exists     *bool       `yaml:"exists"`     // Flag values that are present (true) or missing (false)
+	Equals     interface{} `yaml:"equals"`     // Flag values equal to this
+	NotEquals  interface{} `yaml:"notEquals"`  // Flag values that differ from this or are missing
+	Matches    string      `yaml:"matches"`    // Flag values matching this regular expression
+	NotMatches string      `yaml:"notMatches"` // Flag present values not matching this regular expression
+}
+
+// podSpecRoot starts a custom rule path at the pod spec, wherever the
+// resource kind keeps it
+const podSpecRoot = "$podSpec"
+
+// pathStep is one element of a parsed custom rule path
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// pathMatch is a value a path resolved to, or the point where it went
+// missing
+type pathMatch struct {
+	path  string
+	value interface{}
+	found bool
+}
+
+// Compile checks a custom rule and returns the Rule that evaluates it
+func (c CustomRule) Compile() (Rule, error) {
+	if c.ID == "" {
+		return nil, errors.New("rule has no id")
+	}
+	if c.Reason == "" || c.Impact == "" || c.Fix == "" {
+		return nil, fmt.Errorf("rule %s: reason, impact and fix are required", c.ID)
+	}
+
+	podSpec, steps, err := parsePath(c.Match.Path)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", c.ID, err)
+	}
+	condition, err := c.Match.condition()
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", c.ID, err)
+	}
+
+	kinds := make(map[string]bool)
+	for _, kind := range c.Kinds {
+		kinds[kind] = true
+	}
+
+	return func(resource parser.K8sResource) []types.Finding {
+		if len(kinds) > 0 && !kinds[resource.Kind] {
+			return nil
+		}
+
+		var root interface{} = resource.Raw
+		prefix := ""
+		if podSpec {
+			prefix = parser.PodSpecPath(resource)
+			if prefix == "" {
+				return nil
+			}
+			root, _, _ = parser.Lookup(resource.Raw, prefix)
+		}
+
+		var findings []types.Finding
+		for _, m := range resolvePath(root, steps, prefix) {
+			if !condition(m) {
+				continue
+			}
+			findings = append(findings, types.Finding{
+				RuleID:    c.ID,
+				Severity:  c.Severity,
+				Category:  c.Category,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Reason:    c.Reason,
+				Impact:    c.Impact,
+				Fix:       c.Fix,
+				Path:      m.path,
+			})
+		}
+		return findings
+	}, nil
+}
+
+// condition returns the test applied to each resolved value, after checking
+// that exactly one is configured
+func (m CustomMatch) condition() (func(pathMatch) bool, error) {
+	var conditions []func(pathMatch) bool
+
+	if m.Exists != nil {
+		want := *m.Exists
+		conditions = append(conditions, func(pm pathMatch) bool { return pm.found == want })
+	}
+	if m.Equals != nil {
+		conditions = append(conditions, func(pm pathMatch) bool { return pm.found && sameValue(pm.value, m.Equals) })
+	}
+	if m.NotEquals != nil {
+		conditions = append(conditions, func(pm pathMatch) bool { return !pm.found || !sameValue(pm.value, m.NotEquals) })
+	}
+	if m.Matches != "" {
+		re, err := regexp.Compile(m.Matches)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matches expression: %w", err)
+		}
+		conditions = append(conditions, func(pm pathMatch) bool { return pm.found && matchesScalar(re, pm.value) })
+	}
+	if m.NotMatches != "" {
+		re, err := regexp.Compile(m.NotMatches)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notMatches expression: %w", err)
+		}
+		conditions = append(conditions, func(pm pathMatch) bool { return pm.found && !matchesScalar(re, pm.value) })
+	}
+
+	if len(conditions) != 1 {
+		return nil, errors.New("match needs exactly one of exists, equals, notEquals, matches or notMatches")
+	}
+	return conditions[0], nil
+}
+
+// sameValue compares a resource value with one from a rule file. Scalars
+// compare by their text, so equals: "true" and equals: true both match a
+// boolean true.
+func sameValue(value, want interface{}) bool {
+	if isScalar(value) && isScalar(want) {
+		return fmt.Sprint(value) == fmt.Sprint(want)
+	}
+	return reflect.DeepEqual(value, want)
+}
+
+// matchesScalar applies a regular expression to a scalar value's text.
+// Maps and lists never match.
+func matchesScalar(re *regexp.Regexp, value interface{}) bool {
+	return isScalar(value) && value != nil && re.MatchString(fmt.Sprint(value))
+}
+
+// isScalar reports whether a decoded value is neither a map nor a list
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	default:
+		return true
+	}
+}
+
+// parsePath splits a custom rule path into steps. It reports whether the
+// path starts at the pod spec rather than the document root.
+func parsePath(expr string) (bool, []pathStep, error) {
+	rest := strings.TrimSpace(expr)
+	podSpec := false
+	switch {
+	case rest == "":
+		return false, nil, errors.New("match has no path")
+	case strings.HasPrefix(rest, podSpecRoot):
+		podSpec = true
+		rest = rest[len(podSpecRoot):]
+	case strings.HasPrefix(rest, "$"):
+		rest = rest[1:]
+	default:
+		// A bare path such as spec.replicas is read from the root
+		rest = "." + rest
+	}
+
+	var steps []pathStep
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return false, nil, fmt.Errorf("invalid path %q: unclosed [", expr)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return false, nil, fmt.Errorf("invalid path %q: bad index [%s]", expr, inner)
+				}
+				steps = append(steps, pathStep{index: n, isIndex: true})
+			}
+
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return false, nil, fmt.Errorf("invalid path %q: empty key (recursive descent is not supported)", expr)
+			}
+			if key == "*" {
+				steps = append(steps, pathStep{wildcard: true})
+			} else {
+				steps = append(steps, pathStep{key: key})
+			}
+
+		default:
+			return false, nil, fmt.Errorf("invalid path %q: expected . or [ at %q", expr, rest)
+		}
+	}
+	return podSpec, steps, nil
+}
+
+// resolvePath follows steps from value, expanding wildcards. A branch that
+// ends early yields an unfound match at the deepest node it reached, so
+// exists: false can point at the container missing a field.
+func resolvePath(value interface{}, steps []pathStep, path string) []pathMatch {
+	if len(steps) == 0 {
+		return []pathMatch{{path: path, value: value, found: true}}
+	}
+	step, rest := steps[0], steps[1:]
+
+	switch {
+	case step.wildcard:
+		var matches []pathMatch
+		switch v := value.(type) {
+		case []interface{}:
+			for i, item := range v {
+				matches = append(matches, resolvePath(item, rest, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				matches = append(matches, resolvePath(v[key], rest, joinPath(path, key))...)
+			}
+		}
+		return matches
+
+	case step.isIndex:
+		list, ok := value.([]interface{})
+		if !ok || step.index >= len(list) {
+			return []pathMatch{{path: path}}
+		}
+		return resolvePath(list[step.index], rest, fmt.Sprintf("%s[%d]", path, step.index))
+
+	default:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return []pathMatch{{path: path}}
+		}
+		next, ok := m[step.key]
+		if !ok {
+			return []pathMatch{{path: path}}
+		}
+		return resolvePath(next, rest, joinPath(path, step.key))
+	}
+}
+
+// joinPath appends a map key to a finding path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// CustomRulesForCategories compiles the custom rules in any of the given
+// categories
+func CustomRulesForCategories(custom []CustomRule, categories ...types.Category) ([]Rule, error) {
+	selected := make(map[types.Category]bool)
+	for _, category := range categories {
+		selected[category] = true
+	}
+
+	var compiled []Rule
+	for _, c := range custom {
+		if !selected[c.Category] {
+			continue
+		}
+		rule, err := c.Compile()
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,750 @@
+package rules
+
+import "sort"
+
+// Explanation is the extended documentation for a built-in rule, printed by
+// the explain command
+type Explanation struct {
+	RuleID      string
+	Title       string
+	Severity    string // Default severity; rules.yaml may override it
+	Description string
+	Why         string
+	Before      string // Manifest excerpt that triggers the rule
+	After       string // The same excerpt with the fix applied
+	References  []string
+}
+
+// Explain returns the extended documentation for a rule ID. References
+// include any control framework mappings known for the rule.
+func Explain(ruleID string) (Explanation, bool) {
+	e, ok := explanations[ruleID]
+	if !ok {
+		return explainPSS(ruleID)
+	}
+
+	e.RuleID = ruleID
+	if ref, ok := ruleReferences[ruleID]; ok {
+		var refs []string
+		if ref.cisControl != "" {
+			refs = append(refs, "CIS Kubernetes Benchmark "+ref.cisControl)
+		}
+		refs = append(refs, ref.references...)
+		e.References = append(refs, e.References...)
+	}
+	return e, true
+}
+
+// ExplainedRuleIDs returns the sorted IDs of rules that have extended documentation
+func ExplainedRuleIDs() []string {
+	ids := make([]string, 0, len(explanations))
+	for id := range explanations {
+		ids = append(ids, id)
+	}
+	ids = append(ids, PSSRuleIDs()...)
+	sort.Strings(ids)
+	return ids
+}
+
+// explanations holds the extended documentation for built-in rules
+var explanations = map[string]Explanation{
+	"privileged-container": {
+		Title:       "Privileged container",
+		Severity:    "HIGH",
+		Description: "The container sets securityContext.privileged: true, which disables nearly every isolation mechanism the container runtime provides.",
+		Why:         "A privileged container can see all host devices, load kernel modules and mount the host filesystem. Escaping to the node is a matter of a few shell commands, after which every pod and secret on the node is exposed.",
+		Before: `containers:
+- name: app
+  securityContext:
+    privileged: true`,
+		After: `containers:
+- name: app
+  securityContext:
+    privileged: false
+    capabilities:
+      drop: ["ALL"]
+      add: ["NET_ADMIN"]  # only what is actually needed`,
+		References: []string{"https://kubernetes.io/docs/concepts/security/pod-security-standards/"},
+	},
+	"hostpath-volume": {
+		Title:       "hostPath volume",
+		Severity:    "HIGH",
+		Description: "The pod mounts a directory from the node's filesystem with a hostPath volume.",
+		Why:         "Writable hostPath mounts let a compromised container modify node binaries, kubelet credentials or other pods' data. Even read-only mounts can leak secrets and host configuration.",
+		Before: `volumes:
+- name: data
+  hostPath:
+    path: /var/lib/app`,
+		After: `volumes:
+- name: data
+  persistentVolumeClaim:
+    claimName: app-data`,
+		References: []string{"https://kubernetes.io/docs/concepts/storage/volumes/#hostpath"},
+	},
+	"docker-socket-mount": {
+		Title:       "Container runtime socket mount",
+		Severity:    "CRITICAL",
+		Description: "The pod mounts /var/run/docker.sock from the host.",
+		Why:         "Anyone who can talk to the Docker socket can start a privileged container with the host filesystem mounted, which is root on the node.",
+		Before: `volumes:
+- name: docker
+  hostPath:
+    path: /var/run/docker.sock`,
+		After: `# Build images with a rootless builder such as kaniko or buildkit
+# instead of driving the node's Docker daemon`,
+	},
+	"runs-as-root": {
+		Title:       "Container runs as root",
+		Severity:    "MEDIUM",
+		Description: "The container runs as UID 0, or nothing prevents it from doing so because runAsNonRoot is not set.",
+		Why:         "Root inside the container is root on the host kernel. Any container escape or writable host mount is immediately more damaging.",
+		Before: `containers:
+- name: app
+  image: app:1.2.3`,
+		After: `securityContext:
+  runAsNonRoot: true
+  runAsUser: 10001
+containers:
+- name: app
+  image: app:1.2.3`,
+	},
+	"low-uid": {
+		Title:       "Container runs as a system UID",
+		Severity:    "MEDIUM",
+		Description: "runAsUser is non-zero but below the system account threshold (1000 by default, configurable in rules.yaml).",
+		Why:         "Without user namespaces, container UIDs are host UIDs. Low UIDs usually belong to system accounts that own daemons, logs or configuration on the node.",
+		Before: `securityContext:
+  runAsUser: 33`,
+		After: `securityContext:
+  runAsUser: 10001`,
+	},
+	"privilege-escalation-allowed": {
+		Title:       "Privilege escalation allowed",
+		Severity:    "HIGH",
+		Description: "The container sets allowPrivilegeEscalation: true.",
+		Why:         "setuid binaries and file capabilities can then raise the process's privileges above those it started with, which is how many kernel and container escape exploits get root.",
+		Before: `securityContext:
+  allowPrivilegeEscalation: true`,
+		After: `securityContext:
+  allowPrivilegeEscalation: false`,
+	},
+	"wildcard-rbac": {
+		Title:       "Wildcard RBAC permissions",
+		Severity:    "HIGH (CRITICAL for a ClusterRole spanning all API groups)",
+		Description: "A Role or ClusterRole grants verbs: [\"*\"] on resources: [\"*\"].",
+		Why:         "Anyone bound to the role can do anything the role's scope allows, including reading secrets, creating pods and editing RBAC to grant themselves more.",
+		Before: `rules:
+- apiGroups: ["*"]
+  resources: ["*"]
+  verbs: ["*"]`,
+		After: `rules:
+- apiGroups: ["apps"]
+  resources: ["deployments"]
+  verbs: ["get", "list", "watch", "update"]`,
+		References: []string{"https://kubernetes.io/docs/concepts/security/rbac-good-practices/"},
+	},
+	"wildcard-rbac-verbs": {
+		Title:       "All verbs on a sensitive resource",
+		Severity:    "HIGH",
+		Description: "A Role or ClusterRole grants verbs: [\"*\"] on a sensitive resource such as secrets, pods/exec or RBAC objects. The resource list can be replaced in rules.yaml.",
+		Why:         "All verbs on secrets means reading every credential in scope; on pods/exec it means a shell in any pod; on RBAC objects it means granting yourself anything.",
+		Before: `rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["*"]`,
+		After: `rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  resourceNames: ["app-tls"]
+  verbs: ["get"]`,
+	},
+	"wildcard-rbac-resources": {
+		Title:       "Specific verbs on every resource",
+		Severity:    "MEDIUM",
+		Description: "A Role or ClusterRole grants specific verbs on resources: [\"*\"].",
+		Why:         "Even read-only verbs on * include Secrets, and the grant silently grows to cover every resource type installed later, such as CRDs holding credentials.",
+		Before: `rules:
+- apiGroups: ["*"]
+  resources: ["*"]
+  verbs: ["get", "list"]`,
+		After: `rules:
+- apiGroups: ["apps"]
+  resources: ["deployments", "statefulsets"]
+  verbs: ["get", "list"]`,
+	},
+	"rbac-secrets-read": {
+		Title:       "Role can read Secrets",
+		Severity:    "HIGH",
+		Description: "A Role or ClusterRole grants get, list or watch on secrets without a wildcard.",
+		Why:         "list and watch return Secret contents, not just names, so the role can collect every credential and service account token in its scope.",
+		Before: `rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["get", "list"]`,
+		After: `rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  resourceNames: ["app-config"]
+  verbs: ["get"]`,
+	},
+	"rbac-pod-exec": {
+		Title:       "Role can exec into pods",
+		Severity:    "HIGH",
+		Description: "A Role or ClusterRole grants create on pods/exec.",
+		Why:         "Exec runs arbitrary commands inside running containers, with their service account tokens, mounted secrets and network access.",
+		Before: `rules:
+- apiGroups: [""]
+  resources: ["pods/exec"]
+  verbs: ["create"]`,
+		After: `# Keep exec in a separate break-glass role, bound only when needed`,
+	},
+	"rbac-impersonate": {
+		Title:       "Role can impersonate identities",
+		Severity:    "HIGH",
+		Description: "A Role or ClusterRole grants the impersonate verb on users, groups, service accounts or any other resource.",
+		Why:         "Impersonating a more privileged identity, such as a member of system:masters, grants all of its permissions.",
+		Before: `rules:
+- apiGroups: [""]
+  resources: ["users", "groups"]
+  verbs: ["impersonate"]`,
+		After: `rules:
+- apiGroups: [""]
+  resources: ["serviceaccounts"]
+  resourceNames: ["ci-readonly"]
+  verbs: ["impersonate"]`,
+	},
+	"rbac-escalate-bind": {
+		Title:       "Role can escalate or bind roles",
+		Severity:    "HIGH",
+		Description: "A Role or ClusterRole grants escalate or bind on roles or clusterroles.",
+		Why:         "These verbs switch off RBAC's escalation check: escalate edits a role to add permissions the editor lacks, and bind attaches any role, cluster-admin included, to any subject.",
+		Before: `rules:
+- apiGroups: ["rbac.authorization.k8s.io"]
+  resources: ["clusterroles"]
+  verbs: ["bind", "escalate"]`,
+		After: `# Manage role contents and bindings through cluster administrators
+# or GitOps, not through a role that grants these verbs`,
+	},
+	"clusterrolebinding-default-sa": {
+		Title:       "ClusterRoleBinding to a default service account",
+		Severity:    "HIGH",
+		Description: "A ClusterRoleBinding grants cluster-wide permissions to a namespace's default ServiceAccount.",
+		Why:         "Every pod in that namespace that doesn't name a ServiceAccount runs as default, so all of them silently inherit cluster-wide permissions.",
+		Before: `subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: app`,
+		After: `subjects:
+- kind: ServiceAccount
+  name: app-controller
+  namespace: app`,
+	},
+	"service-account-overprivileged": {
+		Title:       "Workload runs as an all-powerful service account",
+		Severity:    "HIGH",
+		Description: "The workload's ServiceAccount (default if none is named) is bound by a RoleBinding or ClusterRoleBinding in the scanned manifests to cluster-admin or a role granting all verbs on all resources. The Reason spells out the chain.",
+		Why:         "The service account token is mounted into the pod, so any code execution in the container, from an RCE to a malicious dependency, becomes full control of the namespace or cluster.",
+		Before: `kind: ClusterRoleBinding
+roleRef:
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+- kind: ServiceAccount
+  name: deployer
+  namespace: ci`,
+		After: `kind: RoleBinding
+metadata:
+  namespace: apps
+roleRef:
+  kind: Role
+  name: deployer  # verbs and resources it actually needs
+subjects:
+- kind: ServiceAccount
+  name: deployer
+  namespace: ci`,
+	},
+	"default-service-account-token": {
+		Title:       "Default service account token with bound permissions",
+		Severity:    "HIGH",
+		Description: "The workload runs as its namespace's default ServiceAccount with the token mounted, and a RoleBinding or ClusterRoleBinding in the scanned manifests grants that account a role.",
+		Why:         "Every pod that doesn't name a ServiceAccount runs as default, so a role bound to it reaches all of them, and any compromised container can use the mounted token.",
+		Before: `spec:
+  containers:
+  - name: app
+    image: app:1.2.3`,
+		After: `spec:
+  serviceAccountName: app  # dedicated account holding the binding
+  containers:
+  - name: app
+    image: app:1.2.3`,
+	},
+	"service-account-token-automount": {
+		Title:       "Service account token mounted",
+		Severity:    "MEDIUM",
+		Description: "Neither the pod nor its ServiceAccount (when scanned) sets automountServiceAccountToken: false, so a token is mounted into every container.",
+		Why:         "Most workloads never call the Kubernetes API; for them the token is only useful to an attacker who gets code execution in the container.",
+		Before: `spec:
+  containers:
+  - name: app
+    image: app:1.2.3`,
+		After: `spec:
+  automountServiceAccountToken: false
+  containers:
+  - name: app
+    image: app:1.2.3`,
+	},
+	"public-loadbalancer": {
+		Title:       "LoadBalancer in a sensitive namespace",
+		Severity:    "HIGH",
+		Description: "A Service of type LoadBalancer lives in kube-system, prod or production.",
+		Why:         "Cloud load balancers are usually internet-facing by default, exposing a sensitive service directly without the controls an ingress layer provides.",
+		Before: `spec:
+  type: LoadBalancer`,
+		After: `spec:
+  type: ClusterIP
+# expose through an Ingress with TLS and authentication`,
+	},
+	"nodeport-service": {
+		Title:       "Unjustified NodePort service",
+		Severity:    "MEDIUM",
+		Description: "A Service of type NodePort has no danger-scan/nodeport-justified annotation.",
+		Why:         "NodePorts open the port on every node, bypassing ingress controllers, WAFs and most network policies.",
+		Before: `spec:
+  type: NodePort`,
+		After: `metadata:
+  annotations:
+    danger-scan/nodeport-justified: "bare-metal ingress entrypoint"
+spec:
+  type: NodePort`,
+	},
+	"latest-image-tag": {
+		Title:       "Unpinned image tag",
+		Severity:    "MEDIUM",
+		Description: "A container image uses the :latest tag or no tag at all.",
+		Why:         "The image that runs can change without any manifest change, making deployments non-reproducible and letting a compromised upstream image roll out silently.",
+		Before:      `image: nginx:latest`,
+		After:       `image: nginx:1.25.3@sha256:<digest>`,
+	},
+	"host-network": {
+		Title:       "Host network namespace",
+		Severity:    "HIGH",
+		Description: "The pod sets hostNetwork: true.",
+		Why:         "The pod shares the node's network stack. It can bind node ports, sniff traffic and reach services listening only on localhost, such as the kubelet.",
+		Before: `spec:
+  hostNetwork: true`,
+		After: `spec:
+  hostNetwork: false`,
+	},
+	"host-pid-ipc": {
+		Title:       "Host PID or IPC namespace",
+		Severity:    "HIGH",
+		Description: "The pod sets hostPID: true or hostIPC: true.",
+		Why:         "With the host PID namespace a container can see and signal every process on the node, and read their environment. The host IPC namespace exposes shared memory of other workloads.",
+		Before: `spec:
+  hostPID: true`,
+		After: `spec:
+  hostPID: false
+  hostIPC: false`,
+	},
+	"host-port": {
+		Title:       "Container hostPort",
+		Severity:    "HIGH",
+		Description: "A container port sets hostPort, binding it directly on the node's network interfaces.",
+		Why:         "The port is reachable from anywhere that can reach the node, bypassing Services and NetworkPolicies, and limits scheduling to one such pod per node.",
+		Before: `ports:
+- containerPort: 8080
+  hostPort: 8080`,
+		After: `ports:
+- containerPort: 8080
+# expose with a Service instead`,
+	},
+	"super-pod": {
+		Title:       "Pod combining several host escape vectors",
+		Severity:    "CRITICAL",
+		Description: "The pod stacks several host-access settings such as privileged, hostPID, hostNetwork and hostPath.",
+		Why:         "Each setting alone is dangerous; together they amount to an interactive root shell on the node with no exploit required.",
+		Before: `spec:
+  hostPID: true
+  hostNetwork: true
+  containers:
+  - securityContext:
+      privileged: true`,
+		After: `# Split node-level tooling into a dedicated, audited DaemonSet that
+# requests only the single host access it needs`,
+	},
+	"exposed-privileged-workload": {
+		Title:       "Privileged workload exposed outside the cluster",
+		Severity:    "CRITICAL",
+		Description: "A workload with a privileged container is selected by a LoadBalancer or NodePort Service in the scanned manifests.",
+		Why:         "Any remote code execution bug in the exposed service lands an attacker in a privileged container, which is root on the node.",
+		Before: `kind: Service
+spec:
+  type: LoadBalancer
+  selector:
+    app: agent
+---
+# Deployment with labels app: agent
+securityContext:
+  privileged: true`,
+		After: `kind: Service
+spec:
+  type: ClusterIP  # reached through an ingress
+  selector:
+    app: agent
+---
+securityContext:
+  privileged: false`,
+	},
+
+	"sensitive-mount-path": {
+		Title:       "Writable volume over a system path",
+		Severity:    "HIGH",
+		Description: "A writable volume is mounted over a binary, system configuration directory or the service account token path.",
+		Why:         "Whoever can write to the volume can replace binaries the container executes or swap the API token it presents, hijacking the workload.",
+		Before: `volumeMounts:
+- name: shared
+  mountPath: /usr/bin`,
+		After: `volumeMounts:
+- name: shared
+  mountPath: /data
+  readOnly: true`,
+	},
+	"dangerous-capabilities": {
+		Title:       "Container adds dangerous Linux capabilities",
+		Severity:    "HIGH",
+		Description: "A container's securityContext.capabilities.add includes ALL, SYS_ADMIN, NET_ADMIN, SYS_PTRACE, SYS_MODULE, SYS_RAWIO, DAC_READ_SEARCH or BPF. Names are matched with or without the CAP_ prefix.",
+		Why:         "SYS_ADMIN alone allows mounting host filesystems and is close to privileged mode; SYS_MODULE loads kernel code, SYS_PTRACE reads the memory of other processes, NET_ADMIN rewrites the node's network, and DAC_READ_SEARCH reads any file handle on the host. Each is a well-documented container escape step.",
+		Before: `securityContext:
+  capabilities:
+    add: ["SYS_ADMIN", "NET_ADMIN"]`,
+		After: `securityContext:
+  capabilities:
+    drop: ["ALL"]
+    add: ["NET_BIND_SERVICE"]`,
+	},
+	"route-without-tls": {
+		Title:       "OpenShift Route without TLS",
+		Severity:    "MEDIUM",
+		Description: "A Route has no spec.tls termination, or allows plain HTTP with insecureEdgeTerminationPolicy: Allow.",
+		Why:         "Traffic, including credentials and session cookies, crosses the network unencrypted and can be read or modified in transit.",
+		Before: `spec:
+  host: app.example.com`,
+		After: `spec:
+  host: app.example.com
+  tls:
+    termination: edge
+    insecureEdgeTerminationPolicy: Redirect`,
+	},
+	"envfrom-without-checksum": {
+		Title:       "envFrom without a config checksum",
+		Severity:    "MEDIUM",
+		Description: "A workload loads a ConfigMap or Secret through envFrom, but its pod template has no checksum/* annotation.",
+		Why:         "Environment variables are read once at container start. Changing the ConfigMap leaves running pods on stale values until something else restarts them.",
+		Before: `template:
+  spec:
+    containers:
+    - envFrom:
+      - configMapRef:
+          name: app-config`,
+		After: `template:
+  metadata:
+    annotations:
+      checksum/config: "<sha256 of app-config>"`,
+	},
+	"job-without-limits": {
+		Title:       "Job without retry or deadline limits",
+		Severity:    "MEDIUM",
+		Description: "A Job, or a CronJob's job template, lacks activeDeadlineSeconds or sets backoffLimit above 10. An unset backoffLimit defaults to 6 and is accepted.",
+		Why:         "A hung Job runs forever and a Job with a large retry budget keeps failing for hours, holding resources and hiding the failure.",
+		Before: `spec:
+  template: ...`,
+		After: `spec:
+  backoffLimit: 3
+  activeDeadlineSeconds: 600
+  template: ...`,
+	},
+	"cronjob-concurrent-runs": {
+		Title:       "CronJob allows overlapping runs",
+		Severity:    "MEDIUM",
+		Description: "A CronJob's concurrencyPolicy is Allow or unset.",
+		Why:         "If a run takes longer than the schedule interval the next one starts anyway, so slow runs pile up and contend for the same data.",
+		Before: `spec:
+  schedule: "*/5 * * * *"`,
+		After: `spec:
+  schedule: "*/5 * * * *"
+  concurrencyPolicy: Forbid`,
+	},
+	"stale-image-pull-policy": {
+		Title:       "Mutable image with a cached pull policy",
+		Severity:    "MEDIUM",
+		Description: "A :latest or untagged image uses imagePullPolicy IfNotPresent or Never.",
+		Why:         "Nodes keep whatever copy of the tag they pulled first, so different nodes run different code under the same tag.",
+		Before: `image: app:latest
+imagePullPolicy: IfNotPresent`,
+		After: `image: app:1.2.3
+imagePullPolicy: IfNotPresent`,
+	},
+	"redundant-image-pull": {
+		Title:       "Digest-pinned image always pulled",
+		Severity:    "LOW",
+		Description: "An image pinned by digest uses imagePullPolicy: Always.",
+		Why:         "A digest never changes, so every container start makes a needless registry round-trip and fails if the registry is unreachable.",
+		Before: `image: app@sha256:<digest>
+imagePullPolicy: Always`,
+		After: `image: app@sha256:<digest>
+imagePullPolicy: IfNotPresent`,
+	},
+	"replicas-not-spread": {
+		Title:       "Replicas not spread across nodes",
+		Severity:    "MEDIUM",
+		Description: "A multi-replica workload has neither podAntiAffinity nor topologySpreadConstraints.",
+		Why:         "The scheduler is free to put every replica on the same node or zone, so a single node failure takes the whole service down.",
+		Before: `spec:
+  replicas: 3`,
+		After: `spec:
+  replicas: 3
+  template:
+    spec:
+      topologySpreadConstraints:
+      - maxSkew: 1
+        topologyKey: kubernetes.io/hostname
+        whenUnsatisfiable: ScheduleAnyway
+        labelSelector:
+          matchLabels:
+            app: web`,
+	},
+	"memory-emptydir-without-limit": {
+		Title:       "Memory-backed emptyDir without a size limit",
+		Severity:    "MEDIUM",
+		Description: "An emptyDir volume sets medium: Memory but no sizeLimit.",
+		Why:         "A memory-backed emptyDir is a tmpfs whose contents count as node memory. Without a limit a runaway writer can exhaust the node and trigger OOM kills of unrelated pods.",
+		Before: `volumes:
+- name: cache
+  emptyDir:
+    medium: Memory`,
+		After: `volumes:
+- name: cache
+  emptyDir:
+    medium: Memory
+    sizeLimit: 256Mi`,
+	},
+	"privileged-port-without-capability": {
+		Title:       "Privileged port bound by a non-root container",
+		Severity:    "MEDIUM",
+		Description: "A container runs as non-root and declares a containerPort below 1024, but does not add NET_BIND_SERVICE. A pod sysctl net.ipv4.ip_unprivileged_port_start that lowers the privileged range is taken into account.",
+		Why:         "Only processes holding CAP_NET_BIND_SERVICE may bind ports below 1024. The bind fails with EACCES at startup and the pod never becomes ready.",
+		Before: `securityContext:
+  runAsNonRoot: true
+ports:
+- containerPort: 80`,
+		After: `securityContext:
+  runAsNonRoot: true
+ports:
+- containerPort: 8080  # Service maps port 80 to 8080`,
+	},
+	"short-termination-grace-period": {
+		Title:       "Pods killed without a graceful shutdown",
+		Severity:    "MEDIUM",
+		Description: "The pod spec sets terminationGracePeriodSeconds: 0, or a StatefulSet sets it below 10 seconds. For StatefulSets the finding also notes a missing preStop hook.",
+		Why:         "With no grace period the kubelet sends SIGKILL straight away on every eviction, rollout and node drain. Databases and queues lose in-flight writes and can be left with corrupted files.",
+		Before: `spec:
+  terminationGracePeriodSeconds: 0`,
+		After: `spec:
+  terminationGracePeriodSeconds: 60
+  containers:
+  - name: db
+    lifecycle:
+      preStop:
+        exec:
+          command: ["pg_ctl", "stop", "-m", "fast"]`,
+	},
+	"no-resource-limits": {
+		Title:       "Container without CPU and memory limits",
+		Severity:    "MEDIUM",
+		Description: "A container does not set resources.limits.cpu or resources.limits.memory. With requests: true in rules.yaml, missing resources.requests.cpu and resources.requests.memory are flagged too.",
+		Why:         "Without limits a container may use all of a node's CPU and memory. A leak or traffic spike in one workload then throttles or OOM-kills every other pod on the node, and without requests the scheduler can't place pods where capacity actually exists.",
+		Before: `containers:
+- name: app
+  image: registry.example.com/app:1.4.2`,
+		After: `containers:
+- name: app
+  image: registry.example.com/app:1.4.2
+  resources:
+    requests:
+      cpu: 250m
+      memory: 256Mi
+    limits:
+      cpu: "1"
+      memory: 512Mi`,
+	},
+	"missing-config-reference": {
+		Title:       "Env var read from a Secret or ConfigMap that isn't defined",
+		Severity:    "MEDIUM",
+		Description: "A container's env valueFrom or envFrom names a Secret or ConfigMap that is not among the scanned manifests, while other objects of that kind in the same namespace are defined in the workload's directory. References marked optional: true are skipped.",
+		Why:         "The kubelet refuses to start a container whose required Secret or ConfigMap is missing, so every pod of the rollout sits in CreateContainerConfigError. A typo in the name or a file left out of the change is enough.",
+		Before: `env:
+- name: DB_PASSWORD
+  valueFrom:
+    secretKeyRef:
+      name: db-credentails
+      key: password`,
+		After: `env:
+- name: DB_PASSWORD
+  valueFrom:
+    secretKeyRef:
+      name: db-credentials
+      key: password`,
+	},
+	"capabilities-not-dropped": {
+		Title:       "Default capabilities kept",
+		Severity:    "MEDIUM",
+		Description: "The container does not drop ALL Linux capabilities.",
+		Why:         "The runtime's default capability set includes powers like NET_RAW and CHOWN that most applications never need but exploits happily use.",
+		Before:      `securityContext: {}`,
+		After: `securityContext:
+  capabilities:
+    drop: ["ALL"]`,
+	},
+	"writable-root-filesystem": {
+		Title:       "Writable root filesystem",
+		Severity:    "MEDIUM",
+		Description: "The container does not set readOnlyRootFilesystem: true. The field only exists on the container securityContext; setting it on the pod has no effect.",
+		Why:         "A writable root filesystem lets an attacker who gets code execution replace binaries, edit configuration and persist tooling inside the container.",
+		Before: `securityContext:
+  allowPrivilegeEscalation: false`,
+		After: `securityContext:
+  allowPrivilegeEscalation: false
+  readOnlyRootFilesystem: true
+# mount an emptyDir at paths the app must write, such as /tmp`,
+	},
+	"namespace-without-networkpolicy": {
+		Title:       "Namespace without NetworkPolicy",
+		Severity:    "MEDIUM",
+		Description: "A namespace runs workloads, but no NetworkPolicy in the scanned manifests applies to it.",
+		Why:         "Pods accept traffic from every other pod by default, so a single compromised workload anywhere in the cluster can reach these services directly.",
+		Before:      `# Deployments in namespace apps, no NetworkPolicy`,
+		After: `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: default-deny
+  namespace: apps
+spec:
+  podSelector: {}
+  policyTypes: ["Ingress"]`,
+	},
+	"namespace-without-default-deny": {
+		Title:       "Namespace without default-deny NetworkPolicy",
+		Severity:    "LOW",
+		Description: "A namespace has NetworkPolicies, but none selects every pod and denies ingress.",
+		Why:         "NetworkPolicies only restrict the pods they select; pods no policy matches, including ones added later, stay open to all traffic.",
+		Before: `spec:
+  podSelector:
+    matchLabels:
+      app: api
+  ingress:
+  - from:
+    - podSelector:
+        matchLabels:
+          app: web`,
+		After: `# Keep the allow rules and add:
+spec:
+  podSelector: {}
+  policyTypes: ["Ingress"]`,
+	},
+	"missing-security-context": {
+		Title:       "No securityContext at all",
+		Severity:    "MEDIUM",
+		Description: "Neither the container nor the pod sets a securityContext. Reported in --strict mode only.",
+		Why:         "Every runtime default applies: root user, default capabilities, writable root filesystem and privilege escalation allowed.",
+		Before: `containers:
+- name: app
+  image: app:1.2.3`,
+		After: `containers:
+- name: app
+  image: app:1.2.3
+  securityContext:
+    runAsNonRoot: true
+    allowPrivilegeEscalation: false
+    capabilities:
+      drop: ["ALL"]`,
+	},
+	"privilege-escalation-not-disabled": {
+		Title:       "Privilege escalation not disabled",
+		Severity:    "MEDIUM",
+		Description: "The container does not set allowPrivilegeEscalation, so the runtime allows it. Reported in --strict mode only.",
+		Why:         "Without no_new_privs, a setuid binary or file capability in the image lets a compromised process gain privileges it was never given.",
+		Before: `securityContext:
+  runAsNonRoot: true`,
+		After: `securityContext:
+  runAsNonRoot: true
+  allowPrivilegeEscalation: false`,
+	},
+	"default-namespace": {
+		Title:       "Workload in the default namespace",
+		Severity:    "MEDIUM",
+		Description: "The resource has no namespace or uses default.",
+		Why:         "The default namespace rarely has quotas, NetworkPolicies or RBAC boundaries, and mixing unrelated workloads there makes ownership unclear.",
+		Before: `metadata:
+  name: app`,
+		After: `metadata:
+  name: app
+  namespace: payments`,
+	},
+	"weak-secret-value": {
+		Title:       "Placeholder secret value",
+		Severity:    "MEDIUM",
+		Description: "A committed Secret has an empty or well-known placeholder value such as changeme or password.",
+		Why:         "Placeholder credentials tend to reach production unchanged and are the first thing an attacker tries.",
+		Before: `stringData:
+  password: changeme`,
+		After: `# Generate the secret outside git, e.g. with an external secrets
+# operator or sealed-secrets`,
+	},
+	"secret-in-env": {
+		Title:       "Plaintext secret in container env",
+		Severity:    "HIGH",
+		Description: "A container env entry has a literal value under a credential-like name (PASSWORD, TOKEN, SECRET, API_KEY and similar) or a high-entropy value that looks like a generated key.",
+		Why:         "Literal env values live in the manifest, its git history and the pod spec, where anyone with read access to the workload or the repository can copy them.",
+		Before: `env:
+- name: DB_PASSWORD
+  value: s3cr3t-prod`,
+		After: `env:
+- name: DB_PASSWORD
+  valueFrom:
+    secretKeyRef:
+      name: db-credentials
+      key: password`,
+	},
+	"secret-volume-permissive-mode": {
+		Title:       "Group or world readable secret volume",
+		Severity:    "MEDIUM",
+		Description: "A Secret volume sets defaultMode or an item mode that grants group or other permissions (mode & 0077 != 0). Projected secret sources are checked too.",
+		Why:         "Any process in the pod that runs as another user or shares the group can read the mounted keys, so one compromised sidecar exposes every secret in the volume.",
+		Before: `volumes:
+- name: tls
+  secret:
+    secretName: tls
+    defaultMode: 0644`,
+		After: `volumes:
+- name: tls
+  secret:
+    secretName: tls
+    defaultMode: 0400`,
+	},
+	"image-not-digest-pinned": {
+		Title:       "Image not pinned by digest",
+		Severity:    "MEDIUM",
+		Description: "A container image is referenced by tag only, even if the tag is a specific version. Opt-in via --categories supply-chain.",
+		Why:         "Tags are mutable pointers: a compromised registry account or a careless re-push changes what runs without any manifest change. A digest names exactly one image.",
+		Before:      `image: registry.example.com/app:1.4.2`,
+		After:       `image: registry.example.com/app:1.4.2@sha256:3f1a...`,
+	},
+	"shell-entrypoint": {
+		Title:       "Inline shell entrypoint",
+		Severity:    "MEDIUM",
+		Description: "The container command runs an inline script via sh -c or a similar shell.",
+		Why:         "The real workload is hidden inside a string, so image scanners and reviewers can't see what runs, and signals are swallowed by the shell instead of reaching the process.",
+		Before:      `command: ["sh", "-c", "migrate && exec server"]`,
+		After:       `command: ["/app/entrypoint"]`,
+	},
+}
@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// maxListedWorkloads caps how many workloads a namespace finding names
+const maxListedWorkloads = 3
+
+// namespacePolicies is what CheckNetworkPolicyCoverage learns about one
+// namespace
+type namespacePolicies struct {
+	workloads   []string // Kind/name of each workload, in scan order
+	policies    int
+	defaultDeny bool
+}
+
+// CheckNetworkPolicyCoverage flags namespaces that run workloads but have no
+// NetworkPolicy in the scanned manifests (namespace-without-networkpolicy),
+// or only policies that leave some pods open to all ingress
+// (namespace-without-default-deny). Findings name the namespace, so they
+// point at its Namespace manifest when one is scanned.
+func CheckNetworkPolicyCoverage(resources []parser.K8sResource) []types.Finding {
+	namespaces := make(map[string]*namespacePolicies)
+	get := func(namespace string) *namespacePolicies {
+		if namespaces[namespace] == nil {
+			namespaces[namespace] = &namespacePolicies{}
+		}
+		return namespaces[namespace]
+	}
+
+	for _, resource := range resources {
+		if resource.Kind == "NetworkPolicy" {
+			ns := get(namespaceOf(resource))
+			ns.policies++
+			if isDefaultDenyIngress(resource) {
+				ns.defaultDeny = true
+			}
+			continue
+		}
+		if _, ok := parser.GetPodSpec(resource); ok {
+			ns := get(namespaceOf(resource))
+			ns.workloads = append(ns.workloads, resource.Kind+"/"+resource.Metadata.Name)
+		}
+	}
+
+	names := make([]string, 0, len(namespaces))
+	for name := range namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []types.Finding
+	for _, name := range names {
+		ns := namespaces[name]
+		if len(ns.workloads) == 0 || ns.defaultDeny {
+			continue
+		}
+
+		workloads := describeWorkloads(ns.workloads)
+		if ns.policies == 0 {
+			findings = append(findings, types.Finding{
+				RuleID:   "namespace-without-networkpolicy",
+				Severity: types.Medium,
+				Kind:     "Namespace",
+				Name:     name,
+				Reason:   fmt.Sprintf("Namespace %s runs %s but has no NetworkPolicy", name, workloads),
+				Impact:   "Every pod accepts traffic from any pod in the cluster, so one compromised workload can reach all of them",
+				Fix:      fmt.Sprintf("Add a default-deny NetworkPolicy to namespace %s and allow only the traffic each workload needs", name),
+			})
+			continue
+		}
+
+		findings = append(findings, types.Finding{
+			RuleID:   "namespace-without-default-deny",
+			Severity: types.Low,
+			Kind:     "Namespace",
+			Name:     name,
+			Reason:   fmt.Sprintf("Namespace %s runs %s and has %d NetworkPolicy object(s), none of them a default deny for ingress", name, workloads, ns.policies),
+			Impact:   "Pods that no policy selects, including ones added later, accept traffic from anywhere",
+			Fix:      "Add a NetworkPolicy with podSelector: {} and policyTypes: [Ingress] and no ingress rules",
+		})
+	}
+
+	return findings
+}
+
+// isDefaultDenyIngress reports whether a NetworkPolicy selects every pod in
+// its namespace and allows no ingress. A policy without policyTypes applies
+// to ingress.
+func isDefaultDenyIngress(policy parser.K8sResource) bool {
+	if !selectsAllPods(policy.Spec["podSelector"]) {
+		return false
+	}
+
+	if policyTypes, ok := policy.Spec["policyTypes"].([]interface{}); ok {
+		ingress := false
+		for _, t := range policyTypes {
+			if name, ok := t.(string); ok && name == "Ingress" {
+				ingress = true
+			}
+		}
+		if !ingress {
+			return false
+		}
+	}
+
+	rules, _ := policy.Spec["ingress"].([]interface{})
+	return len(rules) == 0
+}
+
+// selectsAllPods reports whether a label selector is empty. An omitted
+// selector, matchLabels: {} and matchExpressions: [] all select everything.
+func selectsAllPods(selector interface{}) bool {
+	if selector == nil {
+		return true
+	}
+	fields, ok := selector.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, value := range fields {
+		switch v := value.(type) {
+		case nil:
+		case map[string]interface{}:
+			if len(v) > 0 {
+				return false
+			}
+		case []interface{}:
+			if len(v) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// describeWorkloads names up to maxListedWorkloads workloads for a finding
+func describeWorkloads(workloads []string) string {
+	if len(workloads) <= maxListedWorkloads {
+		return strings.Join(workloads, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(workloads[:maxListedWorkloads], ", "), len(workloads)-maxListedWorkloads)
+}
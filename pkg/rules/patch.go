@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// pathIndex matches a list index in a finding path
+var pathIndex = regexp.MustCompile(`\[(\d+)\]`)
+
+// jsonPointer converts a finding path such as spec.template.spec.containers[0]
+// into the RFC 6901 pointer /spec/template/spec/containers/0
+func jsonPointer(path string) string {
+	path = pathIndex.ReplaceAllString(path, ".$1")
+	var b strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		part = strings.ReplaceAll(part, "~", "~0")
+		b.WriteString("/" + strings.ReplaceAll(part, "/", "~1"))
+	}
+	return b.String()
+}
+
+// replaceField patches the field at path from current to value. The test
+// operation makes the patch fail cleanly once the manifest has changed.
+func replaceField(path string, current, value interface{}) *types.Patch {
+	pointer := jsonPointer(path)
+	return &types.Patch{
+		Type: types.PatchJSON,
+		Patch: []types.PatchOperation{
+			{Op: "test", Path: pointer, Value: current},
+			{Op: "replace", Path: pointer, Value: value},
+		},
+	}
+}
+
+// addField sets the field at path, adding it or replacing its value
+func addField(path string, value interface{}) *types.Patch {
+	return &types.Patch{
+		Type:  types.PatchJSON,
+		Patch: []types.PatchOperation{{Op: "add", Path: jsonPointer(path), Value: value}},
+	}
+}
+
+// setSecurityContextField sets key in the securityContext of the container
+// or pod spec at path, adding the securityContext when element has none
+func setSecurityContextField(path string, element map[string]interface{}, key string, value interface{}) *types.Patch {
+	op := types.PatchOperation{Op: "add", Path: jsonPointer(path + ".securityContext." + key), Value: value}
+	if _, ok := element["securityContext"].(map[string]interface{}); !ok {
+		op = types.PatchOperation{
+			Op:    "add",
+			Path:  jsonPointer(path + ".securityContext"),
+			Value: map[string]interface{}{key: value},
+		}
+	}
+	return &types.Patch{Type: types.PatchJSON, Patch: []types.PatchOperation{op}}
+}
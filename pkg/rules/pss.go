@@ -0,0 +1,696 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// PSSRules returns the rules that evaluate pod specs, and PodSecurityPolicies,
+// against the controls of the given Pod Security Standards level. The
+// restricted level includes every baseline control.
+func PSSRules(level types.PSSLevel) []Rule {
+	return inCategory(types.CategoryCompliance, PSSRule(level))
+}
+
+// PSSRule returns a rule that reports one finding per Pod Security Standards
+// control the resource fails at the given level. Findings are tagged with the
+// lowest level that enforces the failed control.
+func PSSRule(level types.PSSLevel) Rule {
+	return func(resource parser.K8sResource) []types.Finding {
+		isPolicy := resource.Kind == "PodSecurityPolicy"
+
+		var pod pssPod
+		if !isPolicy {
+			podSpec, ok := parser.GetPodSpec(resource)
+			if !ok {
+				return nil
+			}
+			pod = pssPod{
+				spec:        podSpec,
+				annotations: podAnnotations(resource),
+				containers:  allContainers(podSpec),
+			}
+		}
+
+		var findings []types.Finding
+		for _, control := range pssControls {
+			if control.level.Rank() > level.Rank() {
+				continue
+			}
+
+			var violation string
+			if isPolicy {
+				if control.checkPolicy == nil {
+					continue
+				}
+				violation = control.checkPolicy(resource.Spec)
+			} else {
+				violation = control.check(pod)
+			}
+
+			if violation == "" {
+				continue
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "pss-" + control.id,
+				Severity:  types.High,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Reason:    fmt.Sprintf("Fails Pod Security Standards %s control %q: %s", control.level, control.name, violation),
+				Impact:    control.impact,
+				Fix:       control.fix,
+				PSSLevel:  control.level,
+			})
+		}
+
+		return findings
+	}
+}
+
+// PSSRuleIDs returns the rule IDs reported for failed Pod Security Standards controls
+func PSSRuleIDs() []string {
+	ids := make([]string, len(pssControls))
+	for i, control := range pssControls {
+		ids[i] = "pss-" + control.id
+	}
+	return ids
+}
+
+// explainPSS builds the explain output for a Pod Security Standards control
+func explainPSS(ruleID string) (Explanation, bool) {
+	for _, control := range pssControls {
+		if "pss-"+control.id != ruleID {
+			continue
+		}
+		return Explanation{
+			RuleID:      ruleID,
+			Title:       fmt.Sprintf("Pod Security Standards: %s", control.name),
+			Severity:    "HIGH",
+			Description: fmt.Sprintf("The pod fails the %q control of the %s Pod Security Standard. Reported only with --pss-level %s or stricter.", control.name, control.level, control.level),
+			Why:         control.impact + ".",
+			Before:      "# A pod spec failing the control, as described in the finding's Reason",
+			After:       "# " + control.fix,
+			References:  []string{"https://kubernetes.io/docs/concepts/security/pod-security-standards/"},
+		}, true
+	}
+	return Explanation{}, false
+}
+
+// pssPod is the pod-level view a Pod Security Standards control inspects
+type pssPod struct {
+	spec        map[string]interface{}
+	annotations map[string]string
+	containers  []map[string]interface{}
+}
+
+// pssControl is a single Pod Security Standards control. check inspects a pod
+// spec; checkPolicy, if set, inspects whether a PodSecurityPolicy permits pods
+// that would fail the control. Both return a description of the violation,
+// or "" if the control passes.
+type pssControl struct {
+	id          string
+	name        string
+	level       types.PSSLevel
+	check       func(pod pssPod) string
+	checkPolicy func(spec map[string]interface{}) string
+	impact      string
+	fix         string
+}
+
+// pssBaselineCapabilities may be added to containers at the baseline level
+var pssBaselineCapabilities = map[string]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+// pssSafeSysctls are the sysctls pods may set at the baseline level
+var pssSafeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.ip_local_reserved_ports":    true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.ping_group_range":           true,
+	"net.ipv4.tcp_syncookies":             true,
+	"net.ipv4.tcp_keepalive_time":         true,
+	"net.ipv4.tcp_fin_timeout":            true,
+	"net.ipv4.tcp_keepalive_intvl":        true,
+	"net.ipv4.tcp_keepalive_probes":       true,
+}
+
+// pssSELinuxTypes are the SELinux types pods may request at the baseline level
+var pssSELinuxTypes = map[string]bool{
+	"":                   true,
+	"container_t":        true,
+	"container_init_t":   true,
+	"container_kvm_t":    true,
+	"container_engine_t": true,
+}
+
+// pssRestrictedVolumes are the only volume types allowed at the restricted level
+var pssRestrictedVolumes = map[string]bool{
+	"configMap":             true,
+	"csi":                   true,
+	"downwardAPI":           true,
+	"emptyDir":              true,
+	"ephemeral":             true,
+	"persistentVolumeClaim": true,
+	"projected":             true,
+	"secret":                true,
+}
+
+// pssControls lists the Pod Security Standards controls in the order they are
+// documented upstream
+var pssControls = []pssControl{
+	{
+		id:    "host-process",
+		name:  "HostProcess",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			if hostProcess(pod.spec["securityContext"]) {
+				return "pod runs as a Windows HostProcess"
+			}
+			for _, c := range pod.containers {
+				if hostProcess(c["securityContext"]) {
+					return fmt.Sprintf("container %s runs as a Windows HostProcess", containerName(c))
+				}
+			}
+			return ""
+		},
+		impact: "HostProcess containers run directly on the Windows host with full access",
+		fix:    "Remove windowsOptions.hostProcess",
+	},
+	{
+		id:    "host-namespaces",
+		name:  "Host Namespaces",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			return enabledFlags(pod.spec, "hostNetwork", "hostPID", "hostIPC")
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			return enabledFlags(spec, "hostNetwork", "hostPID", "hostIPC")
+		},
+		impact: "Sharing host namespaces exposes host processes and network traffic to the pod",
+		fix:    "Remove hostNetwork, hostPID and hostIPC",
+	},
+	{
+		id:    "privileged",
+		name:  "Privileged Containers",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			for _, c := range pod.containers {
+				if sc, ok := c["securityContext"].(map[string]interface{}); ok {
+					if privileged, _ := sc["privileged"].(bool); privileged {
+						return fmt.Sprintf("container %s is privileged", containerName(c))
+					}
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			if privileged, _ := spec["privileged"].(bool); privileged {
+				return "policy allows privileged containers"
+			}
+			return ""
+		},
+		impact: "Privileged containers disable most isolation and can take over the node",
+		fix:    "Remove privileged: true",
+	},
+	{
+		id:    "capabilities",
+		name:  "Capabilities",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			for _, c := range pod.containers {
+				add, _ := capabilities(c)
+				for _, capability := range add {
+					if !pssBaselineCapabilities[capability] {
+						return fmt.Sprintf("container %s adds %s", containerName(c), capability)
+					}
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			for _, capability := range stringSlice(spec["allowedCapabilities"]) {
+				if capability == "*" || !pssBaselineCapabilities[strings.ToUpper(capability)] {
+					return fmt.Sprintf("policy allows adding %s", capability)
+				}
+			}
+			return ""
+		},
+		impact: "Extra capabilities grant kernel-level powers commonly used in container escapes",
+		fix:    "Drop ALL capabilities and add back only NET_BIND_SERVICE if needed",
+	},
+	{
+		id:    "hostpath-volumes",
+		name:  "HostPath Volumes",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			for _, name := range volumeTypes(pod.spec) {
+				if name == "hostPath" {
+					return "pod mounts a hostPath volume"
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			for _, volume := range stringSlice(spec["volumes"]) {
+				if volume == "*" || volume == "hostPath" {
+					return fmt.Sprintf("policy allows %s volumes", volume)
+				}
+			}
+			return ""
+		},
+		impact: "hostPath volumes give the pod direct access to the node's filesystem",
+		fix:    "Replace hostPath volumes with PersistentVolumeClaims or emptyDir",
+	},
+	{
+		id:    "host-ports",
+		name:  "Host Ports",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			for _, c := range pod.containers {
+				ports, _ := c["ports"].([]interface{})
+				for _, p := range ports {
+					port, _ := p.(map[string]interface{})
+					if hostPort, _ := port["hostPort"].(int); hostPort != 0 {
+						return fmt.Sprintf("container %s binds hostPort %d", containerName(c), hostPort)
+					}
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			if ranges, ok := spec["hostPorts"].([]interface{}); ok && len(ranges) > 0 {
+				return "policy allows host ports"
+			}
+			return ""
+		},
+		impact: "Host ports expose the container on the node's interfaces, bypassing Services and NetworkPolicies",
+		fix:    "Remove hostPort and expose the container through a Service",
+	},
+	{
+		id:    "apparmor",
+		name:  "AppArmor",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			for key, value := range pod.annotations {
+				if strings.HasPrefix(key, "container.apparmor.security.beta.kubernetes.io/") &&
+					value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+					return fmt.Sprintf("annotation %s is %s", key, value)
+				}
+			}
+			if appArmorUnconfined(pod.spec["securityContext"]) {
+				return "pod AppArmor profile is Unconfined"
+			}
+			for _, c := range pod.containers {
+				if appArmorUnconfined(c["securityContext"]) {
+					return fmt.Sprintf("container %s AppArmor profile is Unconfined", containerName(c))
+				}
+			}
+			return ""
+		},
+		impact: "Disabling AppArmor removes a mandatory access control layer around the container",
+		fix:    "Use the RuntimeDefault or a Localhost AppArmor profile",
+	},
+	{
+		id:    "selinux",
+		name:  "SELinux",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			if v := seLinuxViolation(pod.spec["securityContext"]); v != "" {
+				return "pod " + v
+			}
+			for _, c := range pod.containers {
+				if v := seLinuxViolation(c["securityContext"]); v != "" {
+					return fmt.Sprintf("container %s %s", containerName(c), v)
+				}
+			}
+			return ""
+		},
+		impact: "Custom SELinux users, roles or types can lift the confinement applied to containers",
+		fix:    "Remove seLinuxOptions.user and role, and use a standard container type",
+	},
+	{
+		id:    "proc-mount",
+		name:  "/proc Mount Type",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			for _, c := range pod.containers {
+				if sc, ok := c["securityContext"].(map[string]interface{}); ok {
+					if procMount, _ := sc["procMount"].(string); procMount != "" && procMount != "Default" {
+						return fmt.Sprintf("container %s uses procMount %s", containerName(c), procMount)
+					}
+				}
+			}
+			return ""
+		},
+		impact: "An unmasked /proc exposes host kernel information and tunables",
+		fix:    "Remove procMount or set it to Default",
+	},
+	{
+		id:    "seccomp",
+		name:  "Seccomp",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			if seccompType(pod.spec["securityContext"]) == "Unconfined" {
+				return "pod seccomp profile is Unconfined"
+			}
+			for _, c := range pod.containers {
+				if seccompType(c["securityContext"]) == "Unconfined" {
+					return fmt.Sprintf("container %s seccomp profile is Unconfined", containerName(c))
+				}
+			}
+			return ""
+		},
+		impact: "Without seccomp filtering the container can invoke every syscall, widening the kernel attack surface",
+		fix:    "Set securityContext.seccompProfile.type: RuntimeDefault on the pod",
+	},
+	{
+		id:    "sysctls",
+		name:  "Sysctls",
+		level: types.PSSBaseline,
+		check: func(pod pssPod) string {
+			sc, _ := pod.spec["securityContext"].(map[string]interface{})
+			sysctls, _ := sc["sysctls"].([]interface{})
+			for _, s := range sysctls {
+				sysctl, _ := s.(map[string]interface{})
+				if name, _ := sysctl["name"].(string); !pssSafeSysctls[name] {
+					return fmt.Sprintf("pod sets unsafe sysctl %s", name)
+				}
+			}
+			return ""
+		},
+		impact: "Unsafe sysctls change kernel behavior for every pod on the node",
+		fix:    "Remove sysctls outside the safe set",
+	},
+	{
+		id:    "volume-types",
+		name:  "Volume Types",
+		level: types.PSSRestricted,
+		check: func(pod pssPod) string {
+			for _, name := range volumeTypes(pod.spec) {
+				if !pssRestrictedVolumes[name] {
+					return fmt.Sprintf("pod uses a %s volume", name)
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			for _, volume := range stringSlice(spec["volumes"]) {
+				if volume == "*" || !pssRestrictedVolumes[volume] {
+					return fmt.Sprintf("policy allows %s volumes", volume)
+				}
+			}
+			return ""
+		},
+		impact: "Volume types outside the restricted set can reach node or network storage directly",
+		fix:    "Use only configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected and secret volumes",
+	},
+	{
+		id:    "privilege-escalation",
+		name:  "Privilege Escalation",
+		level: types.PSSRestricted,
+		check: func(pod pssPod) string {
+			for _, c := range pod.containers {
+				sc, _ := c["securityContext"].(map[string]interface{})
+				if allow, ok := sc["allowPrivilegeEscalation"].(bool); !ok || allow {
+					return fmt.Sprintf("container %s does not set allowPrivilegeEscalation: false", containerName(c))
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			if allow, ok := spec["allowPrivilegeEscalation"].(bool); !ok || allow {
+				return "policy allows privilege escalation"
+			}
+			return ""
+		},
+		impact: "setuid binaries can raise the process's privileges above those it started with",
+		fix:    "Set allowPrivilegeEscalation: false on every container",
+	},
+	{
+		id:    "run-as-non-root",
+		name:  "Running as Non-root",
+		level: types.PSSRestricted,
+		check: func(pod pssPod) string {
+			podSC, _ := pod.spec["securityContext"].(map[string]interface{})
+			podNonRoot, _ := podSC["runAsNonRoot"].(bool)
+			for _, c := range pod.containers {
+				nonRoot := podNonRoot
+				if sc, ok := c["securityContext"].(map[string]interface{}); ok {
+					if val, ok := sc["runAsNonRoot"].(bool); ok {
+						nonRoot = val
+					}
+				}
+				if !nonRoot {
+					return fmt.Sprintf("container %s does not set runAsNonRoot: true", containerName(c))
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			runAsUser, _ := spec["runAsUser"].(map[string]interface{})
+			if rule, _ := runAsUser["rule"].(string); rule != "MustRunAsNonRoot" {
+				return "policy does not require MustRunAsNonRoot"
+			}
+			return ""
+		},
+		impact: "Containers may run as root, which is root on the host kernel",
+		fix:    "Set runAsNonRoot: true in the pod securityContext",
+	},
+	{
+		id:    "seccomp-restricted",
+		name:  "Seccomp (v1.19+)",
+		level: types.PSSRestricted,
+		check: func(pod pssPod) string {
+			if seccompType(pod.spec["securityContext"]) != "" {
+				return ""
+			}
+			for _, c := range pod.containers {
+				if seccompType(c["securityContext"]) == "" {
+					return fmt.Sprintf("container %s sets no seccomp profile", containerName(c))
+				}
+			}
+			return ""
+		},
+		impact: "Without seccomp filtering the container can invoke every syscall, widening the kernel attack surface",
+		fix:    "Set securityContext.seccompProfile.type: RuntimeDefault on the pod",
+	},
+	{
+		id:    "capabilities-restricted",
+		name:  "Capabilities (v1.22+)",
+		level: types.PSSRestricted,
+		check: func(pod pssPod) string {
+			for _, c := range pod.containers {
+				add, drop := capabilities(c)
+				for _, capability := range add {
+					if capability != "NET_BIND_SERVICE" {
+						return fmt.Sprintf("container %s adds %s (only NET_BIND_SERVICE is allowed)", containerName(c), capability)
+					}
+				}
+				if !containsFold(drop, "ALL") {
+					return fmt.Sprintf("container %s does not drop ALL capabilities", containerName(c))
+				}
+			}
+			return ""
+		},
+		checkPolicy: func(spec map[string]interface{}) string {
+			if !containsFold(stringSlice(spec["requiredDropCapabilities"]), "ALL") {
+				return "policy does not require dropping ALL capabilities"
+			}
+			return ""
+		},
+		impact: "Capabilities kept by default include powers like NET_RAW that exploits rely on",
+		fix:    "Set capabilities.drop: [ALL] and add back only NET_BIND_SERVICE if needed",
+	},
+	{
+		id:    "run-as-user",
+		name:  "Running as Non-root user",
+		level: types.PSSRestricted,
+		check: func(pod pssPod) string {
+			podSC, _ := pod.spec["securityContext"].(map[string]interface{})
+			if uid, ok := podSC["runAsUser"].(int); ok && uid == 0 {
+				return "pod sets runAsUser: 0"
+			}
+			for _, c := range pod.containers {
+				sc, _ := c["securityContext"].(map[string]interface{})
+				if uid, ok := sc["runAsUser"].(int); ok && uid == 0 {
+					return fmt.Sprintf("container %s sets runAsUser: 0", containerName(c))
+				}
+			}
+			return ""
+		},
+		impact: "UID 0 inside the container is root on the host kernel",
+		fix:    "Set runAsUser to a non-zero UID",
+	},
+}
+
+// podAnnotations returns the annotations of the pod template, or of the pod itself
+func podAnnotations(resource parser.K8sResource) map[string]string {
+	if resource.Kind == "Pod" {
+		return resource.Metadata.Annotations
+	}
+
+	metadata := podTemplateMetadata(resource)
+	annotations := make(map[string]string)
+	raw, _ := metadata["annotations"].(map[string]interface{})
+	for key, value := range raw {
+		if s, ok := value.(string); ok {
+			annotations[key] = s
+		}
+	}
+	return annotations
+}
+
+// podTemplateMetadata returns the metadata of a workload's pod template
+func podTemplateMetadata(resource parser.K8sResource) map[string]interface{} {
+	spec := resource.Spec
+	if jobTemplate, ok := spec["jobTemplate"].(map[string]interface{}); ok {
+		spec, _ = jobTemplate["spec"].(map[string]interface{})
+	}
+	template, _ := spec["template"].(map[string]interface{})
+	metadata, _ := template["metadata"].(map[string]interface{})
+	return metadata
+}
+
+// allContainers returns the regular, init and ephemeral containers of a pod spec
+func allContainers(podSpec map[string]interface{}) []map[string]interface{} {
+	var containers []map[string]interface{}
+	for _, key := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		list, _ := podSpec[key].([]interface{})
+		for _, c := range list {
+			if container, ok := c.(map[string]interface{}); ok {
+				containers = append(containers, container)
+			}
+		}
+	}
+	return containers
+}
+
+// containerName returns a container's name for use in messages
+func containerName(container map[string]interface{}) string {
+	name, _ := container["name"].(string)
+	return name
+}
+
+// enabledFlags lists which of the given boolean fields are true
+func enabledFlags(spec map[string]interface{}, fields ...string) string {
+	var enabled []string
+	for _, field := range fields {
+		if on, _ := spec[field].(bool); on {
+			enabled = append(enabled, field)
+		}
+	}
+	if len(enabled) == 0 {
+		return ""
+	}
+	return strings.Join(enabled, ", ") + " enabled"
+}
+
+// capabilities returns the capabilities a container adds and drops
+func capabilities(container map[string]interface{}) (add, drop []string) {
+	sc, _ := container["securityContext"].(map[string]interface{})
+	caps, _ := sc["capabilities"].(map[string]interface{})
+	for _, c := range stringSlice(caps["add"]) {
+		add = append(add, strings.ToUpper(c))
+	}
+	return add, stringSlice(caps["drop"])
+}
+
+// volumeTypes returns the source type of each volume in a pod spec, sorted
+func volumeTypes(podSpec map[string]interface{}) []string {
+	var names []string
+	volumes, _ := podSpec["volumes"].([]interface{})
+	for _, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		for key := range volume {
+			if key != "name" {
+				names = append(names, key)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hostProcess reports whether a securityContext requests a Windows HostProcess
+func hostProcess(securityContext interface{}) bool {
+	sc, _ := securityContext.(map[string]interface{})
+	windows, _ := sc["windowsOptions"].(map[string]interface{})
+	enabled, _ := windows["hostProcess"].(bool)
+	return enabled
+}
+
+// appArmorUnconfined reports whether a securityContext disables AppArmor
+func appArmorUnconfined(securityContext interface{}) bool {
+	sc, _ := securityContext.(map[string]interface{})
+	profile, _ := sc["appArmorProfile"].(map[string]interface{})
+	profileType, _ := profile["type"].(string)
+	return profileType == "Unconfined"
+}
+
+// seccompType returns the seccomp profile type set in a securityContext
+func seccompType(securityContext interface{}) string {
+	sc, _ := securityContext.(map[string]interface{})
+	profile, _ := sc["seccompProfile"].(map[string]interface{})
+	profileType, _ := profile["type"].(string)
+	return profileType
+}
+
+// seLinuxViolation describes disallowed seLinuxOptions in a securityContext
+func seLinuxViolation(securityContext interface{}) string {
+	sc, _ := securityContext.(map[string]interface{})
+	options, ok := sc["seLinuxOptions"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if seType, _ := options["type"].(string); !pssSELinuxTypes[seType] {
+		return fmt.Sprintf("sets SELinux type %s", seType)
+	}
+	for _, field := range []string{"user", "role"} {
+		if value, _ := options[field].(string); value != "" {
+			return fmt.Sprintf("sets SELinux %s %s", field, value)
+		}
+	}
+	return ""
+}
+
+// stringSlice converts a YAML sequence to a slice of strings, skipping non-strings
+func stringSlice(value interface{}) []string {
+	list, _ := value.([]interface{})
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// containsFold reports whether values includes want, ignoring case
+func containsFold(values []string, want string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
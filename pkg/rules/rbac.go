@@ -0,0 +1,390 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// clusterAdminRole is the built-in ClusterRole granting every permission.
+// It is rarely present in manifests, so it is recognized by name.
+const clusterAdminRole = "cluster-admin"
+
+// grant is one binding of a service account to a role that grants */*
+type grant struct {
+	bindingKind string
+	bindingName string
+	roleKind    string
+	roleName    string
+	namespace   string // Namespace the grant is limited to; empty for cluster-wide
+}
+
+// rbacIndex resolves which service accounts are bound to wildcard roles
+type rbacIndex struct {
+	roles        map[string]parser.K8sResource // Keyed by namespace/name
+	clusterRoles map[string]parser.K8sResource // Keyed by name
+	bindings     []parser.K8sResource
+}
+
+// newRBACIndex indexes the roles and bindings among resources
+func newRBACIndex(resources []parser.K8sResource) rbacIndex {
+	index := rbacIndex{
+		roles:        make(map[string]parser.K8sResource),
+		clusterRoles: make(map[string]parser.K8sResource),
+	}
+	for _, resource := range resources {
+		switch resource.Kind {
+		case "Role":
+			index.roles[namespaceOf(resource)+"/"+resource.Metadata.Name] = resource
+		case "ClusterRole":
+			index.clusterRoles[resource.Metadata.Name] = resource
+		case "RoleBinding", "ClusterRoleBinding":
+			if resource.RoleRef != nil {
+				index.bindings = append(index.bindings, resource)
+			}
+		}
+	}
+	return index
+}
+
+// wildcardGrant returns the first binding that gives the service account
+// namespace/name a role granting all verbs on all resources
+func (index rbacIndex) wildcardGrant(namespace, name string) (grant, bool) {
+	for _, binding := range index.bindings {
+		if !bindsServiceAccount(binding, namespace, name) {
+			continue
+		}
+
+		ref := binding.RoleRef
+		var role parser.K8sResource
+		var found bool
+		switch ref.Kind {
+		case "Role":
+			if binding.Kind != "RoleBinding" {
+				continue
+			}
+			role, found = index.roles[namespaceOf(binding)+"/"+ref.Name]
+		case "ClusterRole":
+			role, found = index.clusterRoles[ref.Name]
+		default:
+			continue
+		}
+
+		if !found {
+			// cluster-admin is built in and rarely redefined in manifests
+			if ref.Kind != "ClusterRole" || ref.Name != clusterAdminRole {
+				continue
+			}
+		} else if !grantsEverything(role) {
+			continue
+		}
+
+		g := grant{
+			bindingKind: binding.Kind,
+			bindingName: binding.Metadata.Name,
+			roleKind:    ref.Kind,
+			roleName:    ref.Name,
+		}
+		if binding.Kind == "RoleBinding" {
+			g.namespace = namespaceOf(binding)
+		}
+		return g, true
+	}
+	return grant{}, false
+}
+
+// bindsServiceAccount reports whether a binding's subjects include the
+// service account namespace/name, directly or through the
+// system:serviceaccounts groups
+func bindsServiceAccount(binding parser.K8sResource, namespace, name string) bool {
+	for _, subject := range binding.Subjects {
+		switch subject.Kind {
+		case "ServiceAccount":
+			subjectNamespace := subject.Namespace
+			if subjectNamespace == "" && binding.Kind == "RoleBinding" {
+				subjectNamespace = namespaceOf(binding)
+			}
+			if subject.Name == name && subjectNamespace == namespace {
+				return true
+			}
+		case "Group":
+			if subject.Name == "system:serviceaccounts" || subject.Name == "system:serviceaccounts:"+namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// grantsEverything reports whether a role has a rule allowing every verb on
+// every resource
+func grantsEverything(role parser.K8sResource) bool {
+	for _, rule := range role.Rules {
+		if contains(rule.Verbs, "*") && contains(rule.Resources, "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceOf returns a resource's namespace, treating an empty namespace as
+// "default"
+func namespaceOf(resource parser.K8sResource) string {
+	if resource.Metadata.Namespace == "" {
+		return "default"
+	}
+	return resource.Metadata.Namespace
+}
+
+// CheckServiceAccountRBAC follows each workload's service account through the
+// RoleBindings and ClusterRoleBindings in the scanned manifests, and flags
+// workloads whose service account is bound to cluster-admin or another role
+// granting all verbs on all resources
+func CheckServiceAccountRBAC(resources []parser.K8sResource) []types.Finding {
+	index := newRBACIndex(resources)
+	if len(index.bindings) == 0 {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, resource := range resources {
+		podSpec, ok := parser.GetPodSpec(resource)
+		if !ok {
+			continue
+		}
+
+		serviceAccount := podServiceAccount(podSpec)
+		namespace := namespaceOf(resource)
+		g, ok := index.wildcardGrant(namespace, serviceAccount)
+		if !ok {
+			continue
+		}
+
+		scope := "across the cluster"
+		if g.namespace != "" {
+			scope = "in namespace " + g.namespace
+		}
+		findings = append(findings, types.Finding{
+			RuleID:    "service-account-overprivileged",
+			Severity:  types.High,
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			Reason: fmt.Sprintf("%s %s uses ServiceAccount %s/%s, bound by %s %s to %s %s granting */* %s",
+				resource.Kind, resource.Metadata.Name, namespace, serviceAccount,
+				g.bindingKind, g.bindingName, g.roleKind, g.roleName, scope),
+			Impact: "Anyone who compromises the pod can read its service account token and act with full control " + scope,
+			Fix:    fmt.Sprintf("Run %s under a dedicated ServiceAccount bound to a role listing only the verbs and resources it needs", resource.Metadata.Name),
+		})
+	}
+
+	return findings
+}
+
+// podServiceAccount returns the service account a pod spec runs as
+func podServiceAccount(podSpec map[string]interface{}) string {
+	serviceAccount, _ := podSpec["serviceAccountName"].(string)
+	if serviceAccount == "" {
+		serviceAccount, _ = podSpec["serviceAccount"].(string)
+	}
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	return serviceAccount
+}
+
+// CheckServiceAccountTokenAutomount flags workloads that get a service
+// account token mounted. The pod's automountServiceAccountToken wins over the
+// ServiceAccount's, when that ServiceAccount is among the scanned resources.
+// A workload running as its namespace's default service account while a
+// binding in the scan set grants that account a role is reported as
+// default-service-account-token (HIGH) instead of
+// service-account-token-automount (MEDIUM).
+func CheckServiceAccountTokenAutomount(resources []parser.K8sResource) []types.Finding {
+	accountAutomount := make(map[string]bool)
+	var bindings []parser.K8sResource
+	for _, resource := range resources {
+		switch resource.Kind {
+		case "ServiceAccount":
+			if automount, ok := resource.Raw["automountServiceAccountToken"].(bool); ok {
+				accountAutomount[namespaceOf(resource)+"/"+resource.Metadata.Name] = automount
+			}
+		case "RoleBinding", "ClusterRoleBinding":
+			if resource.RoleRef != nil {
+				bindings = append(bindings, resource)
+			}
+		}
+	}
+
+	var findings []types.Finding
+	for _, resource := range resources {
+		podSpec, ok := parser.GetPodSpec(resource)
+		if !ok {
+			continue
+		}
+
+		namespace := namespaceOf(resource)
+		serviceAccount := podServiceAccount(podSpec)
+
+		automount := true
+		if val, ok := accountAutomount[namespace+"/"+serviceAccount]; ok {
+			automount = val
+		}
+		if val, ok := podSpec["automountServiceAccountToken"].(bool); ok {
+			automount = val
+		}
+		if !automount {
+			continue
+		}
+
+		var binding *parser.K8sResource
+		if serviceAccount == "default" {
+			for i := range bindings {
+				if bindsServiceAccount(bindings[i], namespace, serviceAccount) {
+					binding = &bindings[i]
+					break
+				}
+			}
+		}
+
+		if binding != nil {
+			findings = append(findings, types.Finding{
+				RuleID:    "default-service-account-token",
+				Severity:  types.High,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Reason: fmt.Sprintf("%s %s mounts the token of ServiceAccount %s/default, which %s %s binds to %s %s",
+					resource.Kind, resource.Metadata.Name, namespace,
+					binding.Kind, binding.Metadata.Name, binding.RoleRef.Kind, binding.RoleRef.Name),
+				Impact:         "Every pod in the namespace that keeps the default service account gets these permissions, and a compromise of any of them can use the token",
+				Fix:            fmt.Sprintf("Set automountServiceAccountToken: false, or run %s under a dedicated ServiceAccount and bind the role to that instead", resource.Metadata.Name),
+				Path:           parser.PodSpecPath(resource),
+				SuggestedPatch: addField(parser.PodSpecPath(resource)+".automountServiceAccountToken", false),
+			})
+			continue
+		}
+
+		findings = append(findings, types.Finding{
+			RuleID:         "service-account-token-automount",
+			Severity:       types.Medium,
+			Kind:           resource.Kind,
+			Name:           resource.Metadata.Name,
+			Namespace:      resource.Metadata.Namespace,
+			Reason:         fmt.Sprintf("%s %s mounts the token of ServiceAccount %s/%s", resource.Kind, resource.Metadata.Name, namespace, serviceAccount),
+			Impact:         "A compromised container can read the token and call the Kubernetes API as the service account",
+			Fix:            "Set automountServiceAccountToken: false in the pod spec unless the workload calls the Kubernetes API",
+			Path:           parser.PodSpecPath(resource),
+			SuggestedPatch: addField(parser.PodSpecPath(resource)+".automountServiceAccountToken", false),
+		})
+	}
+
+	return findings
+}
+
+// rbacGrant is a specific, non-wildcard permission that is dangerous on its
+// own: verbs on resources in the core or RBAC API group
+type rbacGrant struct {
+	ruleID    string
+	apiGroup  string
+	verbs     []string
+	resources []string // Empty matches any resource
+	impact    string
+	fix       string
+}
+
+// dangerousGrants are the permissions CheckRBACDangerousGrants reports, one
+// rule ID each. Grants of verbs: * are left to wildcard-rbac-verbs.
+var dangerousGrants = []rbacGrant{
+	{
+		ruleID:    "rbac-secrets-read",
+		apiGroup:  "",
+		verbs:     []string{"get", "list", "watch"},
+		resources: []string{"secrets"},
+		impact:    "Principals with this role can read every Secret in its scope, including service account tokens and credentials",
+		fix:       "Grant access to specific Secrets with resourceNames, or mount them into the workload instead of reading them through the API",
+	},
+	{
+		ruleID:    "rbac-pod-exec",
+		apiGroup:  "",
+		verbs:     []string{"create"},
+		resources: []string{"pods/exec"},
+		impact:    "Principals with this role can run commands in any pod in its scope and take over its service account and secrets",
+		fix:       "Drop pods/exec from the role, or limit it to a break-glass role bound only when needed",
+	},
+	{
+		ruleID:   "rbac-impersonate",
+		apiGroup: "",
+		verbs:    []string{"impersonate"},
+		impact:   "Principals with this role can act as other users, groups or service accounts, including more privileged ones",
+		fix:      "Remove impersonate, or restrict it with resourceNames to the identities that must be impersonated",
+	},
+	{
+		ruleID:    "rbac-escalate-bind",
+		apiGroup:  "rbac.authorization.k8s.io",
+		verbs:     []string{"escalate", "bind"},
+		resources: []string{"roles", "clusterroles"},
+		impact:    "Principals with this role can grant themselves or others permissions they don't hold, up to cluster-admin",
+		fix:       "Remove escalate and bind; let a cluster administrator manage role contents and bindings",
+	},
+}
+
+// CheckRBACDangerousGrants checks Roles and ClusterRoles for specific verbs
+// that lead to escalation without any wildcard: reading Secrets, exec into
+// pods, impersonation and escalate/bind on roles. At most one finding per
+// rule ID is reported.
+func CheckRBACDangerousGrants(resource parser.K8sResource) []types.Finding {
+	if resource.Kind != "Role" && resource.Kind != "ClusterRole" {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, g := range dangerousGrants {
+		for i, rule := range resource.Rules {
+			if !contains(rule.APIGroups, g.apiGroup) && !contains(rule.APIGroups, "*") {
+				continue
+			}
+
+			var verbs []string
+			for _, verb := range g.verbs {
+				if contains(rule.Verbs, verb) {
+					verbs = append(verbs, verb)
+				}
+			}
+			if len(verbs) == 0 {
+				continue
+			}
+
+			var resources []string
+			for _, r := range g.resources {
+				if contains(rule.Resources, r) {
+					resources = append(resources, r)
+				}
+			}
+			if len(g.resources) > 0 && len(resources) == 0 {
+				continue
+			}
+
+			reason := fmt.Sprintf("Grants %s", strings.Join(verbs, ", "))
+			if len(resources) > 0 {
+				reason += " on " + strings.Join(resources, ", ")
+			}
+			findings = append(findings, types.Finding{
+				RuleID:    g.ruleID,
+				Severity:  types.High,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Reason:    reason,
+				Impact:    g.impact,
+				Fix:       g.fix,
+				Path:      fmt.Sprintf("rules[%d]", i),
+			})
+			break
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// configReference is a container's reference to a Secret or ConfigMap
+type configReference struct {
+	kind      string // Secret or ConfigMap
+	name      string
+	container string
+	path      string
+}
+
+// referenceKinds maps env and envFrom reference fields to the kind they name
+var referenceKinds = map[string]string{
+	"secretKeyRef":    "Secret",
+	"configMapKeyRef": "ConfigMap",
+	"secretRef":       "Secret",
+	"configMapRef":    "ConfigMap",
+}
+
+// configIndex records which Secrets and ConfigMaps the scan defines, and
+// where
+type configIndex struct {
+	objects map[string]bool // Keyed by kind/namespace/name
+	managed map[string]bool // Keyed by kind/namespace/directory
+}
+
+// newConfigIndex indexes the Secrets and ConfigMaps among resources
+func newConfigIndex(resources []parser.K8sResource) configIndex {
+	index := configIndex{
+		objects: make(map[string]bool),
+		managed: make(map[string]bool),
+	}
+	for _, resource := range resources {
+		if resource.Kind != "Secret" && resource.Kind != "ConfigMap" {
+			continue
+		}
+		namespace := namespaceOf(resource)
+		index.objects[resource.Kind+"/"+namespace+"/"+resource.Metadata.Name] = true
+		index.managed[resource.Kind+"/"+namespace+"/"+filepath.Dir(resource.Source)] = true
+	}
+	return index
+}
+
+// containerReferences returns the non-optional Secret and ConfigMap
+// references in a container's env and envFrom
+func containerReferences(container map[string]interface{}, path string) []configReference {
+	name := containerName(container)
+	var refs []configReference
+
+	env, _ := container["env"].([]interface{})
+	for i, e := range env {
+		entry, _ := e.(map[string]interface{})
+		valueFrom, _ := entry["valueFrom"].(map[string]interface{})
+		for _, field := range []string{"secretKeyRef", "configMapKeyRef"} {
+			if ref, ok := requiredRef(valueFrom[field]); ok {
+				refs = append(refs, configReference{referenceKinds[field], ref, name, fmt.Sprintf("%s.env[%d].valueFrom.%s", path, i, field)})
+			}
+		}
+	}
+
+	envFrom, _ := container["envFrom"].([]interface{})
+	for i, e := range envFrom {
+		entry, _ := e.(map[string]interface{})
+		for _, field := range []string{"secretRef", "configMapRef"} {
+			if ref, ok := requiredRef(entry[field]); ok {
+				refs = append(refs, configReference{referenceKinds[field], ref, name, fmt.Sprintf("%s.envFrom[%d].%s", path, i, field)})
+			}
+		}
+	}
+	return refs
+}
+
+// requiredRef returns the object name of a key or envFrom reference unless
+// it is marked optional
+func requiredRef(value interface{}) (string, bool) {
+	ref, ok := value.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	if optional, _ := ref["optional"].(bool); optional {
+		return "", false
+	}
+	name, _ := ref["name"].(string)
+	return name, name != ""
+}
+
+// CheckMissingConfigReferences flags containers that read env vars from a
+// Secret or ConfigMap the scan doesn't define. Many Secrets are created
+// outside of manifests (sealed-secrets, external-secrets, CI), so a
+// reference is only checked when the workload's directory already defines
+// objects of that kind in the same namespace.
+func CheckMissingConfigReferences(resources []parser.K8sResource) []types.Finding {
+	index := newConfigIndex(resources)
+	if len(index.managed) == 0 {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, resource := range resources {
+		podSpec, ok := parser.GetPodSpec(resource)
+		if !ok {
+			continue
+		}
+		namespace := namespaceOf(resource)
+		dir := filepath.Dir(resource.Source)
+
+		var refs []configReference
+		for _, c := range podContainers(podSpec) {
+			refs = append(refs, containerReferences(c.spec, c.path(resource))...)
+		}
+
+		for _, ref := range refs {
+			if !index.managed[ref.kind+"/"+namespace+"/"+dir] || index.objects[ref.kind+"/"+namespace+"/"+ref.name] {
+				continue
+			}
+			findings = append(findings, types.Finding{
+				RuleID:    "missing-config-reference",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Reason: fmt.Sprintf("Container '%s' reads env from %s %s/%s, which is not defined alongside the other %ss in this directory",
+					ref.container, ref.kind, namespace, ref.name, ref.kind),
+				Impact: "Pods fail to start with CreateContainerConfigError until the object exists, so a typo or a missed file breaks the rollout",
+				Fix:    fmt.Sprintf("Add %s %s to the manifests, fix the reference name, or mark the reference optional: true if it may be absent", ref.kind, ref.name),
+				Path:   ref.path,
+			})
+		}
+	}
+
+	return findings
+}
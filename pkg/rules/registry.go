@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// RuleInfo describes a built-in rule for listings and configuration: what
+// it checks, the default severity of its findings, the category that runs it
+// and the control framework entries it maps to
+type RuleInfo struct {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Severity    types.Severity `json:"severity"`
+	Category    types.Category `json:"category"`
+	Default     bool           `json:"default"` // Runs without opting in to its category, strict mode or a PSS level
+	CISControl  string         `json:"cis_control,omitempty"`
+	References  []string       `json:"references,omitempty"`
+}
+
+// builtinRules lists the IDs of the per-resource, context and aggregate
+// rules with the category that runs them, in reporting order. A check
+// function may report several of these IDs.
+var builtinRules = []struct {
+	id       string
+	category types.Category
+}{
+	{"privileged-container", types.CategorySecurity},
+	{"hostpath-volume", types.CategorySecurity},
+	{"docker-socket-mount", types.CategorySecurity},
+	{"runs-as-root", types.CategorySecurity},
+	{"privilege-escalation-allowed", types.CategorySecurity},
+	{"wildcard-rbac", types.CategorySecurity},
+	{"wildcard-rbac-verbs", types.CategorySecurity},
+	{"wildcard-rbac-resources", types.CategorySecurity},
+	{"rbac-secrets-read", types.CategorySecurity},
+	{"rbac-pod-exec", types.CategorySecurity},
+	{"rbac-impersonate", types.CategorySecurity},
+	{"rbac-escalate-bind", types.CategorySecurity},
+	{"clusterrolebinding-default-sa", types.CategorySecurity},
+	{"public-loadbalancer", types.CategorySecurity},
+	{"nodeport-service", types.CategorySecurity},
+	{"latest-image-tag", types.CategorySecurity},
+	{"host-network", types.CategorySecurity},
+	{"host-pid-ipc", types.CategorySecurity},
+	{"host-port", types.CategorySecurity},
+	{"super-pod", types.CategorySecurity},
+	{"exposed-privileged-workload", types.CategorySecurity},
+	{"sensitive-mount-path", types.CategorySecurity},
+	{"dangerous-capabilities", types.CategorySecurity},
+	{"envfrom-without-checksum", types.CategoryReliability},
+	{"capabilities-not-dropped", types.CategoryHardening},
+	{"writable-root-filesystem", types.CategoryHardening},
+	{"namespace-without-networkpolicy", types.CategoryHardening},
+	{"namespace-without-default-deny", types.CategoryHardening},
+	{"missing-security-context", types.CategoryHardening},
+	{"privilege-escalation-not-disabled", types.CategoryHardening},
+	{"default-namespace", types.CategoryGovernance},
+	{"job-without-limits", types.CategoryReliability},
+	{"cronjob-concurrent-runs", types.CategoryReliability},
+	{"shell-entrypoint", types.CategoryObservability},
+	{"image-not-digest-pinned", types.CategorySupplyChain},
+	{"stale-image-pull-policy", types.CategoryReliability},
+	{"redundant-image-pull", types.CategoryReliability},
+	{"weak-secret-value", types.CategorySecrets},
+	{"secret-volume-permissive-mode", types.CategorySecrets},
+	{"secret-in-env", types.CategorySecrets},
+	{"replicas-not-spread", types.CategoryReliability},
+	{"route-without-tls", types.CategorySecurity},
+	{"low-uid", types.CategorySecurity},
+	{"memory-emptydir-without-limit", types.CategoryReliability},
+	{"privileged-port-without-capability", types.CategoryReliability},
+	{"short-termination-grace-period", types.CategoryReliability},
+	{"no-resource-limits", types.CategoryReliability},
+	{"missing-config-reference", types.CategoryReliability},
+	{"service-account-overprivileged", types.CategorySecurity},
+	{"default-service-account-token", types.CategorySecurity},
+	{"service-account-token-automount", types.CategorySecurity},
+}
+
+// RuleIDs returns the IDs of all built-in rules
+func RuleIDs() []string {
+	ids := make([]string, len(builtinRules))
+	for i, rule := range builtinRules {
+		ids[i] = rule.id
+	}
+	return append(ids, PSSRuleIDs()...)
+}
+
+// Rules returns the metadata of every built-in rule, in RuleIDs order
+func Rules() []RuleInfo {
+	defaults := make(map[types.Category]bool)
+	for _, category := range DefaultCategories() {
+		defaults[category] = true
+	}
+	strictOnly := make(map[string]bool)
+	for _, id := range StrictRuleIDs() {
+		strictOnly[id] = true
+	}
+
+	var infos []RuleInfo
+	for _, rule := range builtinRules {
+		info := describe(rule.id, rule.category)
+		info.Default = defaults[rule.category] && !strictOnly[rule.id]
+		infos = append(infos, info)
+	}
+	for _, id := range PSSRuleIDs() {
+		infos = append(infos, describe(id, types.CategoryCompliance))
+	}
+	return infos
+}
+
+// describe assembles a rule's metadata from its explanation and control
+// framework mappings
+func describe(ruleID string, category types.Category) RuleInfo {
+	info := RuleInfo{ID: ruleID, Category: category}
+	e, ok := explanations[ruleID]
+	if !ok {
+		e, _ = explainPSS(ruleID)
+	}
+	// Explanations may qualify the severity, as in "HIGH (CRITICAL for ...)"
+	severity, _, _ := strings.Cut(e.Severity, " ")
+	info.Title, info.Description, info.Severity = e.Title, e.Description, types.Severity(severity)
+	if ref, ok := ruleReferences[ruleID]; ok {
+		info.CISControl = ref.cisControl
+		info.References = ref.references
+	}
+	return info
+}
@@ -1,7 +1,12 @@
 package rules
 
 import (
+	"encoding/base64"
 	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
@@ -11,21 +16,358 @@ import (
 // Rule is a function that checks a resource and returns findings
 type Rule func(resource parser.K8sResource) []types.Finding
 
+// AggregateRule checks a whole set of resources at once, for findings that
+// depend on how resources refer to each other
+type AggregateRule func(resources []parser.K8sResource) []types.Finding
+
+// Categories returns all rule categories in evaluation order
+func Categories() []types.Category {
+	return []types.Category{
+		types.CategorySecurity,
+		types.CategoryReliability,
+		types.CategoryHardening,
+		types.CategoryGovernance,
+		types.CategorySecrets,
+		types.CategoryObservability,
+		types.CategorySupplyChain,
+	}
+}
+
+// DefaultCategories returns the categories that run when none are requested.
+// Noisy categories such as observability and supply-chain are opt-in.
+func DefaultCategories() []types.Category {
+	return []types.Category{
+		types.CategorySecurity,
+		types.CategoryReliability,
+		types.CategoryHardening,
+		types.CategoryGovernance,
+		types.CategorySecrets,
+	}
+}
+
 // AllRules returns all implemented rules
 func AllRules() []Rule {
+	return RulesForCategories(Categories()...)
+}
+
+// RulesForCategories returns the rules registered under any of the given categories
+func RulesForCategories(categories ...types.Category) []Rule {
+	return RulesForCategoriesWithSettings(DefaultSettings(), categories...)
+}
+
+// RulesForCategoriesWithSettings is RulesForCategories with tuned rule parameters
+func RulesForCategoriesWithSettings(settings Settings, categories ...types.Category) []Rule {
+	var selected []Rule
+	for _, category := range categories {
+		selected = append(selected, RulesForCategoryWithSettings(category, settings)...)
+	}
+	return selected
+}
+
+// AggregateRulesForCategories returns the aggregate rules registered under
+// any of the given categories
+func AggregateRulesForCategories(categories ...types.Category) []AggregateRule {
+	var selected []AggregateRule
+	for _, category := range categories {
+		switch category {
+		case types.CategorySecurity:
+			selected = append(selected, aggregateInCategory(category, CheckServiceAccountRBAC, CheckServiceAccountTokenAutomount)...)
+		case types.CategoryReliability:
+			selected = append(selected, aggregateInCategory(category, CheckMissingConfigReferences)...)
+		case types.CategoryHardening:
+			selected = append(selected, aggregateInCategory(category, CheckNetworkPolicyCoverage)...)
+		}
+	}
+	return selected
+}
+
+// DefaultLowUIDThreshold is the UID below which a non-root user is treated as
+// a host system account
+const DefaultLowUIDThreshold = 1000
+
+// Settings holds the tunable parameters of rules that take them
+type Settings struct {
+	LowUIDThreshold         int      // low-uid flags runAsUser values from 1 up to, not including, this
+	SensitiveResources      []string // RBAC resources on which wildcard-rbac-verbs flags verbs: *
+	RequireResourceRequests bool     // no-resource-limits also flags missing CPU and memory requests
+}
+
+// DefaultSettings returns the built-in rule parameters
+func DefaultSettings() Settings {
+	return Settings{
+		LowUIDThreshold:    DefaultLowUIDThreshold,
+		SensitiveResources: DefaultSensitiveResources(),
+	}
+}
+
+// RulesForCategory returns the rules registered under the given category.
+// Findings produced by the returned rules are tagged with the category.
+func RulesForCategory(category types.Category) []Rule {
+	return RulesForCategoryWithSettings(category, DefaultSettings())
+}
+
+// RulesForCategoryWithSettings is RulesForCategory with tuned rule parameters
+func RulesForCategoryWithSettings(category types.Category, settings Settings) []Rule {
+	switch category {
+	case types.CategorySecurity:
+		return inCategory(category, securityRules(settings)...)
+	case types.CategoryReliability:
+		return inCategory(category, reliabilityRules(settings)...)
+	case types.CategoryHardening:
+		return inCategory(category, hardeningRules()...)
+	case types.CategoryGovernance:
+		return inCategory(category, governanceRules()...)
+	case types.CategorySecrets:
+		return inCategory(category, secretsRules()...)
+	case types.CategoryObservability:
+		return inCategory(category, observabilityRules()...)
+	case types.CategorySupplyChain:
+		return inCategory(category, supplyChainRules()...)
+	default:
+		return nil
+	}
+}
+
+// StrictRules returns the extra rules enabled in strict mode
+func StrictRules() []Rule {
+	return inCategory(types.CategoryHardening,
+		CheckMissingSecurityContext,
+		CheckPrivilegeEscalationUnset,
+	)
+}
+
+// StrictRuleIDs lists the rules that only run in strict mode
+func StrictRuleIDs() []string {
+	return []string{
+		"missing-security-context",
+		"privilege-escalation-not-disabled",
+	}
+}
+
+// SupersededByStrict lists rules whose findings are redundant for a resource
+// once strict mode has flagged it for having no securityContext at all
+func SupersededByStrict() []string {
+	return []string{
+		"runs-as-root",
+		"capabilities-not-dropped",
+		"writable-root-filesystem",
+		"privilege-escalation-not-disabled",
+	}
+}
+
+// EscalatedForDaemonSets lists rules whose HIGH findings become CRITICAL on
+// a DaemonSet: a node takeover from a pod scheduled on every node, control
+// plane nodes included, is a takeover of the whole cluster
+func EscalatedForDaemonSets() []string {
+	return []string{
+		"privileged-container",
+		"hostpath-volume",
+		"host-pid-ipc",
+		"dangerous-capabilities",
+	}
+}
+
+// inCategory wraps rules so that their findings carry the given category
+// and any control framework references known for the rule
+func inCategory(category types.Category, checks ...Rule) []Rule {
+	wrapped := make([]Rule, len(checks))
+	for i, check := range checks {
+		check := check
+		wrapped[i] = func(resource parser.K8sResource) []types.Finding {
+			findings := check(resource)
+			for j := range findings {
+				findings[j].Category = category
+				if ref, ok := ruleReferences[findings[j].RuleID]; ok {
+					findings[j].CISControl = ref.cisControl
+					findings[j].References = ref.references
+				}
+			}
+			return findings
+		}
+	}
+	return wrapped
+}
+
+// aggregateInCategory is inCategory for aggregate rules
+func aggregateInCategory(category types.Category, checks ...AggregateRule) []AggregateRule {
+	wrapped := make([]AggregateRule, len(checks))
+	for i, check := range checks {
+		check := check
+		wrapped[i] = func(resources []parser.K8sResource) []types.Finding {
+			findings := check(resources)
+			for j := range findings {
+				findings[j].Category = category
+				if ref, ok := ruleReferences[findings[j].RuleID]; ok {
+					findings[j].CISControl = ref.cisControl
+					findings[j].References = ref.references
+				}
+			}
+			return findings
+		}
+	}
+	return wrapped
+}
+
+// reference maps a rule to CIS Kubernetes Benchmark controls and to entries
+// in other frameworks: NSA/CISA Kubernetes Hardening Guide topics and MITRE
+// ATT&CK techniques
+type reference struct {
+	cisControl string
+	references []string
+}
+
+// ruleReferences holds the control framework mappings for built-in rules.
+// CIS numbering follows the CIS Kubernetes Benchmark v1.6 section 5, and
+// NSA/CISA topics the Kubernetes Hardening Guide v1.2.
+var ruleReferences = map[string]reference{
+	"privileged-container":               {"5.2.1", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1611"}},
+	"hostpath-volume":                    {"", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1611"}},
+	"docker-socket-mount":                {"", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1611", "MITRE ATT&CK T1610"}},
+	"runs-as-root":                       {"5.2.6", []string{"NSA/CISA Non-root containers"}},
+	"privilege-escalation-allowed":       {"5.2.5", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1068"}},
+	"wildcard-rbac":                      {"5.1.3", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1078"}},
+	"wildcard-rbac-verbs":                {"5.1.3", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1078"}},
+	"wildcard-rbac-resources":            {"5.1.3", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1078"}},
+	"rbac-secrets-read":                  {"5.1.2", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1552"}},
+	"rbac-pod-exec":                      {"", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1609"}},
+	"rbac-impersonate":                   {"", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1078"}},
+	"rbac-escalate-bind":                 {"", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1098"}},
+	"clusterrolebinding-default-sa":      {"5.1.5", []string{"NSA/CISA Authentication and authorization", "MITRE ATT&CK T1078"}},
+	"public-loadbalancer":                {"", []string{"NSA/CISA Network separation", "MITRE ATT&CK T1133"}},
+	"nodeport-service":                   {"", []string{"NSA/CISA Network separation", "MITRE ATT&CK T1133"}},
+	"latest-image-tag":                   {"", []string{"NSA/CISA Building secure container images", "MITRE ATT&CK T1525"}},
+	"host-network":                       {"5.2.4", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1611"}},
+	"host-pid-ipc":                       {"5.2.2, 5.2.3", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1611"}},
+	"host-port":                          {"", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1133"}},
+	"super-pod":                          {"5.2.1", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1611"}},
+	"exposed-privileged-workload":        {"5.2.1", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1190", "MITRE ATT&CK T1611"}},
+	"sensitive-mount-path":               {"", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1574", "MITRE ATT&CK T1528"}},
+	"dangerous-capabilities":             {"5.2.8", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1611"}},
+	"capabilities-not-dropped":           {"5.2.9", []string{"NSA/CISA Pod security"}},
+	"writable-root-filesystem":           {"", []string{"NSA/CISA Immutable container file systems"}},
+	"namespace-without-networkpolicy":    {"5.3.2", []string{"NSA/CISA Network policies", "MITRE ATT&CK T1046"}},
+	"namespace-without-default-deny":     {"5.3.2", []string{"NSA/CISA Network policies"}},
+	"missing-security-context":           {"5.7.3", []string{"NSA/CISA Pod security"}},
+	"privilege-escalation-not-disabled":  {"5.2.5", []string{"NSA/CISA Pod security", "MITRE ATT&CK T1068"}},
+	"default-namespace":                  {"5.7.4", []string{"NSA/CISA Namespaces"}},
+	"job-without-limits":                 {"", []string{"NSA/CISA Resource policies"}},
+	"image-not-digest-pinned":            {"", []string{"NSA/CISA Building secure container images"}},
+	"weak-secret-value":                  {"", []string{"NSA/CISA Secrets"}},
+	"secret-volume-permissive-mode":      {"", []string{"NSA/CISA Secrets"}},
+	"secret-in-env":                      {"5.4.1", []string{"NSA/CISA Secrets", "MITRE ATT&CK T1552"}},
+	"route-without-tls":                  {"", []string{"NSA/CISA Network separation", "MITRE ATT&CK T1557"}},
+	"low-uid":                            {"5.2.6", []string{"NSA/CISA Non-root containers"}},
+	"memory-emptydir-without-limit":      {"", []string{"NSA/CISA Resource policies"}},
+	"privileged-port-without-capability": {"", []string{"NSA/CISA Non-root containers"}},
+	"no-resource-limits":                 {"", []string{"NSA/CISA Resource policies"}},
+	"service-account-overprivileged":     {"", []string{"NSA/CISA Authentication and authorization"}},
+	"default-service-account-token":      {"5.1.5, 5.1.6", []string{"NSA/CISA Protecting Pod service account tokens", "MITRE ATT&CK T1528"}},
+	"service-account-token-automount":    {"5.1.6", []string{"NSA/CISA Protecting Pod service account tokens", "MITRE ATT&CK T1528"}},
+}
+
+// cisControlTitles names the CIS Kubernetes Benchmark v1.6 controls that
+// built-in rules map to
+var cisControlTitles = map[string]string{
+	"5.1.2": "Minimize access to secrets",
+	"5.1.3": "Minimize wildcard use in Roles and ClusterRoles",
+	"5.1.5": "Ensure that default service accounts are not actively used",
+	"5.1.6": "Ensure that Service Account Tokens are only mounted where necessary",
+	"5.2.1": "Minimize the admission of privileged containers",
+	"5.2.2": "Minimize the admission of containers wishing to share the host process ID namespace",
+	"5.2.3": "Minimize the admission of containers wishing to share the host IPC namespace",
+	"5.2.4": "Minimize the admission of containers wishing to share the host network namespace",
+	"5.2.5": "Minimize the admission of containers with allowPrivilegeEscalation",
+	"5.2.6": "Minimize the admission of root containers",
+	"5.2.8": "Minimize the admission of containers with added capabilities",
+	"5.2.9": "Minimize the admission of containers with capabilities assigned",
+	"5.3.2": "Ensure that all Namespaces have Network Policies defined",
+	"5.4.1": "Prefer using secrets as files over secrets as environment variables",
+	"5.7.3": "Apply Security Context to Your Pods and Containers",
+	"5.7.4": "The default namespace should not be used",
+}
+
+// CISControlTitle returns the title of a CIS Kubernetes Benchmark control,
+// or "" for a control no built-in rule maps to
+func CISControlTitle(control string) string {
+	return cisControlTitles[control]
+}
+
+// securityRules returns the rules that detect exploitable misconfigurations
+func securityRules(settings Settings) []Rule {
 	return []Rule{
 		CheckPrivilegedContainer,
 		CheckHostPath,
 		CheckDockerSocket,
 		CheckRunsAsRoot,
 		CheckPrivilegeEscalation,
-		CheckWildcardRBAC,
+		WildcardRBACRule(settings.SensitiveResources),
+		CheckRBACDangerousGrants,
 		CheckClusterRoleBindingDefaultSA,
 		CheckPublicLoadBalancer,
 		CheckNodePort,
 		CheckLatestTag,
 		CheckHostNetwork,
 		CheckHostPIDIPC,
+		CheckHostPort,
+		CheckSuperPod,
+		CheckSensitiveMountPath,
+		CheckDangerousCapabilities,
+		CheckRouteTLS,
+		LowUIDRule(settings.LowUIDThreshold),
+	}
+}
+
+// reliabilityRules returns the rules that detect outage-prone configurations
+func reliabilityRules(settings Settings) []Rule {
+	return []Rule{
+		ResourceLimitsRule(settings.RequireResourceRequests),
+		CheckEnvFromChecksum,
+		CheckJobSafety,
+		CheckImagePullPolicy,
+		CheckReplicaSpread,
+		CheckMemoryEmptyDir,
+		CheckPrivilegedPortBind,
+		CheckTerminationGracePeriod,
+	}
+}
+
+// hardeningRules returns the rules that flag missing defense-in-depth settings
+func hardeningRules() []Rule {
+	return []Rule{
+		CheckCapabilitiesNotDropped,
+		CheckWritableRootFilesystem,
+	}
+}
+
+// governanceRules returns the rules that flag organizational policy smells
+func governanceRules() []Rule {
+	return []Rule{
+		CheckDefaultNamespace,
+	}
+}
+
+// secretsRules returns the rules that inspect committed Secret manifests
+func secretsRules() []Rule {
+	return []Rule{
+		CheckWeakSecretValues,
+		CheckSecretVolumeMode,
+		CheckSecretInEnv,
+	}
+}
+
+// observabilityRules returns the opt-in rules that flag configurations which
+// obscure what a container actually runs
+func observabilityRules() []Rule {
+	return []Rule{
+		CheckShellEntrypoint,
+	}
+}
+
+// supplyChainRules returns the opt-in rules for teams that require images to
+// be pinned immutably
+func supplyChainRules() []Rule {
+	return []Rule{
+		CheckImageDigestPinning,
 	}
 }
 
@@ -36,16 +378,9 @@ func CheckPrivilegedContainer(resource parser.K8sResource) []types.Finding {
 		return nil
 	}
 
-	containers, ok := podSpec["containers"].([]interface{})
-	if !ok {
-		return nil
-	}
-
-	for _, c := range containers {
-		container, ok := c.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
 
 		securityContext, ok := container["securityContext"].(map[string]interface{})
 		if !ok {
@@ -53,20 +388,23 @@ func CheckPrivilegedContainer(resource parser.K8sResource) []types.Finding {
 		}
 
 		if privileged, ok := securityContext["privileged"].(bool); ok && privileged {
-			return []types.Finding{{
-				RuleID:    "privileged-container",
-				Severity:  types.High,
-				Kind:      resource.Kind,
-				Name:      resource.Metadata.Name,
-				Namespace: resource.Metadata.Namespace,
-				Reason:    "Container runs in privileged mode",
-				Impact:    "Full host access if container is compromised",
-				Fix:       "Remove privileged flag or set to false",
-			}}
+			findings = append(findings, types.Finding{
+				RuleID:         "privileged-container",
+				Severity:       types.High,
+				Kind:           resource.Kind,
+				Name:           resource.Metadata.Name,
+				Namespace:      resource.Metadata.Namespace,
+				Container:      containerName(container),
+				Reason:         "Container runs in privileged mode",
+				Impact:         "Full host access if container is compromised",
+				Fix:            "Remove privileged flag or set to false",
+				Path:           c.path(resource),
+				SuggestedPatch: replaceField(c.path(resource)+".securityContext.privileged", true, false),
+			})
 		}
 	}
 
-	return nil
+	return findings
 }
 
 // CheckHostPath checks for hostPath volumes
@@ -81,14 +419,15 @@ func CheckHostPath(resource parser.K8sResource) []types.Finding {
 		return nil
 	}
 
-	for _, v := range volumes {
+	var findings []types.Finding
+	for i, v := range volumes {
 		volume, ok := v.(map[string]interface{})
 		if !ok {
 			continue
 		}
 
 		if _, hasHostPath := volume["hostPath"]; hasHostPath {
-			return []types.Finding{{
+			findings = append(findings, types.Finding{
 				RuleID:    "hostpath-volume",
 				Severity:  types.High,
 				Kind:      resource.Kind,
@@ -97,11 +436,12 @@ func CheckHostPath(resource parser.K8sResource) []types.Finding {
 				Reason:    "Uses hostPath volume mount",
 				Impact:    "Direct filesystem access enables container escape",
 				Fix:       "Use PersistentVolumes or emptyDir instead",
-			}}
+				Path:      itemPath(resource, "volumes", i),
+			})
 		}
 	}
 
-	return nil
+	return findings
 }
 
 // CheckDockerSocket checks for Docker socket mounts
@@ -116,7 +456,8 @@ func CheckDockerSocket(resource parser.K8sResource) []types.Finding {
 		return nil
 	}
 
-	for _, v := range volumes {
+	var findings []types.Finding
+	for i, v := range volumes {
 		volume, ok := v.(map[string]interface{})
 		if !ok {
 			continue
@@ -125,22 +466,23 @@ func CheckDockerSocket(resource parser.K8sResource) []types.Finding {
 		if hostPath, ok := volume["hostPath"].(map[string]interface{}); ok {
 			if path, ok := hostPath["path"].(string); ok {
 				if strings.Contains(path, "/var/run/docker.sock") {
-					return []types.Finding{{
+					findings = append(findings, types.Finding{
 						RuleID:    "docker-socket-mount",
-						Severity:  types.High,
+						Severity:  types.Critical,
 						Kind:      resource.Kind,
 						Name:      resource.Metadata.Name,
 						Namespace: resource.Metadata.Namespace,
 						Reason:    "Mounts Docker socket from host",
 						Impact:    "Grants root-equivalent access to the node",
 						Fix:       "Remove Docker socket mount",
-					}}
+						Path:      itemPath(resource, "volumes", i),
+					})
 				}
 			}
 		}
 	}
 
-	return nil
+	return findings
 }
 
 // CheckRunsAsRoot checks if containers run as root
@@ -164,31 +506,26 @@ func CheckRunsAsRoot(resource parser.K8sResource) []types.Finding {
 		}
 	}
 
-	containers, ok := podSpec["containers"].([]interface{})
-	if !ok {
-		return nil
-	}
-
-	for _, c := range containers {
-		container, ok := c.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
 
 		securityContext, ok := container["securityContext"].(map[string]interface{})
 		if !ok {
 			// No container-level securityContext, check pod-level
 			if !podRunAsNonRoot && podRunAsUser != 0 {
-				return []types.Finding{{
+				findings = append(findings, types.Finding{
 					RuleID:    "runs-as-root",
 					Severity:  types.Medium,
 					Kind:      resource.Kind,
 					Name:      resource.Metadata.Name,
 					Namespace: resource.Metadata.Namespace,
+					Container: containerName(container),
 					Reason:    "Container runs as root user (UID 0)",
 					Impact:    "Increases blast radius of container compromise",
 					Fix:       "Set runAsNonRoot: true or runAsUser to non-zero UID",
-				}}
+					Path:      c.path(resource),
+				})
 			}
 			continue
 		}
@@ -205,20 +542,73 @@ func CheckRunsAsRoot(resource parser.K8sResource) []types.Finding {
 		}
 
 		if !runAsNonRoot && (runAsUser == 0 || runAsUser == -1) {
-			return []types.Finding{{
+			findings = append(findings, types.Finding{
 				RuleID:    "runs-as-root",
 				Severity:  types.Medium,
 				Kind:      resource.Kind,
 				Name:      resource.Metadata.Name,
 				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
 				Reason:    "Container runs as root user (UID 0)",
 				Impact:    "Increases blast radius of container compromise",
 				Fix:       "Set runAsNonRoot: true or runAsUser to non-zero UID",
-			}}
+				Path:      c.path(resource),
+			})
 		}
 	}
 
-	return nil
+	return findings
+}
+
+// LowUIDRule returns a rule that flags containers running as a non-root UID
+// below threshold, which often maps to a privileged system account on the
+// host when user namespaces are not in use. UID 0 is left to runs-as-root.
+func LowUIDRule(threshold int) Rule {
+	return func(resource parser.K8sResource) []types.Finding {
+		podSpec, ok := parser.GetPodSpec(resource)
+		if !ok {
+			return nil
+		}
+
+		podRunAsUser := -1
+		if podSecurityContext, ok := podSpec["securityContext"].(map[string]interface{}); ok {
+			if runAsUser, ok := podSecurityContext["runAsUser"].(int); ok {
+				podRunAsUser = runAsUser
+			}
+		}
+
+		var findings []types.Finding
+		for _, c := range podContainers(podSpec) {
+			container := c.spec
+
+			// The path points wherever the UID is set, which may be the pod
+			runAsUser := podRunAsUser
+			path := parser.PodSpecPath(resource) + ".securityContext.runAsUser"
+			if securityContext, ok := container["securityContext"].(map[string]interface{}); ok {
+				if val, ok := securityContext["runAsUser"].(int); ok {
+					runAsUser = val
+					path = c.path(resource) + ".securityContext.runAsUser"
+				}
+			}
+
+			if runAsUser > 0 && runAsUser < threshold {
+				findings = append(findings, types.Finding{
+					RuleID:    "low-uid",
+					Severity:  types.Medium,
+					Kind:      resource.Kind,
+					Name:      resource.Metadata.Name,
+					Namespace: resource.Metadata.Namespace,
+					Container: containerName(container),
+					Path:      path,
+					Reason:    fmt.Sprintf("Container runs as UID %d, inside the system account range (below %d)", runAsUser, threshold),
+					Impact:    "Without user namespaces the UID is shared with the host, where it may own system files or daemons",
+					Fix:       fmt.Sprintf("Set runAsUser to a dedicated UID of %d or above", threshold),
+				})
+			}
+		}
+
+		return findings
+	}
 }
 
 // CheckPrivilegeEscalation checks for privilege escalation allowance
@@ -228,16 +618,9 @@ func CheckPrivilegeEscalation(resource parser.K8sResource) []types.Finding {
 		return nil
 	}
 
-	containers, ok := podSpec["containers"].([]interface{})
-	if !ok {
-		return nil
-	}
-
-	for _, c := range containers {
-		container, ok := c.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
 
 		securityContext, ok := container["securityContext"].(map[string]interface{})
 		if !ok {
@@ -245,61 +628,191 @@ func CheckPrivilegeEscalation(resource parser.K8sResource) []types.Finding {
 		}
 
 		if allowPE, ok := securityContext["allowPrivilegeEscalation"].(bool); ok && allowPE {
-			return []types.Finding{{
-				RuleID:    "privilege-escalation-allowed",
-				Severity:  types.High,
-				Kind:      resource.Kind,
-				Name:      resource.Metadata.Name,
-				Namespace: resource.Metadata.Namespace,
-				Reason:    "Allows privilege escalation within container",
-				Impact:    "Enables container escape via kernel exploits",
-				Fix:       "Set allowPrivilegeEscalation: false",
-			}}
+			findings = append(findings, types.Finding{
+				RuleID:         "privilege-escalation-allowed",
+				Severity:       types.High,
+				Kind:           resource.Kind,
+				Name:           resource.Metadata.Name,
+				Namespace:      resource.Metadata.Namespace,
+				Container:      containerName(container),
+				Reason:         "Allows privilege escalation within container",
+				Impact:         "Enables container escape via kernel exploits",
+				Fix:            "Set allowPrivilegeEscalation: false",
+				Path:           c.path(resource),
+				SuggestedPatch: replaceField(c.path(resource)+".securityContext.allowPrivilegeEscalation", true, false),
+			})
 		}
 	}
 
-	return nil
+	return findings
+}
+
+// DefaultSensitiveResources lists the RBAC resources on which granting every
+// verb is treated as dangerous by wildcard-rbac-verbs
+func DefaultSensitiveResources() []string {
+	return []string{
+		"secrets",
+		"pods/exec",
+		"pods/attach",
+		"pods/portforward",
+		"serviceaccounts/token",
+		"nodes/proxy",
+		"roles",
+		"rolebindings",
+		"clusterroles",
+		"clusterrolebindings",
+		"certificatesigningrequests/approval",
+	}
 }
 
-// CheckWildcardRBAC checks for wildcard RBAC permissions
+// CheckWildcardRBAC checks for wildcard RBAC permissions using the default
+// sensitive resource list
 func CheckWildcardRBAC(resource parser.K8sResource) []types.Finding {
-	if resource.Kind != "Role" && resource.Kind != "ClusterRole" {
-		return nil
+	return WildcardRBACRule(DefaultSensitiveResources())(resource)
+}
+
+// WildcardRBACRule returns a rule that grades wildcard RBAC permissions:
+// every verb on every resource (wildcard-rbac, CRITICAL for a ClusterRole that
+// also spans all API groups), every verb on a sensitive resource
+// (wildcard-rbac-verbs) and specific verbs on every resource
+// (wildcard-rbac-resources). At most one finding per rule ID is reported.
+func WildcardRBACRule(sensitiveResources []string) Rule {
+	sensitive := make(map[string]bool)
+	for _, r := range sensitiveResources {
+		sensitive[r] = true
 	}
 
-	for _, rule := range resource.Rules {
-		hasWildcardVerbs := false
-		hasWildcardResources := false
+	return func(resource parser.K8sResource) []types.Finding {
+		if resource.Kind != "Role" && resource.Kind != "ClusterRole" {
+			return nil
+		}
 
-		for _, verb := range rule.Verbs {
-			if verb == "*" {
-				hasWildcardVerbs = true
-				break
+		seen := make(map[string]int) // Rule ID -> index in findings
+		var findings []types.Finding
+		report := func(f types.Finding) {
+			if i, ok := seen[f.RuleID]; ok {
+				// Keep the most severe instance
+				if f.Severity.Rank() > findings[i].Severity.Rank() {
+					findings[i] = f
+				}
+				return
 			}
+			seen[f.RuleID] = len(findings)
+			findings = append(findings, f)
 		}
 
-		for _, res := range rule.Resources {
-			if res == "*" {
-				hasWildcardResources = true
-				break
+		for i, rule := range resource.Rules {
+			wildcardVerbs := contains(rule.Verbs, "*")
+			wildcardResources := contains(rule.Resources, "*")
+			wildcardGroups := contains(rule.APIGroups, "*")
+
+			groupsNote := ""
+			if wildcardGroups {
+				groupsNote = " in all API groups"
+			}
+
+			switch {
+			case wildcardVerbs && wildcardResources:
+				severity := types.High
+				impact := "Complete control over the role's scope for any principal with this role"
+				if wildcardGroups && resource.Kind == "ClusterRole" {
+					severity = types.Critical
+					impact = "Equivalent to cluster-admin: complete cluster control for any principal with this role"
+				}
+				report(types.Finding{
+					RuleID:    "wildcard-rbac",
+					Severity:  severity,
+					Kind:      resource.Kind,
+					Name:      resource.Metadata.Name,
+					Namespace: resource.Metadata.Namespace,
+					Reason:    "Grants wildcard permissions (verbs: *, resources: *)" + groupsNote,
+					Impact:    impact,
+					Fix:       "Specify explicit apiGroups, verbs and resources",
+					Path:      fmt.Sprintf("rules[%d]", i),
+				})
+
+			case wildcardVerbs:
+				var matched []string
+				for _, r := range rule.Resources {
+					if sensitive[r] {
+						matched = append(matched, r)
+					}
+				}
+				if len(matched) == 0 {
+					continue
+				}
+				report(types.Finding{
+					RuleID:    "wildcard-rbac-verbs",
+					Severity:  types.High,
+					Kind:      resource.Kind,
+					Name:      resource.Metadata.Name,
+					Namespace: resource.Metadata.Namespace,
+					Reason:    fmt.Sprintf("Grants all verbs (verbs: *) on sensitive resources: %s", strings.Join(matched, ", ")),
+					Impact:    "Principals with this role can read credentials, exec into pods or rewrite RBAC to escalate further",
+					Fix:       "List only the verbs actually needed, e.g. get on secrets",
+					Path:      fmt.Sprintf("rules[%d]", i),
+				})
+
+			case wildcardResources:
+				report(types.Finding{
+					RuleID:    "wildcard-rbac-resources",
+					Severity:  types.Medium,
+					Kind:      resource.Kind,
+					Name:      resource.Metadata.Name,
+					Namespace: resource.Metadata.Namespace,
+					Reason:    fmt.Sprintf("Grants %s on every resource (resources: *)%s", strings.Join(rule.Verbs, ", "), groupsNote),
+					Impact:    "Silently extends to Secrets and to any resource type added to the cluster later",
+					Fix:       "Specify explicit resources",
+					Path:      fmt.Sprintf("rules[%d]", i),
+				})
 			}
 		}
 
-		if hasWildcardVerbs && hasWildcardResources {
-			return []types.Finding{{
-				RuleID:    "wildcard-rbac",
-				Severity:  types.High,
-				Kind:      resource.Kind,
-				Name:      resource.Metadata.Name,
-				Namespace: resource.Metadata.Namespace,
-				Reason:    "Grants wildcard permissions (verbs: *, resources: *)",
-				Impact:    "Complete cluster control for any principal with this role",
-				Fix:       "Specify explicit verbs and resources",
-			}}
+		return findings
+	}
+}
+
+// itemPath returns the path of the i-th entry of a pod spec list such as
+// containers or volumes, for finding snippets
+func itemPath(resource parser.K8sResource, list string, i int) string {
+	return fmt.Sprintf("%s.%s[%d]", parser.PodSpecPath(resource), list, i)
+}
+
+// podContainer is one container of a pod spec and where it sits
+type podContainer struct {
+	spec  map[string]interface{}
+	list  string // initContainers, containers or ephemeralContainers
+	index int
+}
+
+// podContainers returns the init, regular and ephemeral containers of a pod
+// spec, in that order, skipping entries that aren't mappings
+func podContainers(podSpec map[string]interface{}) []podContainer {
+	var containers []podContainer
+	for _, list := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		items, _ := podSpec[list].([]interface{})
+		for i, item := range items {
+			if spec, ok := item.(map[string]interface{}); ok {
+				containers = append(containers, podContainer{spec: spec, list: list, index: i})
+			}
 		}
 	}
+	return containers
+}
 
-	return nil
+// path returns the finding path of the container within resource
+func (c podContainer) path(resource parser.K8sResource) string {
+	return itemPath(resource, c.list, c.index)
+}
+
+// contains reports whether values includes want
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
 }
 
 // CheckClusterRoleBindingDefaultSA checks for ClusterRoleBinding to default service accounts
@@ -308,7 +821,7 @@ func CheckClusterRoleBindingDefaultSA(resource parser.K8sResource) []types.Findi
 		return nil
 	}
 
-	for _, subject := range resource.Subjects {
+	for i, subject := range resource.Subjects {
 		if subject.Kind == "ServiceAccount" && subject.Name == "default" {
 			return []types.Finding{{
 				RuleID:    "clusterrolebinding-default-sa",
@@ -319,6 +832,7 @@ func CheckClusterRoleBindingDefaultSA(resource parser.K8sResource) []types.Findi
 				Reason:    "Binds permissions to default service account",
 				Impact:    "All pods without explicit SA inherit these permissions",
 				Fix:       "Create and use a dedicated ServiceAccount",
+				Path:      fmt.Sprintf("subjects[%d]", i),
 			}}
 		}
 	}
@@ -352,12 +866,46 @@ func CheckPublicLoadBalancer(resource parser.K8sResource) []types.Finding {
 			Reason:    fmt.Sprintf("LoadBalancer service in %s namespace", resource.Metadata.Namespace),
 			Impact:    "Exposes internal services directly to the internet",
 			Fix:       "Use ClusterIP with Ingress, or add explicit justification",
+			Path:      "spec",
 		}}
 	}
 
 	return nil
 }
 
+// CheckRouteTLS checks for OpenShift Routes that serve plain HTTP, either
+// because TLS termination is not configured or because insecure traffic is allowed
+func CheckRouteTLS(resource parser.K8sResource) []types.Finding {
+	if resource.Kind != "Route" {
+		return nil
+	}
+
+	reason := ""
+	tls, ok := resource.Spec["tls"].(map[string]interface{})
+	termination, _ := tls["termination"].(string)
+	if !ok || termination == "" {
+		reason = "Route has no TLS termination configured"
+	} else if policy, _ := tls["insecureEdgeTerminationPolicy"].(string); policy == "Allow" {
+		reason = "Route allows plain HTTP alongside TLS (insecureEdgeTerminationPolicy: Allow)"
+	}
+
+	if reason == "" {
+		return nil
+	}
+
+	return []types.Finding{{
+		RuleID:    "route-without-tls",
+		Severity:  types.Medium,
+		Kind:      resource.Kind,
+		Name:      resource.Metadata.Name,
+		Namespace: resource.Metadata.Namespace,
+		Reason:    reason,
+		Impact:    "Traffic to the route, including credentials and session cookies, can be read or modified in transit",
+		Fix:       "Set spec.tls.termination to edge, reencrypt or passthrough and insecureEdgeTerminationPolicy to Redirect or None",
+		Path:      "spec",
+	}}
+}
+
 // CheckNodePort checks for NodePort services without justification
 func CheckNodePort(resource parser.K8sResource) []types.Finding {
 	if resource.Kind != "Service" {
@@ -376,6 +924,7 @@ func CheckNodePort(resource parser.K8sResource) []types.Finding {
 				Reason:    "NodePort service without justification annotation",
 				Impact:    "Bypasses ingress controls and exposes port on all nodes",
 				Fix:       "Use ClusterIP/LoadBalancer or add annotation: danger-scan/nodeport-justified",
+				Path:      "spec",
 			}}
 		}
 	}
@@ -390,34 +939,29 @@ func CheckLatestTag(resource parser.K8sResource) []types.Finding {
 		return nil
 	}
 
-	containers, ok := podSpec["containers"].([]interface{})
-	if !ok {
-		return nil
-	}
-
-	for _, c := range containers {
-		container, ok := c.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
 
 		if image, ok := container["image"].(string); ok {
 			if strings.HasSuffix(image, ":latest") || !strings.Contains(image, ":") {
-				return []types.Finding{{
+				findings = append(findings, types.Finding{
 					RuleID:    "latest-image-tag",
 					Severity:  types.Medium,
 					Kind:      resource.Kind,
 					Name:      resource.Metadata.Name,
 					Namespace: resource.Metadata.Namespace,
+					Container: containerName(container),
 					Reason:    "Uses :latest or untagged image",
 					Impact:    "Non-reproducible deployments and potential supply chain risk",
 					Fix:       "Pin to specific image digest or semantic version",
-				}}
+					Path:      c.path(resource),
+				})
 			}
 		}
 	}
 
-	return nil
+	return findings
 }
 
 // CheckHostNetwork checks for hostNetwork usage
@@ -429,52 +973,1488 @@ func CheckHostNetwork(resource parser.K8sResource) []types.Finding {
 
 	if hostNetwork, ok := podSpec["hostNetwork"].(bool); ok && hostNetwork {
 		return []types.Finding{{
-			RuleID:    "host-network",
-			Severity:  types.High,
-			Kind:      resource.Kind,
-			Name:      resource.Metadata.Name,
-			Namespace: resource.Metadata.Namespace,
-			Reason:    "Uses host network namespace",
-			Impact:    "Bypasses network policies and accesses host network",
-			Fix:       "Remove hostNetwork or set to false",
+			RuleID:         "host-network",
+			Severity:       types.High,
+			Kind:           resource.Kind,
+			Name:           resource.Metadata.Name,
+			Namespace:      resource.Metadata.Namespace,
+			Reason:         "Uses host network namespace",
+			Impact:         "Bypasses network policies and accesses host network",
+			Fix:            "Remove hostNetwork or set to false",
+			Path:           parser.PodSpecPath(resource) + ".hostNetwork",
+			SuggestedPatch: replaceField(parser.PodSpecPath(resource)+".hostNetwork", true, false),
 		}}
 	}
 
 	return nil
 }
 
-// CheckHostPIDIPC checks for hostPID or hostIPC usage
+// CheckHostPIDIPC checks for hostPID and hostIPC usage, with a finding for
+// each
 func CheckHostPIDIPC(resource parser.K8sResource) []types.Finding {
 	podSpec, ok := parser.GetPodSpec(resource)
 	if !ok {
 		return nil
 	}
 
+	var findings []types.Finding
 	if hostPID, ok := podSpec["hostPID"].(bool); ok && hostPID {
-		return []types.Finding{{
-			RuleID:    "host-pid-ipc",
-			Severity:  types.High,
-			Kind:      resource.Kind,
-			Name:      resource.Metadata.Name,
-			Namespace: resource.Metadata.Namespace,
-			Reason:    "Uses host PID namespace",
-			Impact:    "Can inspect and kill processes on the host",
-			Fix:       "Remove hostPID or set to false",
-		}}
+		findings = append(findings, types.Finding{
+			RuleID:         "host-pid-ipc",
+			Severity:       types.High,
+			Kind:           resource.Kind,
+			Name:           resource.Metadata.Name,
+			Namespace:      resource.Metadata.Namespace,
+			Reason:         "Uses host PID namespace",
+			Impact:         "Can inspect and kill processes on the host",
+			Fix:            "Remove hostPID or set to false",
+			Path:           parser.PodSpecPath(resource) + ".hostPID",
+			SuggestedPatch: replaceField(parser.PodSpecPath(resource)+".hostPID", true, false),
+		})
 	}
 
 	if hostIPC, ok := podSpec["hostIPC"].(bool); ok && hostIPC {
-		return []types.Finding{{
-			RuleID:    "host-pid-ipc",
-			Severity:  types.High,
-			Kind:      resource.Kind,
-			Name:      resource.Metadata.Name,
-			Namespace: resource.Metadata.Namespace,
-			Reason:    "Uses host IPC namespace",
-			Impact:    "Can access shared memory and semaphores on host",
-			Fix:       "Remove hostIPC or set to false",
-		}}
+		findings = append(findings, types.Finding{
+			RuleID:         "host-pid-ipc",
+			Severity:       types.High,
+			Kind:           resource.Kind,
+			Name:           resource.Metadata.Name,
+			Namespace:      resource.Metadata.Namespace,
+			Reason:         "Uses host IPC namespace",
+			Impact:         "Can access shared memory and semaphores on host",
+			Fix:            "Remove hostIPC or set to false",
+			Path:           parser.PodSpecPath(resource) + ".hostIPC",
+			SuggestedPatch: replaceField(parser.PodSpecPath(resource)+".hostIPC", true, false),
+		})
 	}
 
-	return nil
+	return findings
+}
+
+// CheckHostPort checks for container ports bound directly on the host
+func CheckHostPort(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		ports, ok := container["ports"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for j, p := range ports {
+			port, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			hostPort, ok := port["hostPort"].(int)
+			if !ok || hostPort == 0 {
+				continue
+			}
+
+			reason := fmt.Sprintf("Container binds hostPort %d on the node", hostPort)
+			if hostPort < 1024 {
+				reason = fmt.Sprintf("Container binds privileged hostPort %d on the node", hostPort)
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "host-port",
+				Severity:  types.High,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    reason,
+				Impact:    "Bypasses Services and node firewalling by exposing the pod on the node IP",
+				Fix:       "Remove hostPort and expose the pod through a Service",
+				Path:      c.path(resource) + fmt.Sprintf(".ports[%d]", j),
+			})
+		}
+	}
+
+	return findings
+}
+
+// CheckEnvFromChecksum checks for workloads consuming ConfigMaps or Secrets via
+// envFrom without a checksum annotation to roll pods when they change
+func CheckEnvFromChecksum(resource parser.K8sResource) []types.Finding {
+	if resource.Kind != "Deployment" && resource.Kind != "StatefulSet" && resource.Kind != "DaemonSet" && resource.Kind != "DeploymentConfig" {
+		return nil
+	}
+
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	// The checksum annotation lives on the pod template so a change rolls the pods
+	if template, ok := resource.Spec["template"].(map[string]interface{}); ok {
+		if metadata, ok := template["metadata"].(map[string]interface{}); ok {
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				for key := range annotations {
+					if strings.HasPrefix(key, "checksum/") {
+						return nil
+					}
+				}
+			}
+		}
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		envFrom, ok := container["envFrom"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, e := range envFrom {
+			source, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			var refKind, refName string
+			if ref, ok := source["configMapRef"].(map[string]interface{}); ok {
+				refKind = "ConfigMap"
+				refName, _ = ref["name"].(string)
+			} else if ref, ok := source["secretRef"].(map[string]interface{}); ok {
+				refKind = "Secret"
+				refName, _ = ref["name"].(string)
+			} else {
+				continue
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "envfrom-without-checksum",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Consumes %s %q via envFrom without a checksum annotation", refKind, refName),
+				Impact:    "Changes to the referenced config do not restart pods, leaving them on stale values",
+				Fix:       "Add a checksum/config annotation to the pod template that changes with the config",
+				Path:      c.path(resource),
+			})
+			break
+		}
+	}
+
+	return findings
+}
+
+// CheckCapabilitiesNotDropped checks for containers that keep the default
+// Linux capability set instead of dropping ALL
+func CheckCapabilitiesNotDropped(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		dropsAll := false
+		if securityContext, ok := container["securityContext"].(map[string]interface{}); ok {
+			if capabilities, ok := securityContext["capabilities"].(map[string]interface{}); ok {
+				if drop, ok := capabilities["drop"].([]interface{}); ok {
+					for _, d := range drop {
+						if name, ok := d.(string); ok && strings.EqualFold(name, "ALL") {
+							dropsAll = true
+							break
+						}
+					}
+				}
+			}
+		}
+
+		if !dropsAll {
+			findings = append(findings, types.Finding{
+				RuleID:    "capabilities-not-dropped",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    "Container does not drop ALL capabilities",
+				Impact:    "Retains the runtime's default capability set, widening the kernel attack surface",
+				Fix:       "Set securityContext.capabilities.drop: [\"ALL\"] and add back only what is needed",
+				Path:      c.path(resource),
+			})
+		}
+	}
+
+	return findings
+}
+
+// CheckWritableRootFilesystem checks for containers whose root filesystem
+// is writable. readOnlyRootFilesystem only exists on the container
+// securityContext; a pod-level value is ignored by Kubernetes, so it never
+// satisfies the rule and is called out in the reason instead.
+func CheckWritableRootFilesystem(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	podReadOnly := false
+	if podSecurityContext, ok := podSpec["securityContext"].(map[string]interface{}); ok {
+		if readOnly, ok := podSecurityContext["readOnlyRootFilesystem"].(bool); ok && readOnly {
+			podReadOnly = true
+		}
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		readOnly := false
+		if securityContext, ok := container["securityContext"].(map[string]interface{}); ok {
+			if val, ok := securityContext["readOnlyRootFilesystem"].(bool); ok {
+				readOnly = val
+			}
+		}
+		if readOnly {
+			continue
+		}
+
+		reason := fmt.Sprintf("Container %s does not set readOnlyRootFilesystem: true", containerName(container))
+		if podReadOnly {
+			reason = fmt.Sprintf("Container %s relies on readOnlyRootFilesystem in the pod securityContext, where Kubernetes ignores it", containerName(container))
+		}
+
+		findings = append(findings, types.Finding{
+			RuleID:         "writable-root-filesystem",
+			Severity:       types.Medium,
+			Kind:           resource.Kind,
+			Name:           resource.Metadata.Name,
+			Namespace:      resource.Metadata.Namespace,
+			Container:      containerName(container),
+			Reason:         reason,
+			Impact:         "An attacker can modify binaries and configuration or drop tools into the container filesystem",
+			Fix:            "Set securityContext.readOnlyRootFilesystem: true on the container and mount an emptyDir for paths that must be writable",
+			Path:           c.path(resource),
+			SuggestedPatch: setSecurityContextField(c.path(resource), container, "readOnlyRootFilesystem", true),
+		})
+	}
+
+	return findings
+}
+
+// dangerousCapabilities are capabilities that, once added, give a container
+// a direct path to the host kernel or other processes on the node
+var dangerousCapabilities = map[string]bool{
+	"ALL":             true,
+	"SYS_ADMIN":       true,
+	"NET_ADMIN":       true,
+	"SYS_PTRACE":      true,
+	"SYS_MODULE":      true,
+	"SYS_RAWIO":       true,
+	"DAC_READ_SEARCH": true,
+	"BPF":             true,
+}
+
+// CheckDangerousCapabilities checks for containers that add capabilities
+// commonly used to escape to the node. Capability names are matched with or
+// without the CAP_ prefix.
+func CheckDangerousCapabilities(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		add, _ := capabilities(container)
+		var dangerous []string
+		for _, capability := range add {
+			if name := strings.TrimPrefix(capability, "CAP_"); dangerousCapabilities[name] {
+				dangerous = append(dangerous, name)
+			}
+		}
+		if len(dangerous) == 0 {
+			continue
+		}
+
+		findings = append(findings, types.Finding{
+			RuleID:    "dangerous-capabilities",
+			Severity:  types.High,
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			Container: containerName(container),
+			Reason:    fmt.Sprintf("Container %s adds dangerous capabilities: %s", containerName(container), strings.Join(dangerous, ", ")),
+			Impact:    "These capabilities allow mounting filesystems, loading kernel modules, tracing other processes or reconfiguring the node network, the usual steps of a container escape",
+			Fix:       "Remove them from securityContext.capabilities.add; drop ALL and add back only narrow capabilities such as NET_BIND_SERVICE",
+			Path:      c.path(resource) + ".securityContext.capabilities",
+		})
+	}
+
+	return findings
+}
+
+// CheckMissingSecurityContext checks for containers with no securityContext at
+// the container or pod level. Only enabled in strict mode.
+func CheckMissingSecurityContext(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	if _, hasPodSC := podSpec["securityContext"].(map[string]interface{}); hasPodSC {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		if _, ok := container["securityContext"].(map[string]interface{}); !ok {
+			name, _ := container["name"].(string)
+			findings = append(findings, types.Finding{
+				RuleID:    "missing-security-context",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Container %q has no securityContext", name),
+				Impact:    "Runs with every runtime default: root user, default capabilities, writable root filesystem",
+				Fix:       "Set runAsNonRoot: true, capabilities.drop: [\"ALL\"], readOnlyRootFilesystem: true and allowPrivilegeEscalation: false",
+				Path:      c.path(resource),
+			})
+		}
+	}
+
+	return findings
+}
+
+// CheckPrivilegeEscalationUnset checks for containers that leave
+// allowPrivilegeEscalation unset, which the runtime treats as allowed. Only
+// enabled in strict mode; an explicit true is reported by
+// privilege-escalation-allowed and privileged containers always escalate.
+func CheckPrivilegeEscalationUnset(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		securityContext, _ := container["securityContext"].(map[string]interface{})
+		if privileged, ok := securityContext["privileged"].(bool); ok && privileged {
+			continue
+		}
+		if _, set := securityContext["allowPrivilegeEscalation"].(bool); set {
+			continue
+		}
+
+		name, _ := container["name"].(string)
+		findings = append(findings, types.Finding{
+			RuleID:         "privilege-escalation-not-disabled",
+			Severity:       types.Medium,
+			Kind:           resource.Kind,
+			Name:           resource.Metadata.Name,
+			Namespace:      resource.Metadata.Namespace,
+			Container:      containerName(container),
+			Reason:         fmt.Sprintf("Container %q does not set allowPrivilegeEscalation, which defaults to allowed", name),
+			Impact:         "setuid binaries and file capabilities in the image can raise privileges inside the container",
+			Fix:            "Set securityContext.allowPrivilegeEscalation: false",
+			Path:           c.path(resource),
+			SuggestedPatch: setSecurityContextField(c.path(resource), container, "allowPrivilegeEscalation", false),
+		})
+	}
+
+	return findings
+}
+
+// CheckDefaultNamespace checks for workloads deployed to the default namespace
+func CheckDefaultNamespace(resource parser.K8sResource) []types.Finding {
+	// Only workloads; cluster-scoped kinds have no namespace to begin with
+	if _, ok := parser.GetPodSpec(resource); !ok {
+		return nil
+	}
+
+	namespace := resource.Metadata.Namespace
+	if namespace != "" && namespace != "default" {
+		return nil
+	}
+
+	return []types.Finding{{
+		RuleID:    "default-namespace",
+		Severity:  types.Medium,
+		Kind:      resource.Kind,
+		Name:      resource.Metadata.Name,
+		Namespace: namespace,
+		Reason:    "Workload runs in the default namespace",
+		Impact:    "Shares a namespace with unrelated workloads, complicating RBAC, quotas and policy targeting",
+		Fix:       "Set metadata.namespace to a dedicated namespace for this application",
+		Path:      "metadata",
+	}}
+}
+
+// CheckSuperPod checks for pods that stack several host-access escape vectors.
+// Each vector is reported on its own as HIGH; together they amount to a root
+// shell on the node and are escalated to a single CRITICAL finding.
+func CheckSuperPod(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var vectors []string
+
+	if len(CheckPrivilegedContainer(resource)) > 0 {
+		vectors = append(vectors, "privileged")
+	}
+	if hostNetwork, ok := podSpec["hostNetwork"].(bool); ok && hostNetwork {
+		vectors = append(vectors, "hostNetwork")
+	}
+	if hostPID, ok := podSpec["hostPID"].(bool); ok && hostPID {
+		vectors = append(vectors, "hostPID")
+	}
+	if hostIPC, ok := podSpec["hostIPC"].(bool); ok && hostIPC {
+		vectors = append(vectors, "hostIPC")
+	}
+	if len(CheckDockerSocket(resource)) > 0 {
+		vectors = append(vectors, "docker.sock")
+	}
+
+	if len(vectors) < 2 {
+		return nil
+	}
+
+	return []types.Finding{{
+		RuleID:    "super-pod",
+		Severity:  types.Critical,
+		Kind:      resource.Kind,
+		Name:      resource.Metadata.Name,
+		Namespace: resource.Metadata.Namespace,
+		Path:      parser.PodSpecPath(resource),
+		Reason:    fmt.Sprintf("Combines multiple host escape vectors: %s", strings.Join(vectors, " + ")),
+		Impact:    "Effectively a root shell on the node; compromise of this pod is compromise of the host",
+		Fix:       "Split the workload so no single pod needs more than one host-level privilege, and remove the rest",
+	}}
+}
+
+// maxBackoffLimit is the largest backoffLimit job-without-limits accepts.
+// Kubernetes defaults it to 6.
+const maxBackoffLimit = 10
+
+// CheckJobSafety checks for Jobs that can run forever or retry too often and
+// CronJobs that allow overlapping runs
+func CheckJobSafety(resource parser.K8sResource) []types.Finding {
+	var jobSpec map[string]interface{}
+	var jobSpecPath string
+
+	switch resource.Kind {
+	case "Job":
+		jobSpec, jobSpecPath = resource.Spec, "spec"
+	case "CronJob":
+		jobTemplate, ok := resource.Spec["jobTemplate"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		jobSpec, _ = jobTemplate["spec"].(map[string]interface{})
+		jobSpecPath = "spec.jobTemplate.spec"
+	default:
+		return nil
+	}
+
+	var findings []types.Finding
+
+	// An unset backoffLimit defaults to 6 retries, which is bounded, so only
+	// a missing deadline or an explicitly large retry budget is flagged
+	var path string
+	var problems, fixes []string
+	if _, ok := jobSpec["activeDeadlineSeconds"]; !ok {
+		path = jobSpecPath + ".activeDeadlineSeconds"
+		problems = append(problems, "does not set activeDeadlineSeconds")
+		fixes = append(fixes, "set "+jobSpecPath+".activeDeadlineSeconds")
+	}
+	if backoffLimit, ok := jobSpec["backoffLimit"].(int); ok && backoffLimit > maxBackoffLimit {
+		if path == "" {
+			path = jobSpecPath + ".backoffLimit"
+		}
+		problems = append(problems, fmt.Sprintf("sets backoffLimit to %d", backoffLimit))
+		fixes = append(fixes, fmt.Sprintf("lower %s.backoffLimit to %d or less", jobSpecPath, maxBackoffLimit))
+	}
+
+	if len(problems) > 0 {
+		fix := strings.Join(fixes, " and ")
+		findings = append(findings, types.Finding{
+			RuleID:    "job-without-limits",
+			Severity:  types.Medium,
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			Path:      path,
+			Reason:    "Job " + strings.Join(problems, " and "),
+			Impact:    "A failing or hung Job can retry or run indefinitely and exhaust cluster resources",
+			Fix:       strings.ToUpper(fix[:1]) + fix[1:],
+		})
+	}
+
+	if resource.Kind == "CronJob" {
+		policy, _ := resource.Spec["concurrencyPolicy"].(string)
+		if policy != "Forbid" && policy != "Replace" {
+			findings = append(findings, types.Finding{
+				RuleID:    "cronjob-concurrent-runs",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Path:      "spec.concurrencyPolicy",
+				Reason:    "CronJob allows concurrent runs (concurrencyPolicy is Allow or unset)",
+				Impact:    "Slow runs pile up on top of each other, multiplying load and contending for the same data",
+				Fix:       "Set spec.concurrencyPolicy to Forbid or Replace",
+			})
+		}
+	}
+
+	return findings
+}
+
+// shells lists interpreters commonly used to run inline scripts
+var shells = map[string]bool{
+	"sh":   true,
+	"bash": true,
+	"ash":  true,
+	"dash": true,
+	"zsh":  true,
+	"ksh":  true,
+}
+
+// maxQuotedCommand caps how much of a command is echoed in a finding
+const maxQuotedCommand = 80
+
+// CheckShellEntrypoint checks for containers whose command runs an inline
+// shell script via "sh -c"
+func CheckShellEntrypoint(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		var argv []string
+		for _, key := range []string{"command", "args"} {
+			if list, ok := container[key].([]interface{}); ok {
+				for _, item := range list {
+					if arg, ok := item.(string); ok {
+						argv = append(argv, arg)
+					}
+				}
+			}
+		}
+
+		if len(argv) < 2 || !shells[path.Base(argv[0])] {
+			continue
+		}
+
+		for j, arg := range argv[1:] {
+			// argv[j+1] is the flag; the inline script must follow it
+			if arg != "-c" || j+2 >= len(argv) {
+				continue
+			}
+
+			quoted := strings.Join(argv, " ")
+			if len(quoted) > maxQuotedCommand {
+				quoted = quoted[:maxQuotedCommand] + "..."
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "shell-entrypoint",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Container runs an inline shell script: %q", quoted),
+				Impact:    "Obscures what actually runs and gives attackers a ready-made shell foothold",
+				Fix:       "Bake the script into the image or a ConfigMap-mounted file and invoke it directly",
+				Path:      c.path(resource),
+			})
+			break
+		}
+	}
+
+	return findings
+}
+
+// sensitiveMountPaths are in-container directories whose contents must not be
+// replaced by a writable volume
+var sensitiveMountPaths = map[string]bool{
+	"/":              true,
+	"/bin":           true,
+	"/sbin":          true,
+	"/lib":           true,
+	"/lib64":         true,
+	"/etc":           true,
+	"/usr":           true,
+	"/usr/bin":       true,
+	"/usr/sbin":      true,
+	"/usr/lib":       true,
+	"/usr/local/bin": true,
+}
+
+// serviceAccountTokenPath is where the kubelet mounts the pod's API token
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// readOnlyVolumeSources are volume types the kubelet always mounts read-only
+var readOnlyVolumeSources = map[string]bool{
+	"configMap":   true,
+	"secret":      true,
+	"downwardAPI": true,
+	"projected":   true,
+}
+
+// CheckSensitiveMountPath checks for writable volumes mounted over binaries,
+// system configuration or the service account token
+func CheckSensitiveMountPath(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	// Map volume names to their source type, e.g. emptyDir or hostPath
+	volumeSources := make(map[string]string)
+	if volumes, ok := podSpec["volumes"].([]interface{}); ok {
+		for _, v := range volumes {
+			volume, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := volume["name"].(string)
+			for key := range volume {
+				if key != "name" {
+					volumeSources[name] = key
+				}
+			}
+		}
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		mounts, ok := container["volumeMounts"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for j, m := range mounts {
+			mount, ok := m.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if readOnly, ok := mount["readOnly"].(bool); ok && readOnly {
+				continue
+			}
+
+			volumeName, _ := mount["name"].(string)
+			source := volumeSources[volumeName]
+			if readOnlyVolumeSources[source] {
+				continue
+			}
+
+			mountPath, ok := mount["mountPath"].(string)
+			if !ok || mountPath == "" {
+				continue
+			}
+			mountPath = path.Clean(mountPath)
+
+			shadowsToken := mountPath == serviceAccountTokenPath ||
+				strings.HasPrefix(serviceAccountTokenPath, mountPath+"/")
+			if !sensitiveMountPaths[mountPath] && !shadowsToken {
+				continue
+			}
+
+			if source == "" {
+				source = "unknown"
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "sensitive-mount-path",
+				Severity:  types.High,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Writable %s volume %q is mounted over %s", source, volumeName, mountPath),
+				Impact:    "Lets a compromised process replace binaries, system config or the service account token",
+				Fix:       "Mount the volume elsewhere or set readOnly: true on the volumeMount",
+				Path:      c.path(resource) + fmt.Sprintf(".volumeMounts[%d]", j),
+			})
+		}
+	}
+
+	return findings
+}
+
+// parseImageRef splits an image reference of the form
+// [registry[:port]/]repository[:tag][@digest] into its name, tag and digest
+func parseImageRef(image string) (name, tag, digest string) {
+	name = image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+
+	// A colon after the last slash separates the tag; earlier ones are ports
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	return name, tag, digest
+}
+
+// CheckImageDigestPinning checks for container images referenced by tag
+// alone. Unlike latest-image-tag, a pinned version tag is not enough: tags
+// can be moved to different content, digests cannot.
+func CheckImageDigestPinning(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+		image, ok := container["image"].(string)
+		if !ok || image == "" {
+			continue
+		}
+
+		name, tag, digest := parseImageRef(image)
+		if digest != "" {
+			continue
+		}
+		if tag == "" {
+			tag = "latest"
+		}
+
+		findings = append(findings, types.Finding{
+			RuleID:    "image-not-digest-pinned",
+			Severity:  types.Medium,
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			Container: containerName(container),
+			Reason:    fmt.Sprintf("Container %q image %s is referenced by tag %q without a digest", containerName(container), image, tag),
+			Impact:    "Whoever controls the registry can push different content under the same tag, and nodes will run it on the next pull",
+			Fix:       fmt.Sprintf("Pin the image by digest, e.g. %s:%s@sha256:<digest>", name, tag),
+			Path:      c.path(resource),
+		})
+	}
+
+	return findings
+}
+
+// CheckImagePullPolicy checks that each container's imagePullPolicy makes
+// sense for how its image is pinned
+func CheckImagePullPolicy(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		image, ok := container["image"].(string)
+		if !ok {
+			continue
+		}
+		policy, ok := container["imagePullPolicy"].(string)
+		if !ok {
+			continue
+		}
+
+		_, tag, digest := parseImageRef(image)
+		floating := digest == "" && (tag == "" || tag == "latest")
+
+		if floating && (policy == "IfNotPresent" || policy == "Never") {
+			findings = append(findings, types.Finding{
+				RuleID:    "stale-image-pull-policy",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Floating image %s uses imagePullPolicy: %s", image, policy),
+				Impact:    "Nodes keep running whatever copy they cached first, so replicas silently diverge",
+				Fix:       "Pin the image to a version or digest, or use imagePullPolicy: Always",
+				Path:      c.path(resource),
+			})
+		}
+
+		if digest != "" && policy == "Always" {
+			findings = append(findings, types.Finding{
+				RuleID:    "redundant-image-pull",
+				Severity:  types.Low,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Digest-pinned image %s uses imagePullPolicy: Always", image),
+				Impact:    "Every pod start contacts the registry for content that cannot change, slowing starts and adding a registry dependency",
+				Fix:       "Use imagePullPolicy: IfNotPresent for digest-pinned images",
+				Path:      c.path(resource),
+			})
+		}
+	}
+
+	return findings
+}
+
+// weakSecretValues are placeholder or default credentials that should never
+// be committed as real secret values
+var weakSecretValues = map[string]bool{
+	"changeme":    true,
+	"change-me":   true,
+	"password":    true,
+	"passw0rd":    true,
+	"admin":       true,
+	"root":        true,
+	"secret":      true,
+	"default":     true,
+	"test":        true,
+	"123456":      true,
+	"placeholder": true,
+	"todo":        true,
+}
+
+// CheckWeakSecretValues checks Secret manifests for empty or placeholder values,
+// with one finding per weak key. Only the key name is reported; secret values
+// are never echoed.
+func CheckWeakSecretValues(resource parser.K8sResource) []types.Finding {
+	if resource.Kind != "Secret" {
+		return nil
+	}
+
+	// Each key remembers the field it came from for the finding's path
+	values := make(map[string]string)
+	fields := make(map[string]string)
+	if data, ok := resource.Raw["data"].(map[string]interface{}); ok {
+		for key, v := range data {
+			encoded, _ := v.(string)
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				continue
+			}
+			values[key] = string(decoded)
+			fields[key] = "data"
+		}
+	}
+	// stringData wins over data for the same key, as in the API server
+	if stringData, ok := resource.Raw["stringData"].(map[string]interface{}); ok {
+		for key, v := range stringData {
+			fields[key] = "stringData"
+			if v == nil {
+				values[key] = ""
+				continue
+			}
+			values[key] = fmt.Sprint(v)
+		}
+	}
+
+	// Visit keys in a stable order so findings are reported deterministically
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var findings []types.Finding
+	for _, key := range keys {
+		value := strings.TrimSpace(values[key])
+		if value != "" && !weakSecretValues[strings.ToLower(value)] {
+			continue
+		}
+
+		findings = append(findings, types.Finding{
+			RuleID:    "weak-secret-value",
+			Severity:  types.Medium,
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			Path:      fields[key] + "." + key,
+			Reason:    fmt.Sprintf("Secret key %q is empty or a well-known placeholder value", key),
+			Impact:    "Placeholder credentials get deployed for real and are trivially guessable",
+			Fix:       "Generate a strong value and keep it out of version control (e.g. External Secrets or Sealed Secrets)",
+		})
+	}
+
+	return findings
+}
+
+// secretEnvKeywords mark an environment variable name as holding a
+// credential. Names are matched after uppercasing and dropping - and _.
+var secretEnvKeywords = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "APIKEY", "PRIVATEKEY", "ACCESSKEY", "CREDENTIAL"}
+
+// secretEnvNameSuffixes mark a credential-looking name as pointing at the
+// secret rather than holding it, e.g. DB_PASSWORD_FILE
+var secretEnvNameSuffixes = []string{"_FILE", "_PATH", "_DIR", "_URL", "_NAME", "_TTL", "_EXPIRY"}
+
+// minEntropyLength and minEntropyBits bound the high-entropy check: random
+// base64 or alphanumeric keys of 20+ characters score above 4 bits per
+// character, while words, hex digests and UUIDs stay below
+const (
+	minEntropyLength = 20
+	minEntropyBits   = 4.0
+)
+
+// CheckSecretInEnv checks for credentials written as literal container env
+// values, either under a credential-looking name or as a high-entropy
+// string. Only the variable name is reported, never the value.
+func CheckSecretInEnv(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		env, _ := container["env"].([]interface{})
+		for j, e := range env {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := entry["name"].(string)
+			value, ok := entry["value"].(string)
+			if !ok {
+				continue
+			}
+
+			var why string
+			switch {
+			case looksLikeSecretName(name) && looksLikeSecretValue(value):
+				why = "credential-like name"
+			case highEntropy(value):
+				why = "high-entropy string"
+			default:
+				continue
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "secret-in-env",
+				Severity:  types.High,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Container %s sets env %s to a plaintext value (%s)", containerName(container), name, why),
+				Impact:    "The credential is stored in plain text in the manifest, its git history and the pod spec, readable by anyone who can get the workload",
+				Fix:       "Move the value into a Secret and reference it with env[].valueFrom.secretKeyRef, then rotate the exposed credential",
+				Path:      fmt.Sprintf("%s.env[%d]", c.path(resource), j),
+			})
+		}
+	}
+
+	return findings
+}
+
+// looksLikeSecretName reports whether an env var name suggests a credential
+func looksLikeSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, suffix := range secretEnvNameSuffixes {
+		if strings.HasSuffix(upper, suffix) {
+			return false
+		}
+	}
+
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(upper)
+	for _, keyword := range secretEnvKeywords {
+		if strings.Contains(normalized, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeSecretValue filters out literal values that can't be a
+// credential: empty strings, $(VAR) references, paths, numbers and booleans
+func looksLikeSecretValue(value string) bool {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "",
+		strings.HasPrefix(value, "$("),
+		strings.HasPrefix(value, "/"):
+		return false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return false
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return false
+	}
+	return true
+}
+
+// highEntropy reports whether a value looks like a random key: long, free
+// of spaces, mixing upper case, lower case and digits, with a high Shannon
+// entropy per character
+func highEntropy(value string) bool {
+	if len(value) < minEntropyLength || strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, r := range value {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+		counts[r]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+
+	entropy := 0.0
+	length := float64(len([]rune(value)))
+	for _, n := range counts {
+		p := float64(n) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minEntropyBits
+}
+
+// CheckSecretVolumeMode checks for Secret volumes whose defaultMode or
+// per-item mode grants group or world access
+func CheckSecretVolumeMode(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	volumes, ok := podSpec["volumes"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for i, v := range volumes {
+		volume, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := volume["name"].(string)
+		for _, src := range secretVolumeSources(volume) {
+			setting, ok := permissiveMode(src)
+			if !ok {
+				continue
+			}
+			findings = append(findings, types.Finding{
+				RuleID:    "secret-volume-permissive-mode",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Reason:    fmt.Sprintf("Secret volume %s sets %s, readable beyond the file owner", name, setting),
+				Impact:    "Any process in the pod running as a different user, or sharing the group, can read the secret files",
+				Fix:       fmt.Sprintf("Set defaultMode: 0400 on volume %s and narrow any per-item modes to 0400", name),
+				Path:      itemPath(resource, "volumes", i),
+			})
+			break
+		}
+	}
+
+	return findings
+}
+
+// secretVolumeSources returns the secret sources of a volume: the volume's
+// own secret, or the secret sources of a projected volume. A projected
+// volume's defaultMode is carried onto each source that doesn't set one.
+func secretVolumeSources(volume map[string]interface{}) []map[string]interface{} {
+	if secret, ok := volume["secret"].(map[string]interface{}); ok {
+		return []map[string]interface{}{secret}
+	}
+
+	projected, ok := volume["projected"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	sources, _ := projected["sources"].([]interface{})
+	var secrets []map[string]interface{}
+	for _, s := range sources {
+		source, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		secret, ok := source["secret"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		merged := map[string]interface{}{"items": secret["items"]}
+		if mode, ok := projected["defaultMode"]; ok {
+			merged["defaultMode"] = mode
+		}
+		secrets = append(secrets, merged)
+	}
+	return secrets
+}
+
+// permissiveMode describes the first mode setting of a secret source that
+// grants group or other permissions. Unset modes are not reported.
+func permissiveMode(secret map[string]interface{}) (string, bool) {
+	if mode, ok := fileMode(secret["defaultMode"]); ok && mode&0o077 != 0 {
+		return fmt.Sprintf("defaultMode %04o", mode), true
+	}
+
+	items, _ := secret["items"].([]interface{})
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if mode, ok := fileMode(item["mode"]); ok && mode&0o077 != 0 {
+			key, _ := item["key"].(string)
+			return fmt.Sprintf("mode %04o for key %s", mode, key), true
+		}
+	}
+	return "", false
+}
+
+// fileMode converts a volume mode value to an int. YAML octal literals such
+// as 0644 already decode to ints; JSON manifests carry the decimal value, and
+// quoted strings are parsed as octal when they have a leading zero.
+func fileMode(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	case string:
+		mode, err := strconv.ParseInt(v, 0, 32)
+		if err != nil {
+			return 0, false
+		}
+		return int(mode), true
+	default:
+		return 0, false
+	}
+}
+
+// CheckReplicaSpread checks for multi-replica Deployments, StatefulSets,
+// ReplicaSets, ReplicationControllers and DeploymentConfigs with nothing
+// spreading their replicas across nodes or zones
+func CheckReplicaSpread(resource parser.K8sResource) []types.Finding {
+	switch resource.Kind {
+	case "Deployment", "StatefulSet", "ReplicaSet", "ReplicationController", "DeploymentConfig":
+	default:
+		return nil
+	}
+
+	replicas, ok := resource.Spec["replicas"].(int)
+	if !ok || replicas <= 1 {
+		return nil
+	}
+
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	if affinity, ok := podSpec["affinity"].(map[string]interface{}); ok {
+		if _, ok := affinity["podAntiAffinity"].(map[string]interface{}); ok {
+			return nil
+		}
+	}
+	if constraints, ok := podSpec["topologySpreadConstraints"].([]interface{}); ok && len(constraints) > 0 {
+		return nil
+	}
+
+	return []types.Finding{{
+		RuleID:    "replicas-not-spread",
+		Severity:  types.Medium,
+		Kind:      resource.Kind,
+		Name:      resource.Metadata.Name,
+		Namespace: resource.Metadata.Namespace,
+		Reason:    fmt.Sprintf("%d replicas with no podAntiAffinity or topologySpreadConstraints", replicas),
+		Impact:    "The scheduler may place all replicas on one node or zone, so a single node failure takes down every replica",
+		Fix:       "Add topologySpreadConstraints on kubernetes.io/hostname (and topology.kubernetes.io/zone) or a podAntiAffinity rule",
+		Path:      "spec.replicas",
+	}}
+}
+
+// CheckMemoryEmptyDir checks for memory-backed emptyDir volumes without a sizeLimit
+func CheckMemoryEmptyDir(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	volumes, ok := podSpec["volumes"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var findings []types.Finding
+	for i, v := range volumes {
+		volume, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		emptyDir, ok := volume["emptyDir"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if medium, _ := emptyDir["medium"].(string); medium != "Memory" {
+			continue
+		}
+		if _, ok := emptyDir["sizeLimit"]; ok {
+			continue
+		}
+
+		name, _ := volume["name"].(string)
+		findings = append(findings, types.Finding{
+			RuleID:    "memory-emptydir-without-limit",
+			Severity:  types.Medium,
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			Reason:    fmt.Sprintf("emptyDir volume %s uses medium: Memory without a sizeLimit", name),
+			Impact:    "Files written to the tmpfs consume node memory and can grow until the node runs out and starts OOM-killing pods",
+			Fix:       fmt.Sprintf("Set emptyDir.sizeLimit on volume %s (e.g. sizeLimit: 256Mi)", name),
+			Path:      itemPath(resource, "volumes", i),
+		})
+	}
+
+	return findings
+}
+
+// ResourceLimitsRule returns a rule that flags containers without a CPU or
+// memory limit and, when requireRequests is set, without a CPU or memory
+// request. A LimitRange may default these per namespace, but manifests
+// can't show it, so the container is flagged either way.
+func ResourceLimitsRule(requireRequests bool) Rule {
+	fields := []string{"limits.cpu", "limits.memory"}
+	if requireRequests {
+		fields = append(fields, "requests.cpu", "requests.memory")
+	}
+
+	return func(resource parser.K8sResource) []types.Finding {
+		podSpec, ok := parser.GetPodSpec(resource)
+		if !ok {
+			return nil
+		}
+
+		var findings []types.Finding
+		for _, c := range podContainers(podSpec) {
+			// Ephemeral containers may not set resources
+			if c.list == "ephemeralContainers" {
+				continue
+			}
+			container := c.spec
+
+			resources, _ := container["resources"].(map[string]interface{})
+			var missing []string
+			for _, field := range fields {
+				kind, name, _ := strings.Cut(field, ".")
+				values, _ := resources[kind].(map[string]interface{})
+				if _, ok := values[name]; !ok {
+					missing = append(missing, "resources."+field)
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+
+			findings = append(findings, types.Finding{
+				RuleID:    "no-resource-limits",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Container %s does not set %s", containerName(container), strings.Join(missing, ", ")),
+				Impact:    "An unbounded container can take all of a node's CPU or memory, starving or OOM-killing its neighbors",
+				Fix:       "Set resources.limits.cpu and resources.limits.memory (and matching requests) sized from observed usage",
+				Path:      c.path(resource),
+			})
+		}
+
+		return findings
+	}
+}
+
+// privilegedPortLimit is the first port a non-root process may bind without
+// CAP_NET_BIND_SERVICE
+const privilegedPortLimit = 1024
+
+// CheckPrivilegedPortBind checks for non-root containers that declare a port
+// below 1024 without being granted NET_BIND_SERVICE, so the bind fails at runtime
+func CheckPrivilegedPortBind(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	podNonRoot := false
+	podRunAsUser := -1
+	unprivilegedStart := privilegedPortLimit
+	if podSecurityContext, ok := podSpec["securityContext"].(map[string]interface{}); ok {
+		podNonRoot, _ = podSecurityContext["runAsNonRoot"].(bool)
+		if runAsUser, ok := podSecurityContext["runAsUser"].(int); ok {
+			podRunAsUser = runAsUser
+		}
+		// The runtime may lower the privileged range with a namespaced sysctl
+		sysctls, _ := podSecurityContext["sysctls"].([]interface{})
+		for _, s := range sysctls {
+			sysctl, _ := s.(map[string]interface{})
+			if name, _ := sysctl["name"].(string); name == "net.ipv4.ip_unprivileged_port_start" {
+				value, _ := sysctl["value"].(string)
+				fmt.Sscanf(value, "%d", &unprivilegedStart)
+			}
+		}
+	}
+
+	var findings []types.Finding
+	for _, c := range podContainers(podSpec) {
+		container := c.spec
+
+		nonRoot := podNonRoot
+		runAsUser := podRunAsUser
+		if securityContext, ok := container["securityContext"].(map[string]interface{}); ok {
+			if val, ok := securityContext["runAsNonRoot"].(bool); ok {
+				nonRoot = val
+			}
+			if val, ok := securityContext["runAsUser"].(int); ok {
+				runAsUser = val
+			}
+		}
+		if runAsUser == 0 || (!nonRoot && runAsUser < 0) {
+			continue
+		}
+
+		add, _ := capabilities(container)
+		if containsFold(add, "NET_BIND_SERVICE") || containsFold(add, "ALL") {
+			continue
+		}
+
+		ports, _ := container["ports"].([]interface{})
+		for _, p := range ports {
+			port, _ := p.(map[string]interface{})
+			containerPort, ok := port["containerPort"].(int)
+			if !ok || containerPort >= unprivilegedStart {
+				continue
+			}
+
+			name, _ := container["name"].(string)
+			findings = append(findings, types.Finding{
+				RuleID:    "privileged-port-without-capability",
+				Severity:  types.Medium,
+				Kind:      resource.Kind,
+				Name:      resource.Metadata.Name,
+				Namespace: resource.Metadata.Namespace,
+				Container: containerName(container),
+				Reason:    fmt.Sprintf("Container %s runs as non-root but declares privileged port %d without NET_BIND_SERVICE", name, containerPort),
+				Impact:    "Binding the port is denied at runtime, so the container crash-loops or never serves traffic",
+				Fix:       fmt.Sprintf("Listen on a port of %d or above and map it with the Service, or add NET_BIND_SERVICE to capabilities.add", privilegedPortLimit),
+				Path:      c.path(resource),
+			})
+			break
+		}
+	}
+
+	return findings
+}
+
+// statefulGracePeriod is the shortest terminationGracePeriodSeconds accepted
+// for a StatefulSet, whose pods usually need to flush data before exiting
+const statefulGracePeriod = 10
+
+// CheckTerminationGracePeriod checks for pods that are force-killed on
+// shutdown: terminationGracePeriodSeconds: 0 on any workload, or a grace
+// period under statefulGracePeriod on a StatefulSet. For StatefulSets the
+// Reason also notes when no container has a preStop hook.
+func CheckTerminationGracePeriod(resource parser.K8sResource) []types.Finding {
+	podSpec, ok := parser.GetPodSpec(resource)
+	if !ok {
+		return nil
+	}
+
+	grace, ok := podSpec["terminationGracePeriodSeconds"].(int)
+	if !ok {
+		return nil
+	}
+	stateful := resource.Kind == "StatefulSet"
+	if grace != 0 && (!stateful || grace >= statefulGracePeriod) {
+		return nil
+	}
+
+	reason := fmt.Sprintf("terminationGracePeriodSeconds is %d", grace)
+	if grace == 0 {
+		reason = "terminationGracePeriodSeconds is 0, so pods are killed without a chance to shut down"
+	}
+	fix := "Remove terminationGracePeriodSeconds so pods get the default 30 seconds to shut down"
+	if stateful {
+		fix = fmt.Sprintf("Set terminationGracePeriodSeconds to at least %d (default 30) and add a preStop hook that stops the database cleanly", statefulGracePeriod)
+		if !hasPreStopHook(podSpec) {
+			reason += ", and no container defines a preStop hook"
+		}
+	}
+
+	return []types.Finding{{
+		RuleID:    "short-termination-grace-period",
+		Severity:  types.Medium,
+		Kind:      resource.Kind,
+		Name:      resource.Metadata.Name,
+		Namespace: resource.Metadata.Namespace,
+		Reason:    reason,
+		Impact:    "Data loss on eviction, rollout or node drain: in-flight writes are cut off and unflushed state is corrupted",
+		Fix:       fix,
+		Path:      parser.PodSpecPath(resource) + ".terminationGracePeriodSeconds",
+	}}
+}
+
+// hasPreStopHook reports whether any container in the pod spec defines
+// lifecycle.preStop. Sidecars, declared as init containers with
+// restartPolicy: Always, run until the pod stops and count too.
+func hasPreStopHook(podSpec map[string]interface{}) bool {
+	for _, c := range podContainers(podSpec) {
+		switch c.list {
+		case "containers":
+		case "initContainers":
+			if policy, _ := c.spec["restartPolicy"].(string); policy != "Always" {
+				continue
+			}
+		default:
+			continue
+		}
+		lifecycle, _ := c.spec["lifecycle"].(map[string]interface{})
+		if _, ok := lifecycle["preStop"]; ok {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,441 @@
+package rules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// parseOne parses a manifest holding a single resource
+func parseOne(t *testing.T, manifest string) parser.K8sResource {
+	t.Helper()
+	resources, err := parser.ParseYAML([]byte(manifest))
+	if err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(resources))
+	}
+	return resources[0]
+}
+
+func TestWildcardRBACRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifest  string
+		sensitive []string // nil uses the defaults
+		want      map[string]types.Severity
+	}{
+		{
+			name: "all verbs on secrets",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r, namespace: app}
+rules:
+- apiGroups: [""]
+  resources: [secrets]
+  verbs: ["*"]
+`,
+			want: map[string]types.Severity{"wildcard-rbac-verbs": types.High},
+		},
+		{
+			name: "all verbs on a resource that isn't sensitive",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r, namespace: app}
+rules:
+- apiGroups: [""]
+  resources: [configmaps]
+  verbs: ["*"]
+`,
+			want: map[string]types.Severity{},
+		},
+		{
+			name: "specific verbs on every resource",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r, namespace: app}
+rules:
+- apiGroups: [""]
+  resources: ["*"]
+  verbs: [get, list]
+`,
+			want: map[string]types.Severity{"wildcard-rbac-resources": types.Medium},
+		},
+		{
+			name: "all verbs on every resource",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r, namespace: app}
+rules:
+- apiGroups: [""]
+  resources: ["*"]
+  verbs: ["*"]
+`,
+			want: map[string]types.Severity{"wildcard-rbac": types.High},
+		},
+		{
+			name: "cluster role over all API groups",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata: {name: r}
+rules:
+- apiGroups: ["*"]
+  resources: ["*"]
+  verbs: ["*"]
+`,
+			want: map[string]types.Severity{"wildcard-rbac": types.Critical},
+		},
+		{
+			name: "namespaced role over all API groups",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r, namespace: app}
+rules:
+- apiGroups: ["*"]
+  resources: ["*"]
+  verbs: ["*"]
+`,
+			want: map[string]types.Severity{"wildcard-rbac": types.High},
+		},
+		{
+			name: "custom sensitive resources",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r, namespace: app}
+rules:
+- apiGroups: [""]
+  resources: [configmaps]
+  verbs: ["*"]
+- apiGroups: [""]
+  resources: [secrets]
+  verbs: ["*"]
+`,
+			sensitive: []string{"configmaps"},
+			want:      map[string]types.Severity{"wildcard-rbac-verbs": types.High},
+		},
+		{
+			name: "secrets dropped from custom sensitive resources",
+			manifest: `apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata: {name: r, namespace: app}
+rules:
+- apiGroups: [""]
+  resources: [secrets]
+  verbs: ["*"]
+`,
+			sensitive: []string{"configmaps"},
+			want:      map[string]types.Severity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sensitive := tt.sensitive
+			if sensitive == nil {
+				sensitive = DefaultSensitiveResources()
+			}
+			findings := WildcardRBACRule(sensitive)(parseOne(t, tt.manifest))
+
+			got := make(map[string]types.Severity)
+			for _, f := range findings {
+				if _, dup := got[f.RuleID]; dup {
+					t.Errorf("%s reported more than once", f.RuleID)
+				}
+				got[f.RuleID] = f.Severity
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got findings %v, want %v", got, tt.want)
+			}
+			for id, severity := range tt.want {
+				if got[id] != severity {
+					t.Errorf("%s: got severity %q, want %q", id, got[id], severity)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckWeakSecretValuesReportsEveryKey(t *testing.T) {
+	// data.password is "changeme" and data.token a strong value
+	secret := parseOne(t, `apiVersion: v1
+kind: Secret
+metadata: {name: creds, namespace: app}
+data:
+  password: Y2hhbmdlbWU=
+  token: bDBuZy1yYW5kb20tdjRsdWU=
+stringData:
+  api-key: ""
+  user: admin
+`)
+
+	findings := CheckWeakSecretValues(secret)
+	var paths []string
+	for _, f := range findings {
+		paths = append(paths, f.Path)
+		if strings.Contains(f.Reason, "changeme") || strings.Contains(f.Reason, "admin") {
+			t.Errorf("reason %q echoes a secret value", f.Reason)
+		}
+	}
+	want := []string{"stringData.api-key", "data.password", "stringData.user"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+}
+
+func TestLowUIDRulePaths(t *testing.T) {
+	deployment := parseOne(t, `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: app}
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsUser: 33
+      containers:
+      - name: inherits
+        image: nginx:1.25
+      - name: overrides
+        image: nginx:1.25
+        securityContext:
+          runAsUser: 100
+      - name: safe
+        image: nginx:1.25
+        securityContext:
+          runAsUser: 10001
+`)
+
+	want := map[string]string{
+		"inherits":  "spec.template.spec.securityContext.runAsUser",
+		"overrides": "spec.template.spec.containers[1].securityContext.runAsUser",
+	}
+	findings := LowUIDRule(1000)(deployment)
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for _, f := range findings {
+		if f.Path != want[f.Container] {
+			t.Errorf("%s: got path %q, want %q", f.Container, f.Path, want[f.Container])
+		}
+		if strings.Contains(f.Reason, f.Container) {
+			t.Errorf("reason %q repeats the container name", f.Reason)
+		}
+	}
+}
+
+func TestCheckSuperPodPath(t *testing.T) {
+	deployment := parseOne(t, `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: node-agent, namespace: ops}
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      hostPID: true
+      containers:
+      - name: agent
+        image: agent:2.1
+`)
+
+	findings := CheckSuperPod(deployment)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	if findings[0].Path != "spec.template.spec" {
+		t.Errorf("got path %q, want the pod spec", findings[0].Path)
+	}
+}
+
+func TestCheckJobSafetyPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     map[string]string
+	}{
+		{
+			name: "job relying on the default backoff limit",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata: {name: migrate, namespace: app}
+spec:
+  activeDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+      - {name: migrate, image: migrate:1.0}
+`,
+			want: map[string]string{},
+		},
+		{
+			name: "job with a large backoff limit",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata: {name: migrate, namespace: app}
+spec:
+  backoffLimit: 50
+  activeDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+      - {name: migrate, image: migrate:1.0}
+`,
+			want: map[string]string{"job-without-limits": "spec.backoffLimit"},
+		},
+		{
+			name: "job without a deadline",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata: {name: migrate, namespace: app}
+spec:
+  backoffLimit: 2
+  template:
+    spec:
+      containers:
+      - {name: migrate, image: migrate:1.0}
+`,
+			want: map[string]string{"job-without-limits": "spec.activeDeadlineSeconds"},
+		},
+		{
+			name: "cronjob allowing concurrent runs",
+			manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata: {name: report, namespace: app}
+spec:
+  schedule: "0 * * * *"
+  concurrencyPolicy: Allow
+  jobTemplate:
+    spec:
+      backoffLimit: 2
+      activeDeadlineSeconds: 600
+      template:
+        spec:
+          containers:
+          - {name: report, image: report:1.0}
+`,
+			want: map[string]string{"cronjob-concurrent-runs": "spec.concurrencyPolicy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := CheckJobSafety(parseOne(t, tt.manifest))
+			if len(findings) != len(tt.want) {
+				t.Fatalf("got %d findings, want %d: %+v", len(findings), len(tt.want), findings)
+			}
+			for _, f := range findings {
+				if f.Path != tt.want[f.RuleID] {
+					t.Errorf("%s: got path %q, want %q", f.RuleID, f.Path, tt.want[f.RuleID])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckHostPIDIPCReportsBoth(t *testing.T) {
+	pod := parseOne(t, `apiVersion: v1
+kind: Pod
+metadata: {name: debug, namespace: ops}
+spec:
+  hostPID: true
+  hostIPC: true
+  containers:
+  - {name: shell, image: busybox:1.36}
+`)
+
+	var paths []string
+	for _, f := range CheckHostPIDIPC(pod) {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"spec.hostPID", "spec.hostIPC"}; !slices.Equal(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+}
+
+func TestCheckJobSafetyCronJobFix(t *testing.T) {
+	cronJob := parseOne(t, `apiVersion: batch/v1
+kind: CronJob
+metadata: {name: report, namespace: app}
+spec:
+  schedule: "0 * * * *"
+  concurrencyPolicy: Forbid
+  jobTemplate:
+    spec:
+      backoffLimit: 20
+      template:
+        spec:
+          containers:
+          - {name: report, image: report:1.0}
+`)
+
+	findings := CheckJobSafety(cronJob)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Path != "spec.jobTemplate.spec.activeDeadlineSeconds" {
+		t.Errorf("got path %q", f.Path)
+	}
+	want := "Set spec.jobTemplate.spec.activeDeadlineSeconds and lower spec.jobTemplate.spec.backoffLimit to 10 or less"
+	if f.Fix != want {
+		t.Errorf("got fix %q, want %q", f.Fix, want)
+	}
+}
+
+func TestCheckTerminationGracePeriodPreStopHook(t *testing.T) {
+	// statefulSet renders a StatefulSet with a 5s grace period and the given
+	// initContainers entry
+	statefulSet := func(initContainer string) string {
+		return `apiVersion: apps/v1
+kind: StatefulSet
+metadata: {name: db, namespace: data}
+spec:
+  template:
+    spec:
+      terminationGracePeriodSeconds: 5
+      initContainers:
+` + initContainer + `
+      containers:
+      - {name: db, image: postgres:16}
+`
+	}
+
+	tests := []struct {
+		name          string
+		initContainer string
+		wantNoHook    bool
+	}{
+		{
+			name: "sidecar with a preStop hook",
+			initContainer: `      - name: flusher
+        image: flusher:1.0
+        restartPolicy: Always
+        lifecycle:
+          preStop:
+            exec: {command: [flush]}`,
+		},
+		{
+			name: "plain init container with a preStop hook",
+			initContainer: `      - name: setup
+        image: setup:1.0
+        lifecycle:
+          preStop:
+            exec: {command: [flush]}`,
+			wantNoHook: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := CheckTerminationGracePeriod(parseOne(t, statefulSet(tt.initContainer)))
+			if len(findings) != 1 {
+				t.Fatalf("got %d findings, want 1", len(findings))
+			}
+			if noHook := strings.Contains(findings[0].Reason, "no container defines a preStop hook"); noHook != tt.wantNoHook {
+				t.Errorf("got reason %q", findings[0].Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package scanner_test
+
+import (
+	"fmt"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/scanner"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+func ExampleNewScannerWithRules() {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: registry.example.com/api:1.4
+`
+	resources, err := parser.ParseYAML([]byte(manifest))
+	if err != nil {
+		panic(err)
+	}
+
+	// Require every workload to carry a team label
+	requireTeam := func(resource parser.K8sResource) []types.Finding {
+		if resource.Kind != "Deployment" || resource.Metadata.Labels["team"] != "" {
+			return nil
+		}
+		return []types.Finding{{
+			RuleID:    "missing-team-label",
+			Severity:  types.High,
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			Reason:    "Workload has no team label",
+			Fix:       "Add a metadata.labels.team entry",
+		}}
+	}
+
+	s := scanner.NewScannerWithRules(types.ScanOptions{}, []rules.Rule{requireTeam})
+	for _, f := range s.Scan(resources).Findings {
+		fmt.Printf("%s %s %s/%s: %s\n", f.Severity, f.RuleID, f.Kind, f.Name, f.Reason)
+	}
+	// Output:
+	// HIGH missing-team-label Deployment/api: Workload has no team label
+}
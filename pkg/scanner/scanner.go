@@ -1,6 +1,24 @@
+// Package scanner runs the danger rules over parsed Kubernetes resources.
+// It writes nothing to the terminal, so it can be embedded in other
+// programs:
+//
+//	parsed, err := parser.ParseFilesContext(ctx, parser.ParseOptions{}, "manifests/")
+//	if err != nil {
+//		return err
+//	}
+//	result, err := scanner.Scan(ctx, parsed.Resources, types.ScanOptions{MinSeverity: types.Medium},
+//		scanner.WithRules(myRule))
 package scanner
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+
 	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
 	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
 	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
@@ -8,82 +26,648 @@ import (
 
 // Scanner performs security scans on Kubernetes resources
 type Scanner struct {
-	rules   []rules.Rule
-	options types.ScanOptions
+	rules      []rules.Rule
+	contextual []rules.ContextRule
+	aggregates []rules.AggregateRule
+	options    types.ScanOptions
+	log        *slog.Logger // Optional; receives debug messages
+}
+
+// Option adds to what a Scanner runs beyond its ScanOptions
+type Option func(*Scanner)
+
+// WithRules adds custom rules to those a scanner runs, as AddRule does
+func WithRules(ruleSet ...rules.Rule) Option {
+	return func(s *Scanner) {
+		s.rules = append(s.rules, ruleSet...)
+	}
+}
+
+// WithContextRules adds custom context rules, as AddContextRule does
+func WithContextRules(ruleSet ...rules.ContextRule) Option {
+	return func(s *Scanner) {
+		s.contextual = append(s.contextual, ruleSet...)
+	}
+}
+
+// WithAggregateRules adds custom aggregate rules, as AddAggregateRule does
+func WithAggregateRules(ruleSet ...rules.AggregateRule) Option {
+	return func(s *Scanner) {
+		s.aggregates = append(s.aggregates, ruleSet...)
+	}
+}
+
+// WithLogger sends the scanner's debug messages, such as which resources
+// were skipped, to log
+func WithLogger(log *slog.Logger) Option {
+	return func(s *Scanner) {
+		s.log = log
+	}
+}
+
+// Scan scans resources with a scanner built from options and opts. It is
+// the entry point for programs embedding the scanner.
+func Scan(ctx context.Context, resources []parser.K8sResource, options types.ScanOptions, opts ...Option) (types.ScanResult, error) {
+	return NewScanner(options, opts...).ScanContext(ctx, resources)
 }
 
-// NewScanner creates a new scanner with the given options
-func NewScanner(options types.ScanOptions) *Scanner {
-	return &Scanner{
-		rules:   rules.AllRules(),
+// NewScanner creates a new scanner with the given options, running the
+// built-in rules for the selected categories plus any added by opts
+func NewScanner(options types.ScanOptions, opts ...Option) *Scanner {
+	categories := options.Categories
+	if len(categories) == 0 {
+		categories = rules.DefaultCategories()
+	}
+
+	ruleSet := rules.RulesForCategoriesWithSettings(settingsFromOverrides(options.Overrides), categories...)
+	if options.Strict {
+		ruleSet = append(ruleSet, rules.StrictRules()...)
+	}
+	if options.PSSLevel != "" {
+		ruleSet = append(ruleSet, rules.PSSRules(options.PSSLevel)...)
+	}
+
+	s := &Scanner{
+		rules:      ruleSet,
+		contextual: rules.ContextRulesForCategories(categories...),
+		aggregates: rules.AggregateRulesForCategories(categories...),
+		options:    options,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// settingsFromOverrides applies rule parameters set in rules.yaml to the
+// built-in defaults
+func settingsFromOverrides(overrides map[string]types.RuleOverride) rules.Settings {
+	settings := rules.DefaultSettings()
+	if override, ok := overrides["low-uid"]; ok && override.Threshold > 0 {
+		settings.LowUIDThreshold = override.Threshold
+	}
+	if override, ok := overrides["wildcard-rbac-verbs"]; ok && len(override.Resources) > 0 {
+		settings.SensitiveResources = override.Resources
+	}
+	if override, ok := overrides["no-resource-limits"]; ok {
+		settings.RequireResourceRequests = override.Requests
+	}
+	return settings
+}
+
+// NewScannerWithRules creates a scanner that runs exactly the given
+// per-resource rules instead of the built-in set. Unlike NewScanner it runs
+// no context or aggregate rules, such as the ServiceAccount RBAC and
+// NetworkPolicy coverage checks, unless opts add them with WithContextRules
+// and WithAggregateRules, and no Pod Security Standards controls unless
+// ruleSet includes rules.PSSRules. To extend the built-in set instead, pass
+// custom rules to NewScanner with WithRules.
+func NewScannerWithRules(options types.ScanOptions, ruleSet []rules.Rule, opts ...Option) *Scanner {
+	s := &Scanner{
+		rules:   ruleSet,
 		options: options,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddRule appends a custom rule to the scanner's rule set. Resources are
+// scanned concurrently, so the rule must be safe for concurrent use.
+func (s *Scanner) AddRule(rule rules.Rule) {
+	s.rules = append(s.rules, rule)
+}
+
+// AddContextRule appends a custom context rule, which checks one resource
+// at a time with every scanned resource at hand
+func (s *Scanner) AddContextRule(rule rules.ContextRule) {
+	s.contextual = append(s.contextual, rule)
+}
+
+// AddAggregateRule appends a custom aggregate rule, which sees every scanned
+// resource at once
+func (s *Scanner) AddAggregateRule(rule rules.AggregateRule) {
+	s.aggregates = append(s.aggregates, rule)
 }
 
 // Scan scans the given resources and returns findings
 func (s *Scanner) Scan(resources []parser.K8sResource) types.ScanResult {
+	// The background context is never done, so there is no error
+	result, _ := s.ScanContext(context.Background(), resources)
+	return result
+}
+
+// ScanContext is Scan with a context. Once ctx is done, no further
+// resources are scanned and ctx's error is returned.
+func (s *Scanner) ScanContext(ctx context.Context, resources []parser.K8sResource) (types.ScanResult, error) {
 	var findings []types.Finding
+	stats := types.Stats{RulesRun: len(s.rules) + len(s.contextual) + len(s.aggregates)}
+	scan := rules.NewScanContext(resources)
 
-	for _, resource := range resources {
-		// Skip unsupported resource kinds
+	results, err := s.scanAll(ctx, resources, scan, &stats)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	for _, resourceFindings := range results {
+		findings = append(findings, resourceFindings...)
+	}
+	findings = append(findings, s.scanAggregates(resources, &stats)...)
+
+	result := types.ScanResult{
+		Findings: s.finalize(findings),
+		Stats:    stats,
+	}
+	s.judgePSS(&result, resources, results)
+	return result, nil
+}
+
+// scanAll runs scanResource over resources on a pool of workers, returning
+// each resource's findings at its index. Unsupported kinds are skipped, and
+// no resources are handed out once ctx is done.
+func (s *Scanner) scanAll(ctx context.Context, resources []parser.K8sResource, scan *rules.ScanContext, stats *types.Stats) ([][]types.Finding, error) {
+	findings := make([][]types.Finding, len(resources))
+	workerStats := make([]types.Stats, s.workers())
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := range workerStats {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				findings[i] = s.scanResource(resources[i], scan, &workerStats[w])
+			}
+		}()
+	}
+	for i, resource := range resources {
+		if ctx.Err() != nil {
+			break
+		}
 		if !parser.IsSupportedKind(resource.Kind) {
+			if s.log != nil {
+				s.log.Debug("skipping resource", "kind", resource.Kind, "name", resource.Metadata.Name,
+					"file", resource.Source, "reason", "kind is not scanned")
+			}
+			stats.ResourcesSkipped++
 			continue
 		}
+		stats.ResourcesScanned++
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ws := range workerStats {
+		stats.RuleExecutions += ws.RuleExecutions
+	}
+	return findings, nil
+}
+
+// workers returns the number of resources scanned at once
+func (s *Scanner) workers() int {
+	if s.options.Concurrency > 0 {
+		return s.options.Concurrency
+	}
+	return runtime.NumCPU()
+}
+
+// scanResource applies all per-resource and context rules to a single
+// resource
+func (s *Scanner) scanResource(resource parser.K8sResource, scan *rules.ScanContext, stats *types.Stats) []types.Finding {
+	var findings []types.Finding
+	for _, rule := range s.rules {
+		stats.RuleExecutions++
+		findings = append(findings, s.locate(resource, rule(resource))...)
+	}
+	for _, rule := range s.contextual {
+		stats.RuleExecutions++
+		findings = append(findings, s.locate(resource, rule(resource, scan))...)
+	}
+
+	if s.options.Strict {
+		findings = dropSupersededByStrict(findings)
+	}
+	return s.suppress(findings, resource.Metadata.Annotations)
+}
 
-		// Apply all rules to this resource
-		for _, rule := range s.rules {
-			ruleFindings := rule(resource)
-			findings = append(findings, ruleFindings...)
+// locate fills in where in the resource's file each finding is
+func (s *Scanner) locate(resource parser.K8sResource, ruleFindings []types.Finding) []types.Finding {
+	for i := range ruleFindings {
+		ruleFindings[i].File = resource.Source
+		ruleFindings[i].Line, ruleFindings[i].Column = resource.Position(ruleFindings[i].Path)
+		ruleFindings[i].Fingerprint = Fingerprint(ruleFindings[i])
+		if s.options.ShowSnippet && hasSnippet(ruleFindings[i]) {
+			ruleFindings[i].Snippet = snippet(resource, ruleFindings[i].Path)
 		}
 	}
+	return ruleFindings
+}
+
+// hasSnippet reports whether a finding's snippet can be shown. Findings on a
+// whole resource have nothing to show, and weak-secret-value points at the
+// secret value itself, which is never printed.
+func hasSnippet(f types.Finding) bool {
+	return f.Path != "" && f.RuleID != "weak-secret-value"
+}
 
-	// Filter by severity if needed
-	if !s.options.IncludeMedium {
-		findings = filterHighOnly(findings)
+// scanAggregates applies the aggregate rules to the whole resource set. Each
+// finding is attributed to the file of the resource it names.
+func (s *Scanner) scanAggregates(resources []parser.K8sResource, stats *types.Stats) []types.Finding {
+	if len(s.aggregates) == 0 {
+		return nil
 	}
 
-	return types.ScanResult{
-		Findings: findings,
+	byKey := make(map[string]parser.K8sResource)
+	for _, resource := range resources {
+		key := resource.Kind + "|" + namespaceOrDefault(resource.Metadata.Namespace) + "|" + resource.Metadata.Name
+		byKey[key] = resource
 	}
+
+	var findings []types.Finding
+	for _, rule := range s.aggregates {
+		stats.RuleExecutions++
+		for _, f := range rule(resources) {
+			resource := byKey[aggregateKey(f)]
+			f.File = resource.Source
+			f.Line, f.Column = resource.Position(f.Path)
+			f.Fingerprint = Fingerprint(f)
+			if s.options.ShowSnippet && hasSnippet(f) {
+				f.Snippet = snippet(resource, f.Path)
+			}
+			findings = append(findings, s.suppress([]types.Finding{f}, resource.Metadata.Annotations)...)
+		}
+	}
+	return findings
+}
+
+// aggregateKey identifies the resource an aggregate finding is about
+func aggregateKey(f types.Finding) string {
+	return f.Kind + "|" + namespaceOrDefault(f.Namespace) + "|" + f.Name
+}
+
+// findingKey matches a finding on the resource identified by id across
+// manifest versions. Like Fingerprint, it tells apart findings of one rule
+// on different containers or elements of the resource.
+func findingKey(id string, f types.Finding) string {
+	return id + "|" + f.RuleID + "|" + f.Container + "|" + f.Path
 }
 
-// Diff compares old and new resources and returns only newly introduced findings
+// namespaceOrDefault treats an empty namespace as "default"
+func namespaceOrDefault(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
+
+// finalize applies DaemonSet escalation, user overrides and the severity
+// threshold to findings
+func (s *Scanner) finalize(findings []types.Finding) []types.Finding {
+	findings = dropDisabled(findings, s.options.DisabledRules)
+	findings = keepOnly(findings, s.options.OnlyRules)
+	escalateDaemonSets(findings)
+
+	// Apply user overrides before filtering so severity changes take effect
+	if len(s.options.Overrides) > 0 {
+		applyOverrides(findings, s.options.Overrides)
+	}
+
+	return FilterMinSeverity(findings, s.minSeverity())
+}
+
+// judgePSS records a Pod Security Standards verdict for each workload in
+// resources, from the failed-control findings at the same index. Disabled
+// and annotation-suppressed controls don't count against a workload; the
+// severity threshold doesn't apply.
+func (s *Scanner) judgePSS(result *types.ScanResult, resources []parser.K8sResource, findings [][]types.Finding) {
+	if s.options.PSSLevel == "" {
+		return
+	}
+	result.PSSLevel = s.options.PSSLevel
+
+	for i, resource := range resources {
+		if !parser.IsSupportedKind(resource.Kind) {
+			continue
+		}
+		if _, ok := parser.GetPodSpec(resource); !ok {
+			continue
+		}
+
+		verdict := types.PSSVerdict{
+			Kind:      resource.Kind,
+			Name:      resource.Metadata.Name,
+			Namespace: resource.Metadata.Namespace,
+			File:      resource.Source,
+		}
+		for _, f := range keepOnly(dropDisabled(findings[i], s.options.DisabledRules), s.options.OnlyRules) {
+			if f.PSSLevel == "" || f.Suppressed {
+				continue
+			}
+			verdict.Controls = append(verdict.Controls, f.RuleID)
+			if verdict.Violates == "" || f.PSSLevel.Rank() < verdict.Violates.Rank() {
+				verdict.Violates = f.PSSLevel
+			}
+		}
+		result.PSSVerdicts = append(result.PSSVerdicts, verdict)
+	}
+}
+
+// Diff compares old and new resources and returns only newly introduced
+// findings. Resources are matched across the two sets by identity
+// (apiVersion, kind, namespace and name) rather than by file, so directory
+// trees can be compared even when manifests move between files. A finding is
+// new if its rule did not fire on the same container of the matching old
+// resource, or if the resource itself is new. Added and removed resources
+// are reported too.
+//
+// Each finding's Status records how it compares: findings present on both
+// sides are unchanged and only counted, and findings the new resources no
+// longer have are resolved, returned with ShowResolved after the new ones.
 func (s *Scanner) Diff(oldResources, newResources []parser.K8sResource) types.ScanResult {
-	// Scan both sets
-	oldFindings := s.Scan(oldResources).Findings
-	newFindings := s.Scan(newResources).Findings
+	// The background context is never done, so there is no error
+	result, _ := s.DiffContext(context.Background(), oldResources, newResources)
+	return result
+}
+
+// DiffContext is Diff with a context. Once ctx is done, no further
+// resources are scanned and ctx's error is returned.
+func (s *Scanner) DiffContext(ctx context.Context, oldResources, newResources []parser.K8sResource) (types.ScanResult, error) {
+	stats := types.Stats{RulesRun: len(s.rules) + len(s.contextual) + len(s.aggregates)}
+	oldScan := rules.NewScanContext(oldResources)
+	newScan := rules.NewScanContext(newResources)
 
-	// Build a set of old findings for comparison
-	oldFindingsSet := make(map[string]bool)
-	for _, f := range oldFindings {
-		key := findingKey(f)
-		oldFindingsSet[key] = true
+	// Index the findings on each old resource. Aggregate findings carry no
+	// apiVersion, so they are matched by kind, namespace and name.
+	oldIDs := make(map[string]bool)
+	var oldFindings []types.Finding
+	var oldKeys []string
+	oldResults, err := s.scanAll(ctx, oldResources, oldScan, &stats)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	for i, resource := range oldResources {
+		id := resourceIdentity(resource)
+		oldIDs[id] = true
+
+		for _, f := range s.finalize(oldResults[i]) {
+			oldFindings = append(oldFindings, f)
+			oldKeys = append(oldKeys, findingKey(id, f))
+		}
+	}
+	for _, f := range s.finalize(s.scanAggregates(oldResources, &stats)) {
+		oldFindings = append(oldFindings, f)
+		oldKeys = append(oldKeys, findingKey(aggregateKey(f), f))
+	}
+	oldSet := make(map[string]bool)
+	for _, key := range oldKeys {
+		oldSet[key] = true
 	}
 
-	// Filter out findings that existed in old version
 	var diffFindings []types.Finding
-	for _, f := range newFindings {
-		key := findingKey(f)
-		if !oldFindingsSet[key] {
-			diffFindings = append(diffFindings, f)
+	var added []types.ResourceRef
+	newSet := make(map[string]bool)
+	unchanged := 0
+	classify := func(key string, f types.Finding) {
+		newSet[key] = true
+		if oldSet[key] {
+			unchanged++
+			return
+		}
+		f.Status = types.StatusNew
+		diffFindings = append(diffFindings, f)
+	}
+
+	newIDs := make(map[string]bool)
+	newResults, err := s.scanAll(ctx, newResources, newScan, &stats)
+	if err != nil {
+		return types.ScanResult{}, err
+	}
+	for i, resource := range newResources {
+		id := resourceIdentity(resource)
+		if !oldIDs[id] && !newIDs[id] {
+			added = append(added, resourceRef(resource))
+		}
+		newIDs[id] = true
+
+		for _, f := range s.finalize(newResults[i]) {
+			classify(findingKey(id, f), f)
+		}
+	}
+	for _, f := range s.finalize(s.scanAggregates(newResources, &stats)) {
+		classify(findingKey(aggregateKey(f), f), f)
+	}
+
+	if s.options.ShowResolved {
+		for i, f := range oldFindings {
+			if !newSet[oldKeys[i]] {
+				f.Status = types.StatusResolved
+				diffFindings = append(diffFindings, f)
+			}
+		}
+	}
+
+	var removed []types.ResourceRef
+	reported := make(map[string]bool)
+	for _, resource := range oldResources {
+		id := resourceIdentity(resource)
+		if !newIDs[id] && !reported[id] {
+			removed = append(removed, resourceRef(resource))
+			reported[id] = true
 		}
 	}
 
-	return types.ScanResult{
-		Findings: diffFindings,
+	result := types.ScanResult{
+		Findings:         diffFindings,
+		ResourcesAdded:   added,
+		ResourcesRemoved: removed,
+		Unchanged:        unchanged,
+		Stats:            stats,
 	}
+	s.judgePSS(&result, newResources, newResults)
+	return result, nil
 }
 
-// findingKey creates a unique key for a finding
-func findingKey(f types.Finding) string {
-	return f.RuleID + "|" + f.Kind + "|" + f.Name + "|" + f.Namespace
+// resourceIdentity identifies a resource across manifest versions. An empty
+// namespace is treated as "default", matching Fingerprint.
+func resourceIdentity(resource parser.K8sResource) string {
+	namespace := namespaceOrDefault(resource.Metadata.Namespace)
+	return resource.APIVersion + "|" + resource.Kind + "|" + namespace + "|" + resource.Metadata.Name
 }
 
-// filterHighOnly filters findings to only include HIGH severity
-func filterHighOnly(findings []types.Finding) []types.Finding {
+// resourceRef describes a resource for added/removed reporting
+func resourceRef(resource parser.K8sResource) types.ResourceRef {
+	return types.ResourceRef{
+		APIVersion: resource.APIVersion,
+		Kind:       resource.Kind,
+		Name:       resource.Metadata.Name,
+		Namespace:  resource.Metadata.Namespace,
+		File:       resource.Source,
+	}
+}
+
+// dropIgnored removes findings for rules the resource asks to ignore
+func dropIgnored(findings []types.Finding, ignored map[string]bool) []types.Finding {
+	if len(ignored) == 0 {
+		return findings
+	}
+
+	var kept []types.Finding
+	for _, f := range findings {
+		if !ignored[f.RuleID] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// dropSupersededByStrict removes findings made redundant by the strict
+// missing-security-context finding on the same resource
+func dropSupersededByStrict(findings []types.Finding) []types.Finding {
+	hasStrict := false
+	for _, f := range findings {
+		if f.RuleID == "missing-security-context" {
+			hasStrict = true
+			break
+		}
+	}
+	if !hasStrict {
+		return findings
+	}
+
+	superseded := make(map[string]bool)
+	for _, id := range rules.SupersededByStrict() {
+		superseded[id] = true
+	}
+
+	var kept []types.Finding
+	for _, f := range findings {
+		if !superseded[f.RuleID] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// daemonSetContext is appended to the Reason of serious DaemonSet findings
+const daemonSetContext = " (DaemonSet — runs on every node)"
+
+// escalateDaemonSets marks HIGH and CRITICAL findings on DaemonSets as
+// affecting every node, and raises the rules in rules.EscalatedForDaemonSets
+// from HIGH to CRITICAL. It runs before overrides so rules.yaml still has the
+// last word on severity.
+func escalateDaemonSets(findings []types.Finding) {
+	escalated := make(map[string]bool)
+	for _, id := range rules.EscalatedForDaemonSets() {
+		escalated[id] = true
+	}
+
+	for i := range findings {
+		f := &findings[i]
+		if f.Kind != "DaemonSet" || f.Severity.Rank() < types.High.Rank() {
+			continue
+		}
+		if f.Severity == types.High && escalated[f.RuleID] {
+			f.Severity = types.Critical
+		}
+		if !strings.HasSuffix(f.Reason, daemonSetContext) {
+			f.Reason += daemonSetContext
+		}
+	}
+}
+
+// dropDisabled removes findings of rules turned off in the configuration
+func dropDisabled(findings []types.Finding, disabled []string) []types.Finding {
+	if len(disabled) == 0 {
+		return findings
+	}
+	off := make(map[string]bool, len(disabled))
+	for _, id := range disabled {
+		off[id] = true
+	}
+	return dropIgnored(findings, off)
+}
+
+// keepOnly removes findings from rules not listed in only, if any are listed
+func keepOnly(findings []types.Finding, only []string) []types.Finding {
+	if len(only) == 0 {
+		return findings
+	}
+	on := make(map[string]bool, len(only))
+	for _, id := range only {
+		on[id] = true
+	}
+
+	var kept []types.Finding
+	for _, f := range findings {
+		if on[f.RuleID] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// applyOverrides replaces finding text and severity with user-configured values
+func applyOverrides(findings []types.Finding, overrides map[string]types.RuleOverride) {
+	for i := range findings {
+		override, ok := overrides[findings[i].RuleID]
+		if !ok {
+			continue
+		}
+		if override.Severity != "" {
+			findings[i].Severity = override.Severity
+		}
+		if override.Reason != "" {
+			findings[i].Reason = override.Reason
+		}
+		if override.Impact != "" {
+			findings[i].Impact = override.Impact
+		}
+		if override.Fix != "" {
+			findings[i].Fix = override.Fix
+		}
+	}
+}
+
+// Fingerprint returns a stable identifier for a finding, suitable for
+// deduplicating findings in external trackers across re-scans. It is the
+// lowercase hex SHA-256 of
+// "<rule_id>|<kind>|<namespace>|<name>|<container>|<path>", where an empty
+// namespace is written as "default" and the container and path are empty for
+// findings about the resource as a whole. The path tells apart findings of
+// one rule on, say, two volumes. Volatile details such as the file name are
+// deliberately excluded.
+func Fingerprint(f types.Finding) string {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	sum := sha256.Sum256([]byte(f.RuleID + "|" + f.Kind + "|" + namespace + "|" + f.Name + "|" + f.Container + "|" + f.Path))
+	return hex.EncodeToString(sum[:])
+}
+
+// minSeverity resolves the reporting threshold, honoring the deprecated
+// IncludeMedium option when MinSeverity is unset
+func (s *Scanner) minSeverity() types.Severity {
+	if s.options.MinSeverity != "" {
+		return s.options.MinSeverity
+	}
+	if s.options.IncludeMedium {
+		return types.Medium
+	}
+	return types.High
+}
+
+// FilterMinSeverity filters findings to those at or above the given severity
+func FilterMinSeverity(findings []types.Finding, min types.Severity) []types.Finding {
 	var filtered []types.Finding
 	for _, f := range findings {
-		if f.Severity == types.High {
+		if f.Severity.Rank() >= min.Rank() {
 			filtered = append(filtered, f)
 		}
 	}
@@ -97,11 +681,23 @@ func GetSummary(findings []types.Finding) types.Summary {
 	namespaceSet := make(map[string]bool)
 
 	for _, f := range findings {
+		if f.Suppressed {
+			summary.Suppressed++
+			continue
+		}
+		if f.Status == types.StatusResolved {
+			summary.Resolved++
+			continue
+		}
 		switch f.Severity {
+		case types.Critical:
+			summary.Critical++
 		case types.High:
 			summary.High++
 		case types.Medium:
 			summary.Medium++
+		case types.Low:
+			summary.Low++
 		}
 
 		resourceKey := f.Kind + "/" + f.Name
@@ -118,23 +714,34 @@ func GetSummary(findings []types.Finding) types.Summary {
 	return summary
 }
 
-// GetExitCode determines the appropriate exit code based on findings
+// GetExitCode determines the appropriate exit code based on findings. LOW
+// findings are informational and never fail a run.
 func GetExitCode(findings []types.Finding) types.ExitCode {
+	return ExitCodeFor(findings, types.Medium)
+}
+
+// ExitCodeFor is GetExitCode with a --fail-on threshold: findings below
+// failOn, like suppressed and resolved ones, never fail the run. HIGH and
+// CRITICAL findings give ExitHigh, any other failing finding ExitMedium.
+func ExitCodeFor(findings []types.Finding, failOn types.Severity) types.ExitCode {
 	hasHigh := false
-	hasMedium := false
+	hasLower := false
 
 	for _, f := range findings {
-		if f.Severity == types.High {
+		if f.Suppressed || f.Status == types.StatusResolved || f.Severity.Rank() < failOn.Rank() {
+			continue
+		}
+		if f.Severity.Rank() >= types.High.Rank() {
 			hasHigh = true
-		} else if f.Severity == types.Medium {
-			hasMedium = true
+		} else {
+			hasLower = true
 		}
 	}
 
 	if hasHigh {
 		return types.ExitHigh
 	}
-	if hasMedium {
+	if hasLower {
 		return types.ExitMedium
 	}
 	return types.ExitOK
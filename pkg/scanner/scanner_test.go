@@ -0,0 +1,252 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// privilegedPod is a Pod manifest that fires privileged-container
+const privilegedPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  containers:
+  - name: app
+    image: nginx:1.25
+    securityContext:
+      privileged: true
+`
+
+// parseTree writes files, keyed by name, into a fresh directory and parses it
+func parseTree(t *testing.T, files map[string]string) []parser.K8sResource {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := parser.ParseFiles(dir)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", dir, err)
+	}
+	if len(result.Warnings) > 0 {
+		t.Fatalf("unexpected parse warnings: %+v", result.Warnings)
+	}
+	return result.Resources
+}
+
+// pod renders privilegedPod for a name and namespace
+func pod(name, namespace string) string {
+	return fmt.Sprintf(privilegedPod, name, namespace)
+}
+
+// findingsFor returns the findings of one rule
+func findingsFor(findings []types.Finding, ruleID string) []types.Finding {
+	var matched []types.Finding
+	for _, f := range findings {
+		if f.RuleID == ruleID {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+func TestDiffMatchesResourcesMovedBetweenFiles(t *testing.T) {
+	oldResources := parseTree(t, map[string]string{"a.yaml": pod("web", "shop")})
+	newResources := parseTree(t, map[string]string{"b.yaml": pod("web", "shop")})
+
+	result := NewScanner(types.ScanOptions{}).Diff(oldResources, newResources)
+	if len(result.Findings) != 0 {
+		t.Errorf("moving a manifest reported %d new finding(s): %+v", len(result.Findings), result.Findings)
+	}
+	if result.Unchanged == 0 {
+		t.Error("no findings counted as unchanged")
+	}
+	if len(result.ResourcesAdded) != 0 || len(result.ResourcesRemoved) != 0 {
+		t.Errorf("got added %v and removed %v, want none", result.ResourcesAdded, result.ResourcesRemoved)
+	}
+}
+
+func TestDiffReportsAddedAndRemovedResources(t *testing.T) {
+	oldResources := parseTree(t, map[string]string{"pods.yaml": pod("web", "shop")})
+	newResources := parseTree(t, map[string]string{"pods.yaml": pod("api", "shop")})
+
+	result := NewScanner(types.ScanOptions{}).Diff(oldResources, newResources)
+
+	privileged := findingsFor(result.Findings, "privileged-container")
+	if len(privileged) != 1 || privileged[0].Name != "api" || privileged[0].Status != types.StatusNew {
+		t.Errorf("got privileged-container findings %+v, want one new finding on api", privileged)
+	}
+	if len(result.ResourcesAdded) != 1 || result.ResourcesAdded[0].Name != "api" {
+		t.Errorf("got added %+v, want Pod api", result.ResourcesAdded)
+	}
+	if len(result.ResourcesRemoved) != 1 || result.ResourcesRemoved[0].Name != "web" {
+		t.Errorf("got removed %+v, want Pod web", result.ResourcesRemoved)
+	}
+}
+
+func TestDiffKeepsNamespacesApart(t *testing.T) {
+	oldResources := parseTree(t, map[string]string{"pods.yaml": pod("web", "shop")})
+	newResources := parseTree(t, map[string]string{
+		"shop.yaml":    pod("web", "shop"),
+		"staging.yaml": pod("web", "staging"),
+	})
+
+	result := NewScanner(types.ScanOptions{}).Diff(oldResources, newResources)
+
+	privileged := findingsFor(result.Findings, "privileged-container")
+	if len(privileged) != 1 || privileged[0].Namespace != "staging" {
+		t.Errorf("got privileged-container findings %+v, want one on staging/web", privileged)
+	}
+	if len(result.ResourcesAdded) != 1 || result.ResourcesAdded[0].Namespace != "staging" {
+		t.Errorf("got added %+v, want staging/web", result.ResourcesAdded)
+	}
+	if len(result.ResourcesRemoved) != 0 {
+		t.Errorf("got removed %+v, want none", result.ResourcesRemoved)
+	}
+}
+
+func TestExitCodeFor(t *testing.T) {
+	severities := []types.Severity{types.Low, types.Medium, types.High, types.Critical}
+
+	// want[finding][failOn] is the exit code for one finding of that severity
+	want := map[types.Severity]map[types.Severity]types.ExitCode{
+		types.Low: {
+			types.Low: types.ExitMedium, types.Medium: types.ExitOK,
+			types.High: types.ExitOK, types.Critical: types.ExitOK,
+		},
+		types.Medium: {
+			types.Low: types.ExitMedium, types.Medium: types.ExitMedium,
+			types.High: types.ExitOK, types.Critical: types.ExitOK,
+		},
+		types.High: {
+			types.Low: types.ExitHigh, types.Medium: types.ExitHigh,
+			types.High: types.ExitHigh, types.Critical: types.ExitOK,
+		},
+		types.Critical: {
+			types.Low: types.ExitHigh, types.Medium: types.ExitHigh,
+			types.High: types.ExitHigh, types.Critical: types.ExitHigh,
+		},
+	}
+
+	for _, severity := range severities {
+		for _, failOn := range severities {
+			t.Run(fmt.Sprintf("%s finding with fail-on %s", severity, failOn), func(t *testing.T) {
+				finding := types.Finding{RuleID: "test", Severity: severity}
+				if got := ExitCodeFor([]types.Finding{finding}, failOn); got != want[severity][failOn] {
+					t.Errorf("got exit code %d, want %d", got, want[severity][failOn])
+				}
+
+				suppressed := finding
+				suppressed.Suppressed = true
+				if got := ExitCodeFor([]types.Finding{suppressed}, failOn); got != types.ExitOK {
+					t.Errorf("suppressed finding gave exit code %d", got)
+				}
+
+				resolved := finding
+				resolved.Status = types.StatusResolved
+				if got := ExitCodeFor([]types.Finding{resolved}, failOn); got != types.ExitOK {
+					t.Errorf("resolved finding gave exit code %d", got)
+				}
+			})
+		}
+	}
+
+	if got := ExitCodeFor(nil, types.Low); got != types.ExitOK {
+		t.Errorf("no findings gave exit code %d", got)
+	}
+	mixed := []types.Finding{{Severity: types.Medium}, {Severity: types.Critical}}
+	if got := ExitCodeFor(mixed, types.Medium); got != types.ExitHigh {
+		t.Errorf("MEDIUM and CRITICAL findings gave exit code %d, want %d", got, types.ExitHigh)
+	}
+}
+
+// twoHostPaths is a Deployment with one hostPath volume, or two with extra
+const twoHostPaths = `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: api, namespace: shop}
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: api:1.0
+      volumes:
+      - name: logs
+        hostPath: {path: /var/log}
+%s`
+
+func TestSameRuleFindingsOnOneResource(t *testing.T) {
+	oldResources := parseTree(t, map[string]string{"api.yaml": fmt.Sprintf(twoHostPaths, "")})
+	newResources := parseTree(t, map[string]string{"api.yaml": fmt.Sprintf(twoHostPaths,
+		"      - name: etc\n        hostPath: {path: /etc}\n")})
+
+	s := NewScanner(types.ScanOptions{})
+	hostPaths := findingsFor(s.Scan(newResources).Findings, "hostpath-volume")
+	if len(hostPaths) != 2 {
+		t.Fatalf("got %d hostpath-volume findings, want 2", len(hostPaths))
+	}
+	if hostPaths[0].Fingerprint == hostPaths[1].Fingerprint {
+		t.Errorf("both hostPath volumes have fingerprint %s", hostPaths[0].Fingerprint)
+	}
+
+	added := findingsFor(s.Diff(oldResources, newResources).Findings, "hostpath-volume")
+	if len(added) != 1 || added[0].Path != "spec.template.spec.volumes[1]" {
+		t.Errorf("got new hostpath-volume findings %+v, want one on volumes[1]", added)
+	}
+}
+
+// weakSecret is a Secret whose stringData keys are all placeholders
+const weakSecret = `apiVersion: v1
+kind: Secret
+metadata: {name: creds, namespace: shop}
+stringData:
+  password: changeme
+%s`
+
+func TestDiffReportsNewWeakSecretKey(t *testing.T) {
+	oldResources := parseTree(t, map[string]string{"secret.yaml": fmt.Sprintf(weakSecret, "")})
+	newResources := parseTree(t, map[string]string{"secret.yaml": fmt.Sprintf(weakSecret, "  token: admin\n")})
+
+	opts := types.ScanOptions{MinSeverity: types.Low}
+	weak := findingsFor(NewScanner(opts).Diff(oldResources, newResources).Findings, "weak-secret-value")
+	if len(weak) != 1 || weak[0].Path != "stringData.token" || weak[0].Status != types.StatusNew {
+		t.Errorf("got weak-secret-value findings %+v, want one new finding on stringData.token", weak)
+	}
+}
+
+func TestNewScannerWithRulesOptions(t *testing.T) {
+	resources := parseTree(t, map[string]string{"pod.yaml": pod("web", "shop")})
+
+	// Report every resource, to show whether a rule kind ran at all
+	report := func(ruleID string, resource parser.K8sResource) types.Finding {
+		return types.Finding{RuleID: ruleID, Severity: types.High, Kind: resource.Kind,
+			Name: resource.Metadata.Name, Namespace: resource.Metadata.Namespace}
+	}
+	contextRule := func(resource parser.K8sResource, _ *rules.ScanContext) []types.Finding {
+		return []types.Finding{report("context", resource)}
+	}
+	aggregateRule := func(resources []parser.K8sResource) []types.Finding {
+		return []types.Finding{report("aggregate", resources[0])}
+	}
+
+	bare := NewScannerWithRules(types.ScanOptions{}, nil)
+	if findings := bare.Scan(resources).Findings; len(findings) != 0 {
+		t.Errorf("scanner without rules reported %+v", findings)
+	}
+
+	s := NewScannerWithRules(types.ScanOptions{}, nil, WithContextRules(contextRule), WithAggregateRules(aggregateRule))
+	findings := s.Scan(resources).Findings
+	if len(findingsFor(findings, "context")) != 1 || len(findingsFor(findings, "aggregate")) != 1 {
+		t.Errorf("got %+v, want one context and one aggregate finding", findings)
+	}
+}
@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// maxSnippetLines caps the YAML shown for a finding so a large container
+// doesn't drown the report
+const maxSnippetLines = 20
+
+// snippet renders the element at path in a resource as YAML, keyed by its
+// field name or as a one-item list so it reads as it does in the manifest.
+// It returns "" if the path doesn't resolve.
+func snippet(resource parser.K8sResource, path string) string {
+	value, last, ok := parser.Lookup(resource.Raw, path)
+	if !ok {
+		return ""
+	}
+
+	var doc interface{} = map[string]interface{}{last: value}
+	if strings.HasPrefix(last, "[") {
+		doc = []interface{}{value}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return ""
+	}
+	encoder.Close()
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) > maxSnippetLines {
+		lines = append(lines[:maxSnippetLines], "...")
+	}
+	return strings.Join(lines, "\n")
+}
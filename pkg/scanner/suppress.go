@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// ignoreUntilLayout is the date format of the ignore-until annotation
+const ignoreUntilLayout = "2006-01-02"
+
+// ignoredRules returns the rule IDs listed in a resource's ignore annotation
+func ignoredRules(annotations map[string]string) map[string]bool {
+	value, ok := annotations[types.IgnoreAnnotation]
+	if !ok {
+		return nil
+	}
+
+	ignored := make(map[string]bool)
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ignored[id] = true
+		}
+	}
+	return ignored
+}
+
+// suppress applies a resource's ignore annotation to its findings. Ignored
+// findings are dropped, or kept and marked Suppressed with ShowSuppressed.
+// Once the ignore-until date has passed, or if it is not a valid date, the
+// findings are reported as usual with a note in the Reason.
+func (s *Scanner) suppress(findings []types.Finding, annotations map[string]string) []types.Finding {
+	ignored := ignoredRules(annotations)
+	if len(ignored) == 0 {
+		return findings
+	}
+
+	note := ""
+	if until, ok := annotations[types.IgnoreUntilAnnotation]; ok {
+		date, err := time.Parse(ignoreUntilLayout, strings.TrimSpace(until))
+		switch {
+		case err != nil:
+			note = fmt.Sprintf(" (suppression ignored: %s %q is not a YYYY-MM-DD date)", types.IgnoreUntilAnnotation, until)
+		case !time.Now().Before(date.AddDate(0, 0, 1)):
+			note = fmt.Sprintf(" (suppression expired %s)", date.Format(ignoreUntilLayout))
+		}
+	}
+
+	var kept []types.Finding
+	for _, f := range findings {
+		switch {
+		case !ignored[f.RuleID]:
+		case note != "":
+			f.Reason += note
+		case s.options.ShowSuppressed:
+			f.Suppressed = true
+		default:
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
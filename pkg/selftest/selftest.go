@@ -0,0 +1,90 @@
+// Package selftest checks every built-in rule against embedded fixtures: a
+// manifest the rule must flag and a manifest it must leave alone.
+package selftest
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/rules"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/scanner"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// fixtures holds a bad.yaml and a good.yaml per rule, in a directory named
+// after the rule ID
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+// Result is the outcome of checking one rule against its fixtures
+type Result struct {
+	RuleID  string
+	Passed  bool
+	Message string // Why the rule failed; empty when it passed
+}
+
+// Run checks every built-in rule, in RuleIDs order. A rule passes when it
+// fires on its bad fixture and stays silent on its good one. Rules without
+// fixtures fail, so new rules can't skip the self-test.
+func Run() []Result {
+	// Enable everything, including opt-in categories, strict mode and the
+	// restricted Pod Security Standards, and report every severity
+	s := scanner.NewScanner(types.ScanOptions{
+		MinSeverity: types.Low,
+		Categories:  rules.Categories(),
+		Strict:      true,
+		PSSLevel:    types.PSSRestricted,
+	})
+
+	var results []Result
+	for _, id := range rules.RuleIDs() {
+		results = append(results, check(s, id))
+	}
+	return results
+}
+
+// check runs the scanner over one rule's fixtures
+func check(s *scanner.Scanner, ruleID string) Result {
+	bad, err := fires(s, ruleID, "bad.yaml")
+	if err != nil {
+		return Result{RuleID: ruleID, Message: err.Error()}
+	}
+	if !bad {
+		return Result{RuleID: ruleID, Message: "did not fire on bad.yaml"}
+	}
+
+	good, err := fires(s, ruleID, "good.yaml")
+	if err != nil {
+		return Result{RuleID: ruleID, Message: err.Error()}
+	}
+	if good {
+		return Result{RuleID: ruleID, Message: "fired on good.yaml"}
+	}
+
+	return Result{RuleID: ruleID, Passed: true}
+}
+
+// fires reports whether scanning a fixture produces a finding for ruleID
+func fires(s *scanner.Scanner, ruleID, name string) (bool, error) {
+	file := path.Join("fixtures", ruleID, name)
+	data, err := fs.ReadFile(fixtures, file)
+	if err != nil {
+		return false, fmt.Errorf("missing fixture %s", name)
+	}
+
+	resources, err := parser.ParseSource(file, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	for _, f := range s.Scan(resources).Findings {
+		if f.RuleID == ruleID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
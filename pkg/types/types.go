@@ -4,55 +4,305 @@ package types
 type Severity string
 
 const (
-	High   Severity = "HIGH"
-	Medium Severity = "MEDIUM"
+	Critical Severity = "CRITICAL"
+	High     Severity = "HIGH"
+	Medium   Severity = "MEDIUM"
+	Low      Severity = "LOW"
+)
+
+// Rank orders severities from least (1) to most (4) severe. Unknown
+// severities rank 0.
+func (s Severity) Rank() int {
+	switch s {
+	case Low:
+		return 1
+	case Medium:
+		return 2
+	case High:
+		return 3
+	case Critical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// PSSLevel is a Kubernetes Pod Security Standards profile
+type PSSLevel string
+
+const (
+	PSSBaseline   PSSLevel = "baseline"
+	PSSRestricted PSSLevel = "restricted"
+)
+
+// Rank orders PSS levels from least to most restrictive
+func (l PSSLevel) Rank() int {
+	switch l {
+	case PSSBaseline:
+		return 1
+	case PSSRestricted:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Category groups rules by the kind of problem they detect
+type Category string
+
+const (
+	CategorySecurity      Category = "security"
+	CategoryReliability   Category = "reliability"
+	CategoryHardening     Category = "hardening"
+	CategoryGovernance    Category = "governance"
+	CategorySecrets       Category = "secrets"
+	CategoryObservability Category = "observability" // Opt-in
+	CategorySupplyChain   Category = "supply-chain"  // Opt-in
+	CategoryCompliance    Category = "compliance"    // Enabled by --pss-level, not selectable
 )
 
 // Finding represents a security issue detected in a resource
 type Finding struct {
-	RuleID    string   `json:"rule_id"`
-	Severity  Severity `json:"severity"`
-	Kind      string   `json:"kind"`
-	Name      string   `json:"name"`
-	Namespace string   `json:"namespace"`
-	Reason    string   `json:"reason"`
-	Impact    string   `json:"impact"`
-	Fix       string   `json:"fix"`
+	RuleID      string     `json:"rule_id"`
+	Severity    Severity   `json:"severity"`
+	Category    Category   `json:"category,omitempty"`
+	Kind        string     `json:"kind"`
+	Name        string     `json:"name"`
+	Namespace   string     `json:"namespace"`
+	Container   string     `json:"container,omitempty"` // Offending container, for container-level findings
+	Reason      string     `json:"reason"`
+	Impact      string     `json:"impact"`
+	Fix         string     `json:"fix"`
+	File        string     `json:"file,omitempty"`
+	Path        string     `json:"path,omitempty"`   // Location of the offending element, e.g. spec.template.spec.containers[0]
+	Line        int        `json:"line,omitempty"`   // Line of the offending element in File, from 1
+	Column      int        `json:"column,omitempty"` // Column of the offending element in File, from 1
+	CISControl  string     `json:"cis_control,omitempty"`
+	Fingerprint string     `json:"fingerprint"`
+	References  []string   `json:"references,omitempty"`
+	PSSLevel    PSSLevel   `json:"pss_level,omitempty"`  // Pod Security Standards level of the failed control
+	Snippet     string     `json:"snippet,omitempty"`    // YAML of the element at Path, with --show-snippet
+	Suppressed  bool       `json:"suppressed,omitempty"` // Ignored by annotation; only reported with --show-suppressed
+	Status      DiffStatus `json:"status,omitempty"`     // Set by diffs: new, or resolved with --show-resolved
+
+	// SuggestedPatch fixes the finding when applied to the resource, for
+	// rules whose fix is mechanical
+	SuggestedPatch *Patch `json:"suggested_patch,omitempty"`
+}
+
+// DiffStatus classifies a finding when two manifest sets are compared
+type DiffStatus string
+
+const (
+	StatusNew       DiffStatus = "new"       // Introduced by the new manifests
+	StatusResolved  DiffStatus = "resolved"  // Present in the old manifests only
+	StatusUnchanged DiffStatus = "unchanged" // Present in both
+)
+
+// PatchType names a patch format as kubectl patch --type does
+type PatchType string
+
+const (
+	PatchJSON           PatchType = "json"      // RFC 6902 JSON Patch; Patch is a []PatchOperation
+	PatchStrategicMerge PatchType = "strategic" // Strategic merge patch; Patch is a partial object
+)
+
+// Patch is a change to one resource, in a form kubectl patch and GitOps
+// tooling can apply as is
+type Patch struct {
+	Type  PatchType   `json:"type"`
+	Patch interface{} `json:"patch"`
+}
+
+// PatchOperation is one RFC 6902 JSON Patch operation. Paths are JSON
+// pointers from the resource root.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Warning describes a non-fatal problem encountered during a scan, such as a
+// file that could not be parsed
+type Warning struct {
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// Stats describes how much input a scan covered
+type Stats struct {
+	FilesParsed      int   `json:"files_parsed"`
+	ResourcesScanned int   `json:"resources_scanned"`
+	ResourcesSkipped int   `json:"resources_skipped"`
+	RulesRun         int   `json:"rules_run"`
+	RuleExecutions   int   `json:"rule_executions"`
+	ElapsedMillis    int64 `json:"elapsed_ms"`
 }
 
 // ScanResult contains all findings from a scan
 type ScanResult struct {
 	Findings []Finding
+	Warnings []Warning
+	Stats    Stats
+
+	// Findings dropped because they are recorded in the --baseline file
+	Baselined int
+
+	// Findings dropped by an unexpired entry in the --exceptions file
+	ExceptionsApplied []AppliedException
+
+	// Set by diffs only: findings present on both sides, which are not
+	// reported
+	Unchanged int
+
+	// Set by diffs only: resources present on one side but not the other
+	ResourcesAdded   []ResourceRef
+	ResourcesRemoved []ResourceRef
+
+	// Set with ScanOptions.PSSLevel: the level workloads were evaluated
+	// against and one verdict per workload with a pod spec. Diffs report the
+	// new manifests' workloads.
+	PSSLevel    PSSLevel
+	PSSVerdicts []PSSVerdict
+}
+
+// PSSVerdict records whether a workload passes the evaluated Pod Security
+// Standards level and, if not, the least restrictive profile it violates
+type PSSVerdict struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	File      string   `json:"file,omitempty"`
+	Violates  PSSLevel `json:"violates,omitempty"` // Empty if the workload passes
+	Controls  []string `json:"controls,omitempty"` // Rule IDs of the failed controls
+}
+
+// AppliedException records a finding accepted by an exceptions file entry,
+// with the entry's justification, approver and expiry, for audit
+type AppliedException struct {
+	RuleID        string   `json:"rule_id"`
+	Kind          string   `json:"kind"`
+	Name          string   `json:"name"`
+	Namespace     string   `json:"namespace,omitempty"`
+	Container     string   `json:"container,omitempty"`
+	File          string   `json:"file,omitempty"`
+	Fingerprint   string   `json:"fingerprint"`
+	Severity      Severity `json:"severity"`
+	Justification string   `json:"justification"`
+	Approver      string   `json:"approver"`
+	Expires       string   `json:"expires"`
+}
+
+// ResourceRef identifies a resource added or removed between two manifest sets
+type ResourceRef struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	File       string `json:"file,omitempty"`
 }
 
 // Summary provides aggregated results
 type Summary struct {
-	High              int `json:"high"`
-	Medium            int `json:"medium"`
-	ResourcesAffected int `json:"resources_affected"`
+	Critical           int `json:"critical"`
+	High               int `json:"high"`
+	Medium             int `json:"medium"`
+	Low                int `json:"low"`
+	ResourcesAffected  int `json:"resources_affected"`
 	NamespacesAffected int `json:"namespaces_affected"`
+	Warnings           int `json:"warnings"`
+	Suppressed         int `json:"suppressed,omitempty"` // Suppressed findings shown with --show-suppressed
+	Baselined          int `json:"baselined,omitempty"`  // Findings hidden because they are in the baseline
+	Excepted           int `json:"excepted,omitempty"`   // Findings hidden by an exception
+	Resolved           int `json:"resolved,omitempty"`   // Diff findings fixed by the new manifests, shown with --show-resolved
+	Unchanged          int `json:"unchanged,omitempty"`  // Diff findings present in both manifest sets (not shown)
 }
 
 // OutputFormat defines the output format for results
 type OutputFormat string
 
 const (
-	FormatHuman OutputFormat = "human"
-	FormatJSON  OutputFormat = "json"
+	FormatHuman    OutputFormat = "human"
+	FormatJSON     OutputFormat = "json"
+	FormatCSV      OutputFormat = "csv"
+	FormatTemplate OutputFormat = "template" // User-supplied Go text/template
+	FormatSARIF    OutputFormat = "sarif"    // SARIF 2.1.0 for code scanning tools
+	FormatMarkdown OutputFormat = "markdown" // GitHub-flavored Markdown for PR comments
+	FormatHTML     OutputFormat = "html"     // Self-contained HTML report
+	FormatTable    OutputFormat = "table"    // One aligned row per finding
 )
 
+// RuleOverride replaces the built-in text or severity of a rule's findings.
+// Empty fields keep the built-in value.
+type RuleOverride struct {
+	Severity Severity `yaml:"severity,omitempty"`
+	Reason   string   `yaml:"reason,omitempty"`
+	Impact   string   `yaml:"impact,omitempty"`
+	Fix      string   `yaml:"fix,omitempty"`
+	// Threshold tunes rules that take a numeric parameter, such as low-uid
+	Threshold int `yaml:"threshold,omitempty"`
+	// Resources tunes rules that take a list of RBAC resources, such as
+	// wildcard-rbac-verbs
+	Resources []string `yaml:"resources,omitempty"`
+	// Requests makes no-resource-limits flag missing requests as well as
+	// missing limits
+	Requests bool `yaml:"requests,omitempty"`
+}
+
 // ScanOptions configures the scanner behavior
 type ScanOptions struct {
-	IncludeMedium bool
-	OutputFormat  OutputFormat
+	MinSeverity    Severity // Lowest severity reported; empty means HIGH
+	IncludeMedium  bool     // Deprecated: use MinSeverity: Medium
+	OutputFormat   OutputFormat
+	Overrides      map[string]RuleOverride // Keyed by rule ID
+	Strict         bool                    // Flag completely unconfigured containers
+	Categories     []Category              // Rule categories to run; empty means the defaults
+	PSSLevel       PSSLevel                // Evaluate pods against this Pod Security Standards level; empty disables
+	ShowSnippet    bool                    // Attach the YAML of each finding's offending element
+	DisabledRules  []string                // Rule IDs whose findings are dropped
+	OnlyRules      []string                // When set, findings from other rules are dropped
+	ShowSuppressed bool                    // Report annotation-suppressed findings, marked Suppressed
+	Concurrency    int                     // Resources scanned at once; zero or less uses one per CPU
+	ShowResolved   bool                    // Report findings a diff resolved, with StatusResolved
 }
 
+// ComplianceFramework is a benchmark whose controls a report can be grouped by
+type ComplianceFramework string
+
+const (
+	ComplianceCIS ComplianceFramework = "cis" // CIS Kubernetes Benchmark
+)
+
+// IgnoreAnnotation is the resource annotation listing, comma-separated, the
+// rule IDs whose findings are suppressed for that resource
+const IgnoreAnnotation = "danger-scan/ignore"
+
+// IgnoreUntilAnnotation optionally ends a resource's suppressions after the
+// given date (YYYY-MM-DD, inclusive)
+const IgnoreUntilAnnotation = "danger-scan/ignore-until"
+
 // ExitCode defines standard exit codes
 type ExitCode int
 
 const (
-	ExitOK         ExitCode = 0 // No findings
-	ExitMedium     ExitCode = 1 // Medium risk only
-	ExitHigh       ExitCode = 2 // At least one high risk
-	ExitError      ExitCode = 3 // Error occurred
+	ExitOK     ExitCode = 0 // No findings, or LOW findings only
+	ExitMedium ExitCode = 1 // Medium risk only (or low, with --fail-on low)
+	ExitHigh   ExitCode = 2 // At least one high (or critical) risk
+	ExitError  ExitCode = 3 // Error occurred
 )
+
+// Meaning describes an exit code for people reading CI logs
+func (c ExitCode) Meaning() string {
+	switch c {
+	case ExitOK:
+		return "no medium, high or critical findings"
+	case ExitMedium:
+		return "medium-risk findings only"
+	case ExitHigh:
+		return "at least one high or critical finding"
+	case ExitError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+)
+
+// fileState captures enough of a file's metadata to notice edits
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Run calls onChange once, then again every time a manifest under paths is
+// created, modified or removed. Paths are polled every interval, and rapid
+// saves are debounced until the tree has been stable for a full interval.
+// Run never returns.
+func Run(paths []string, interval time.Duration, onChange func()) {
+	last := snapshot(paths)
+	onChange()
+
+	for {
+		time.Sleep(interval)
+
+		current := snapshot(paths)
+		if equal(last, current) {
+			continue
+		}
+
+		// Wait for the burst of writes to settle before rescanning
+		for {
+			time.Sleep(interval)
+			settled := snapshot(paths)
+			if equal(current, settled) {
+				break
+			}
+			current = settled
+		}
+
+		last = current
+		onChange()
+	}
+}
+
+// snapshot records the state of every manifest file under paths
+func snapshot(paths []string) map[string]fileState {
+	states := make(map[string]fileState)
+
+	for _, path := range paths {
+		filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				// Files may disappear mid-walk while an editor saves
+				return nil
+			}
+			if !info.IsDir() && (p == path || parser.IsManifestPath(p)) {
+				states[p] = fileState{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+
+	return states
+}
+
+// equal reports whether two snapshots describe the same files
+func equal(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, ok := b[path]
+		if !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
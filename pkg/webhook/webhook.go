@@ -0,0 +1,212 @@
+// Package webhook serves a Kubernetes validating admission webhook that
+// scans each object the API server is about to admit.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/palthisailohith/k8s-danger-scan/pkg/logger"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/parser"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/scanner"
+	"github.com/palthisailohith/k8s-danger-scan/pkg/types"
+)
+
+// Mode decides what happens to a request with HIGH or CRITICAL findings
+type Mode string
+
+const (
+	ModeDeny  Mode = "deny"  // Reject the request
+	ModeWarn  Mode = "warn"  // Admit it, returning findings as warnings to the client
+	ModeAudit Mode = "audit" // Admit it silently and log the findings
+)
+
+// ParseMode validates a --mode value
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(value)); mode {
+	case ModeDeny, ModeWarn, ModeAudit:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q (valid: deny, warn, audit)", value)
+	}
+}
+
+// maxRequestBytes bounds the AdmissionReview body read from the API server,
+// which itself limits objects to a few MiB
+const maxRequestBytes = 8 << 20
+
+// admissionReview is the admission.k8s.io/v1 AdmissionReview envelope,
+// reduced to the fields the webhook reads or writes
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *admissionRequest  `json:"request,omitempty"`
+	Response   *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID       string          `json:"uid"`
+	Name      string          `json:"name,omitempty"`
+	Namespace string          `json:"namespace,omitempty"`
+	Operation string          `json:"operation,omitempty"`
+	Object    json.RawMessage `json:"object,omitempty"`
+}
+
+type admissionResponse struct {
+	UID      string   `json:"uid"`
+	Allowed  bool     `json:"allowed"`
+	Status   *status  `json:"status,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Server answers AdmissionReview requests by scanning the incoming object
+type Server struct {
+	scanner *scanner.Scanner
+	mode    Mode
+	log     *logger.Logger
+	audit   io.Writer
+}
+
+// NewServer creates a webhook server. Findings are written to audit, one
+// line each, whatever the mode.
+func NewServer(s *scanner.Scanner, mode Mode, log *logger.Logger, audit io.Writer) *Server {
+	return &Server{scanner: s, mode: mode, log: log, audit: audit}
+}
+
+// Handler routes /validate to the webhook and /healthz to a liveness probe
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validate", s.serveValidate)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// serveValidate decodes an AdmissionReview, scans its object and replies
+// with the review's response filled in
+func (s *Server) serveValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var review admissionReview
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes)).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = s.review(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		s.log.Warnf("failed to write AdmissionReview response: %v", err)
+	}
+}
+
+// review scans the request's object and decides whether to admit it.
+// Requests without an object, such as deletes, are always admitted.
+func (s *Server) review(req *admissionRequest) *admissionResponse {
+	response := &admissionResponse{UID: req.UID, Allowed: true}
+	if len(req.Object) == 0 || string(req.Object) == "null" {
+		return response
+	}
+
+	resource, err := decodeObject(req)
+	if err != nil {
+		// A webhook that can't read an object shouldn't block it; the
+		// API server has already validated it
+		s.log.Warnf("request %s: %v", req.UID, err)
+		return response
+	}
+
+	var blocking, other []types.Finding
+	for _, f := range s.scanner.Scan([]parser.K8sResource{resource}).Findings {
+		if f.Suppressed || f.Kind != resource.Kind {
+			// Findings about other objects, such as a namespace without a
+			// NetworkPolicy, can't be judged from a single object
+			continue
+		}
+		fmt.Fprintf(s.audit, "%s %s %s: [%s] %s: %s\n", s.mode, strings.ToLower(req.Operation), objectName(f), f.Severity, f.RuleID, f.Reason)
+		if f.Severity.Rank() >= types.High.Rank() {
+			blocking = append(blocking, f)
+		} else {
+			other = append(other, f)
+		}
+	}
+
+	switch s.mode {
+	case ModeDeny:
+		if len(blocking) > 0 {
+			response.Allowed = false
+			response.Status = &status{Code: http.StatusForbidden, Message: denyMessage(blocking)}
+		}
+		response.Warnings = warnings(other)
+	case ModeWarn:
+		response.Warnings = warnings(append(blocking, other...))
+	}
+	return response
+}
+
+// decodeObject converts the request's raw object into a resource. Objects
+// created with kubectl -n often omit metadata.namespace, so it is taken
+// from the request when missing.
+func decodeObject(req *admissionRequest) (parser.K8sResource, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(req.Object)))
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return parser.K8sResource{}, fmt.Errorf("failed to decode object: %w", err)
+	}
+
+	resource := parser.FromUnstructured(obj)
+	resource.Source = "admission"
+	if resource.Metadata.Namespace == "" {
+		resource.Metadata.Namespace = req.Namespace
+	}
+	if resource.Metadata.Name == "" {
+		resource.Metadata.Name = req.Name
+	}
+	return resource, nil
+}
+
+// denyMessage lists the findings that caused a request to be rejected
+func denyMessage(findings []types.Finding) string {
+	reasons := make([]string, len(findings))
+	for i, f := range findings {
+		reasons[i] = fmt.Sprintf("[%s] %s: %s", f.Severity, f.RuleID, f.Reason)
+	}
+	return fmt.Sprintf("k8s-danger-scan denied %s: %s", objectName(findings[0]), strings.Join(reasons, "; "))
+}
+
+// warnings renders findings as admission warnings, which kubectl prints
+// prefixed with "Warning:"
+func warnings(findings []types.Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, fmt.Sprintf("k8s-danger-scan [%s] %s: %s", f.Severity, f.RuleID, f.Reason))
+	}
+	return out
+}
+
+// objectName returns Kind/namespace/name, or Kind/name without a namespace
+func objectName(f types.Finding) string {
+	if f.Namespace == "" {
+		return f.Kind + "/" + f.Name
+	}
+	return f.Kind + "/" + f.Namespace + "/" + f.Name
+}